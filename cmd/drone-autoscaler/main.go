@@ -7,14 +7,18 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 
 	"github.com/drone/autoscaler"
 	"github.com/drone/autoscaler/config"
 	"github.com/drone/autoscaler/drivers/amazon"
+	"github.com/drone/autoscaler/drivers/azure"
 	"github.com/drone/autoscaler/drivers/digitalocean"
+	"github.com/drone/autoscaler/drivers/failover"
 	"github.com/drone/autoscaler/drivers/google"
 	"github.com/drone/autoscaler/drivers/hetznercloud"
 	"github.com/drone/autoscaler/drivers/openstack"
@@ -34,6 +38,7 @@ import (
 	"github.com/drone/signal"
 
 	"github.com/99designs/basicauth-go"
+	"github.com/dchest/uniuri"
 	"github.com/go-chi/chi"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/acme/autocert"
@@ -56,16 +61,19 @@ func main() {
 	conf := config.MustLoad()
 	setupLogging(conf)
 
-	provider, err := setupProvider(conf)
+	// the encrypt command encrypts the existing server
+	// records with the database secret, and exits.
+	if len(os.Args) > 1 && os.Args[1] == "encrypt" {
+		encryptServers(conf)
+		return
+	}
+
+	pools, err := setupPools(conf)
 	if err != nil {
 		logrus.WithError(err).
 			Fatalln("Invalid or missing hosting provider")
 	}
 
-	// instruments the provider with prometheus metrics.
-	provider = metrics.ServerCreate(provider)
-	provider = metrics.ServerDelete(provider)
-
 	db, err := store.Connect(
 		conf.Database.Driver,
 		conf.Database.Datasource,
@@ -77,8 +85,17 @@ func main() {
 			Fatalln("Cannot establish database connection")
 	}
 
+	enc, err := store.NewEncrypter(
+		conf.Database.Secret,
+		conf.Database.SecretPrevious...,
+	)
+	if err != nil {
+		logrus.WithError(err).
+			Fatalln("Invalid database secret")
+	}
+
 	mu := store.NewLocker(conf.Database.Driver)
-	servers := store.NewServerStore(db, mu)
+	servers := store.NewServerStore(db, mu, enc)
 
 	// instruments the provider with slack notifications
 	// instance creation and termination events.
@@ -88,16 +105,28 @@ func main() {
 	servers = metrics.ServerCount(servers)
 	defer db.Close()
 
+	events := store.NewEventStore(db, mu)
+
 	client := setupClient(conf)
 
 	enginex := engine.New(
 		client,
-		conf,
 		servers,
-		provider,
+		store.NewSampleStore(db, mu),
+		events,
 		metrics.New(),
+		pools...,
 	)
 
+	// only the replica that holds the leader lease runs
+	// the engine, which allows multiple replicas to share
+	// the same database.
+	var leases autoscaler.LeaseStore
+	if conf.Leader.Enabled {
+		leases = store.NewLeaseStore(db, mu)
+		enginex = engine.NewLeader(enginex, leases, setupReplica(conf), conf.Leader.TTL)
+	}
+
 	//
 	// Setup the router
 	//
@@ -118,7 +147,7 @@ func main() {
 		root.Get("/metrics", server.HandleMetrics(conf.Prometheus.AuthToken))
 		root.Get("/version", server.HandleVersion(source, version, commit))
 		root.Get("/healthz", server.HandleHealthz())
-		root.Get("/varz", server.HandleVarz(enginex))
+		root.Get("/varz", server.HandleVarz(enginex, leases))
 		root.Handle("/static/*", http.StripPrefix("/static/", fs))
 
 		if conf.UI.Password != "" {
@@ -128,7 +157,7 @@ func main() {
 
 			root.Route("/ui", func(ui chi.Router) {
 				ui.Use(auth)
-				ui.Get("/", web.HandleServers(servers))
+				ui.Get("/", web.HandleServers(servers, enginex))
 				ui.Get("/logs", web.HandleLogging(history))
 			})
 		}
@@ -138,9 +167,11 @@ func main() {
 			api.Post("/pause", server.HandleEnginePause(enginex))
 			api.Post("/resume", server.HandleEngineResume(enginex))
 			api.Get("/servers", server.HandleServerList(servers))
-			api.Post("/servers", server.HandleServerCreate(servers, conf))
+			api.Post("/servers", server.HandleServerCreate(servers, poolConfigs(pools)))
 			api.Get("/servers/{name}", server.HandleServerFind(servers))
+			api.Patch("/servers/{name}", server.HandleServerUpdate(servers))
 			api.Delete("/servers/{name}", server.HandleServerDelete(servers))
+			api.Get("/servers/{name}/events", server.HandleServerEvents(events))
 		})
 	})
 
@@ -195,6 +226,58 @@ func main() {
 	}
 }
 
+// helper function encrypts the secret and private keys of
+// the existing server records with the database secret. This
+// is used to encrypt records written before encryption was
+// enabled, and to re-encrypt records when the secret is
+// rotated. If the secret is empty, records are decrypted
+// with the previous secrets, which disables encryption.
+func encryptServers(c config.Config) {
+	if c.Database.Secret == "" && len(c.Database.SecretPrevious) == 0 {
+		logrus.Fatalln("Cannot encrypt servers without a database secret")
+	}
+	enc, err := store.NewEncrypter(
+		c.Database.Secret,
+		c.Database.SecretPrevious...,
+	)
+	if err != nil {
+		logrus.WithError(err).
+			Fatalln("Invalid database secret")
+	}
+
+	db, err := store.Connect(
+		c.Database.Driver,
+		c.Database.Datasource,
+		c.Database.MaxIdle,
+		c.Database.MaxLifetime,
+	)
+	if err != nil {
+		logrus.WithError(err).
+			Fatalln("Cannot establish database connection")
+	}
+	defer db.Close()
+
+	mu := store.NewLocker(c.Database.Driver)
+	n, err := store.EncryptServers(context.Background(), db, mu, enc)
+	if err != nil {
+		logrus.WithError(err).
+			WithField("servers", n).
+			Fatalln("Cannot encrypt servers")
+	}
+	logrus.WithField("servers", n).
+		Infoln("Encrypted servers")
+}
+
+// helper function returns the replica identifier used to
+// acquire the leader lease, defaulting to the hostname.
+func setupReplica(c config.Config) string {
+	if c.Leader.ID != "" {
+		return c.Leader.ID
+	}
+	hostname, _ := os.Hostname()
+	return hostname + "-" + uniuri.NewLen(6)
+}
+
 // helper funciton configures the logging.
 func setupLogging(c config.Config) {
 	logger.Default = logger.Logrus(
@@ -228,10 +311,113 @@ func setupClient(c config.Config) drone.Client {
 	return drone.NewClient(uri.String(), auther)
 }
 
+// helper function configures the pools and the hosting
+// provider for each pool.
+func setupPools(c config.Config) ([]engine.Pool, error) {
+	pools, err := config.LoadPools(c)
+	if err != nil {
+		return nil, err
+	}
+	var out []engine.Pool
+	for _, pool := range pools {
+		provider, err := setupProvider(pool.Config, pool.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		// instruments the provider with prometheus metrics.
+		provider = metrics.ServerCreate(provider)
+		provider = metrics.ServerDelete(provider)
+
+		out = append(out, engine.Pool{
+			Name:     pool.Name,
+			Config:   pool.Config,
+			Provider: provider,
+		})
+	}
+	return out, nil
+}
+
 // helper function configures the hosting provider.
-func setupProvider(c config.Config) (autoscaler.Provider, error) {
+func setupProvider(c config.Config, pool string) (autoscaler.Provider, error) {
+	if len(c.Failover.Providers) != 0 {
+		return setupFailover(c, pool)
+	}
+	return setupDriver(c, "", pool)
+}
+
+// helper function configures a failover provider that
+// creates instances using the first available provider.
+//
+// Each provider is optionally suffixed with a region, for
+// example amazon:us-west-2, which allows the same provider to
+// be configured for multiple regions.
+func setupFailover(c config.Config, pool string) (autoscaler.Provider, error) {
+	var targets []failover.Target
+	for _, name := range c.Failover.Providers {
+		typ, region, _ := strings.Cut(name, ":")
+		conf, err := withRegion(c, autoscaler.ProviderType(typ), region)
+		if err != nil {
+			return nil, err
+		}
+		provider, err := setupDriver(conf, autoscaler.ProviderType(typ), pool)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, failover.Target{
+			Name:     name,
+			Type:     autoscaler.ProviderType(typ),
+			Provider: provider,
+			Weight:   c.Failover.Weights[name],
+		})
+	}
+	return failover.New(targets...), nil
+}
+
+// helper function returns a copy of the configuration with
+// the region of the provider overridden. If the region is
+// empty, the configuration is returned unchanged.
+func withRegion(c config.Config, typ autoscaler.ProviderType, region string) (config.Config, error) {
+	if region == "" {
+		return c, nil
+	}
+	switch typ {
+	case autoscaler.ProviderAmazon:
+		c.Amazon.Region = region
+	case autoscaler.ProviderAzure:
+		c.Azure.Location = region
+	case autoscaler.ProviderDigitalOcean:
+		c.DigitalOcean.Region = region
+	case autoscaler.ProviderGoogle:
+		c.Google.Zone = []string{region}
+	case autoscaler.ProviderHetznerCloud:
+		c.HetznerCloud.Datacenter = region
+	case autoscaler.ProviderOpenStack:
+		c.OpenStack.Region = region
+	case autoscaler.ProviderPacket:
+		c.Packet.Facility = region
+	case autoscaler.ProviderScaleway:
+		c.Scaleway.Zone = region
+	default:
+		return c, fmt.Errorf("cannot configure the region of provider %s", typ)
+	}
+	return c, nil
+}
+
+// helper function configures the named provider. If the
+// name is empty the provider is inferred from the
+// configuration.
+func setupDriver(c config.Config, name autoscaler.ProviderType, pool string) (autoscaler.Provider, error) {
+	// the autoscaler identifier defaults to the drone server
+	// address, and is added to every server with the pool name
+	// to identify the servers owned by the autoscaler.
+	owner := c.ID
+	if owner == "" {
+		owner = c.Server.Host
+	}
+
 	switch {
-	case c.Google.Project != "":
+	case name == autoscaler.ProviderGoogle, name == "" && c.Google.Project != "":
 		return google.New(
 			google.WithDiskSize(c.Google.DiskSize),
 			google.WithDiskType(c.Google.DiskType),
@@ -251,8 +437,34 @@ func setupProvider(c config.Config) (autoscaler.Provider, error) {
 			google.WithZones(c.Google.Zone...),
 			google.WithUserDataKey(c.Google.UserDataKey),
 			google.WithRateLimit(c.Google.RateLimit),
+			google.WithProvisioningModel(c.Google.ProvisioningModel),
+			google.WithTerminationAction(c.Google.TerminationAction),
+			google.WithOwner(owner, pool),
+			google.WithBatchConcurrency(c.Pool.BatchConcurrency),
 		)
-	case c.DigitalOcean.Token != "":
+	case name == autoscaler.ProviderAzure, name == "" && c.Azure.SubscriptionID != "":
+		return azure.New(
+			azure.WithCredentials(c.Azure.TenantID, c.Azure.ClientID, c.Azure.ClientSecret),
+			azure.WithSubscription(c.Azure.SubscriptionID),
+			azure.WithEndpoint(c.Azure.Endpoint),
+			azure.WithAuthority(c.Azure.Authority),
+			azure.WithResourceGroup(c.Azure.ResourceGroup),
+			azure.WithLocation(c.Azure.Location),
+			azure.WithSize(c.Azure.Size),
+			azure.WithImage(c.Azure.Image),
+			azure.WithSubnet(c.Azure.Subnet),
+			azure.WithSecurityGroup(c.Azure.SecurityGroup),
+			azure.WithPrivateIP(c.Azure.PrivateIP),
+			azure.WithUsername(c.Azure.Username),
+			azure.WithSSHKey(c.Azure.SSHKey),
+			azure.WithDiskSize(c.Azure.DiskSize),
+			azure.WithDiskType(c.Azure.DiskType),
+			azure.WithTags(c.Azure.Tags),
+			azure.WithUserData(c.Azure.UserData),
+			azure.WithUserDataFile(c.Azure.UserDataFile),
+			azure.WithOwner(owner, pool),
+		), nil
+	case name == autoscaler.ProviderDigitalOcean, name == "" && c.DigitalOcean.Token != "":
 		return digitalocean.New(
 			digitalocean.WithSSHKey(c.DigitalOcean.SSHKey),
 			digitalocean.WithImage(c.DigitalOcean.Image),
@@ -264,8 +476,9 @@ func setupProvider(c config.Config) (autoscaler.Provider, error) {
 			digitalocean.WithToken(c.DigitalOcean.Token),
 			digitalocean.WithPrivateIP(c.DigitalOcean.PrivateIP),
 			digitalocean.WithTags(c.DigitalOcean.Tags...),
+			digitalocean.WithOwner(owner, pool),
 		), nil
-	case c.Scaleway.AccessKey != "":
+	case name == autoscaler.ProviderScaleway, name == "" && c.Scaleway.AccessKey != "":
 		return scaleway.New(
 			scaleway.WithAccessKey(c.Scaleway.AccessKey),
 			scaleway.WithSecretKey(c.Scaleway.SecretKey),
@@ -277,8 +490,9 @@ func setupProvider(c config.Config) (autoscaler.Provider, error) {
 			scaleway.WithTags(c.Scaleway.Tags...),
 			scaleway.WithUserData(c.Scaleway.UserData),
 			scaleway.WithUserDataFile(c.Scaleway.UserDataFile),
+			scaleway.WithOwner(owner, pool),
 		)
-	case c.HetznerCloud.Token != "":
+	case name == autoscaler.ProviderHetznerCloud, name == "" && c.HetznerCloud.Token != "":
 		return hetznercloud.New(
 			hetznercloud.WithDatacenter(c.HetznerCloud.Datacenter),
 			hetznercloud.WithImage(c.HetznerCloud.Image),
@@ -287,8 +501,9 @@ func setupProvider(c config.Config) (autoscaler.Provider, error) {
 			hetznercloud.WithServerType(c.HetznerCloud.Type),
 			hetznercloud.WithSSHKey(c.HetznerCloud.SSHKey),
 			hetznercloud.WithToken(c.HetznerCloud.Token),
+			hetznercloud.WithOwner(owner, pool),
 		), nil
-	case c.Packet.APIKey != "":
+	case name == autoscaler.ProviderPacket, name == "" && c.Packet.APIKey != "":
 		return packet.New(
 			packet.WithAPIKey(c.Packet.APIKey),
 			packet.WithFacility(c.Packet.Facility),
@@ -300,8 +515,9 @@ func setupProvider(c config.Config) (autoscaler.Provider, error) {
 			packet.WithUserDataFile(c.Packet.UserDataFile),
 			packet.WithHostname(c.Packet.Hostname),
 			packet.WithTags(c.Packet.Tags...),
+			packet.WithOwner(owner, pool),
 		), nil
-	case os.Getenv("AWS_ACCESS_KEY_ID") != "" || os.Getenv("AWS_IAM") != "":
+	case name == autoscaler.ProviderAmazon, name == "" && (os.Getenv("AWS_ACCESS_KEY_ID") != "" || os.Getenv("AWS_IAM") != ""):
 		return amazon.New(
 			amazon.WithDeviceName(c.Amazon.DeviceName),
 			amazon.WithImage(c.Amazon.Image),
@@ -323,8 +539,12 @@ func setupProvider(c config.Config) (autoscaler.Provider, error) {
 			amazon.WithIamProfileArn(c.Amazon.IamProfileArn),
 			amazon.WithMarketType(c.Amazon.MarketType),
 			amazon.WithInstanceMetadataTokens(c.Amazon.IMDSTokens),
+			amazon.WithInterruptThreshold(c.Amazon.InterruptThreshold),
+			amazon.WithInterruptWindow(c.Amazon.InterruptWindow),
+			amazon.WithOwner(owner, pool),
+			amazon.WithBatchConcurrency(c.Pool.BatchConcurrency),
 		), nil
-	case os.Getenv("OS_USERNAME") != "":
+	case name == autoscaler.ProviderOpenStack, name == "" && os.Getenv("OS_USERNAME") != "":
 		return openstack.New(
 			openstack.WithImage(c.OpenStack.Image),
 			openstack.WithRegion(c.OpenStack.Region),
@@ -336,8 +556,23 @@ func setupProvider(c config.Config) (autoscaler.Provider, error) {
 			openstack.WithMetadata(c.OpenStack.Metadata),
 			openstack.WithUserData(c.OpenStack.UserData),
 			openstack.WithUserDataFile(c.OpenStack.UserDataFile),
+			openstack.WithOwner(owner, pool),
 		)
+	case name != "":
+		return nil, fmt.Errorf("unknown provider %q", name)
 	default:
 		return nil, errors.New("missing provider configuration")
 	}
 }
+
+// helper function returns the pool configurations.
+func poolConfigs(pools []engine.Pool) []config.Pool {
+	var out []config.Pool
+	for _, pool := range pools {
+		out = append(out, config.Pool{
+			Name:   pool.Name,
+			Config: pool.Config,
+		})
+	}
+	return out
+}
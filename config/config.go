@@ -11,6 +11,7 @@ import "time"
 type (
 	// Config stores the configuration settings.
 	Config struct {
+		ID             string        `envconfig:"DRONE_AUTOSCALER_ID"`
 		Interval       time.Duration `default:"5m"`
 		CapacityBuffer int           `default:"0" split_words:"true"`
 
@@ -32,9 +33,42 @@ type (
 		}
 
 		Pool struct {
-			Min    int           `default:"2"`
-			Max    int           `default:"4"`
-			MinAge time.Duration `default:"55m" split_words:"true"`
+			Min       int           `default:"2"`
+			Max       int           `default:"4"`
+			MinAge    time.Duration `default:"55m" split_words:"true"`
+			MaxAge    time.Duration `split_words:"true"`
+			File      string
+			Hibernate bool
+			BatchSize int `default:"10" split_words:"true"`
+
+			// BatchConcurrency limits the number of servers
+			// created in parallel when the provider cannot
+			// create the batch with a single request.
+			BatchConcurrency int `default:"5" split_words:"true"`
+		}
+
+		Schedule struct {
+			File    string     `envconfig:"DRONE_SCHEDULE_FILE"`
+			Entries []Schedule `ignored:"true"`
+		}
+
+		Forecast struct {
+			Enabled bool          `envconfig:"DRONE_FORECAST_ENABLED"`
+			Method  string        `envconfig:"DRONE_FORECAST_METHOD" default:"smoothing"`
+			Alpha   float64       `envconfig:"DRONE_FORECAST_ALPHA" default:"0.5"`
+			Window  time.Duration `envconfig:"DRONE_FORECAST_WINDOW" default:"10m"`
+			Retain  time.Duration `envconfig:"DRONE_FORECAST_RETAIN" default:"192h"`
+		}
+
+		Events struct {
+			Retain time.Duration `envconfig:"DRONE_EVENTS_RETAIN" default:"720h"`
+		}
+
+		Policy struct {
+			Name   string `envconfig:"DRONE_POLICY" default:"default"`
+			Step   int    `envconfig:"DRONE_POLICY_STEP" default:"1"`
+			Target int    `envconfig:"DRONE_POLICY_TARGET_UTILIZATION" default:"80"`
+			Cycles int    `envconfig:"DRONE_POLICY_CYCLES" default:"3"`
 		}
 
 		Check struct {
@@ -61,6 +95,7 @@ type (
 			Volumes     []string
 			Ports       []string          `envconfig:"DRONE_AGENT_PUBLISHED_PORTS"`
 			Labels      map[string]string `envconfig:"DRONE_AGENT_LABELS"`
+			LabelsMatch string            `envconfig:"DRONE_AGENT_LABELS_MATCH" default:"exact"`
 			NamePrefix  string            `envconfig:"DRONE_AGENT_NAME_PREFIX" default:"agent-"`
 		}
 
@@ -81,8 +116,44 @@ type (
 		}
 
 		Pinger struct {
-			Enabled  bool          `envconfig:"DRONE_PINGER_ENABLED", default:"false"`
-			Interval time.Duration `envconfig:"DRONE_PINGER_INTERVAL" default:"10m"`
+			Enabled     bool          `envconfig:"DRONE_PINGER_ENABLED", default:"false"`
+			Interval    time.Duration `envconfig:"DRONE_PINGER_INTERVAL" default:"10m"`
+			Action      string        `envconfig:"DRONE_PINGER_ACTION" default:"error"`
+			MaxRestarts int           `envconfig:"DRONE_PINGER_MAX_RESTARTS" default:"3"`
+		}
+
+		Interrupt struct {
+			Enabled  bool          `envconfig:"DRONE_INTERRUPT_ENABLED"`
+			Interval time.Duration `envconfig:"DRONE_INTERRUPT_INTERVAL" default:"30s"`
+		}
+
+		Drain struct {
+			Enabled bool          `envconfig:"DRONE_DRAIN_ENABLED"`
+			Timeout time.Duration `envconfig:"DRONE_DRAIN_TIMEOUT" default:"1h"`
+		}
+
+		Reconcile struct {
+			Enabled  bool          `envconfig:"DRONE_RECONCILE_ENABLED"`
+			Action   string        `envconfig:"DRONE_RECONCILE_ACTION" default:"destroy"`
+			Interval time.Duration `envconfig:"DRONE_RECONCILE_INTERVAL" default:"1h"`
+		}
+
+		Recovery struct {
+			Budget     int           `envconfig:"DRONE_RECOVERY_BUDGET" default:"5"`
+			Backoff    time.Duration `envconfig:"DRONE_RECOVERY_BACKOFF" default:"1m"`
+			BackoffMax time.Duration `envconfig:"DRONE_RECOVERY_BACKOFF_MAX" default:"1h"`
+		}
+
+		Rollout struct {
+			Enabled     bool          `envconfig:"DRONE_ROLLOUT_ENABLED"`
+			Surge       int           `envconfig:"DRONE_ROLLOUT_SURGE" default:"1"`
+			Unavailable int           `envconfig:"DRONE_ROLLOUT_UNAVAILABLE" default:"1"`
+			Interval    time.Duration `envconfig:"DRONE_ROLLOUT_INTERVAL" default:"1m"`
+		}
+
+		Failover struct {
+			Providers []string       `envconfig:"DRONE_FAILOVER_PROVIDERS"`
+			Weights   map[string]int `envconfig:"DRONE_FAILOVER_WEIGHTS"`
 		}
 
 		Watchtower struct {
@@ -94,6 +165,12 @@ type (
 			Timeout       time.Duration `envconfig:"DRONE_WATCHTOWER_TIMEOUT" default:"120m"`
 		}
 
+		Leader struct {
+			Enabled bool          `envconfig:"DRONE_LEADER_ENABLED"`
+			ID      string        `envconfig:"DRONE_LEADER_ID"`
+			TTL     time.Duration `envconfig:"DRONE_LEADER_TTL" default:"30s"`
+		}
+
 		HTTP struct {
 			Proto string `envconfig:"DRONE_HTTP_PROTO" default:"http"`
 			Host  string `envconfig:"DRONE_HTTP_HOST"`
@@ -122,6 +199,12 @@ type (
 			Datasource  string        `default:"database.sqlite?cache=shared&mode=rwc&_busy_timeout=9999999"`
 			MaxIdle     int           `envconfig:"DRONE_DATABASE_MAX_IDLE" default:"0"`
 			MaxLifetime time.Duration `envconfig:"DRONE_DATABASE_MAX_LIFETIME"`
+
+			// Secret is used to encrypt the server secret and
+			// private keys. Previous secrets are used to decrypt
+			// values when the secret is rotated.
+			Secret         string   `envconfig:"DRONE_DATABASE_SECRET"`
+			SecretPrevious []string `envconfig:"DRONE_DATABASE_SECRET_PREVIOUS"`
 		}
 
 		Amazon struct {
@@ -146,6 +229,32 @@ type (
 			IamProfileArn    string `envconfig:"DRONE_AMAZON_IAM_PROFILE_ARN"`
 			MarketType       string `envconfig:"DRONE_AMAZON_MARKET_TYPE"`
 			IMDSTokens       string `envconfig:"DRONE_AMAZON_IMDS_TOKENS"`
+
+			InterruptThreshold int           `envconfig:"DRONE_AMAZON_INTERRUPT_THRESHOLD" default:"2"`
+			InterruptWindow    time.Duration `envconfig:"DRONE_AMAZON_INTERRUPT_WINDOW" default:"1h"`
+		}
+
+		Azure struct {
+			SubscriptionID string            `envconfig:"DRONE_AZURE_SUBSCRIPTION_ID"`
+			TenantID       string            `envconfig:"DRONE_AZURE_TENANT_ID"`
+			ClientID       string            `envconfig:"DRONE_AZURE_CLIENT_ID"`
+			ClientSecret   string            `envconfig:"DRONE_AZURE_CLIENT_SECRET"`
+			Endpoint       string            `envconfig:"DRONE_AZURE_ENDPOINT"`
+			Authority      string            `envconfig:"DRONE_AZURE_AUTHORITY"`
+			ResourceGroup  string            `envconfig:"DRONE_AZURE_RESOURCE_GROUP"`
+			Location       string            `envconfig:"DRONE_AZURE_LOCATION"`
+			Size           string            `envconfig:"DRONE_AZURE_SIZE"`
+			Image          string            `envconfig:"DRONE_AZURE_IMAGE"`
+			Subnet         string            `envconfig:"DRONE_AZURE_SUBNET"`
+			SecurityGroup  string            `envconfig:"DRONE_AZURE_SECURITY_GROUP"`
+			PrivateIP      bool              `envconfig:"DRONE_AZURE_PRIVATE_IP"`
+			Username       string            `envconfig:"DRONE_AZURE_USERNAME"`
+			SSHKey         string            `envconfig:"DRONE_AZURE_SSHKEY"`
+			DiskSize       int64             `envconfig:"DRONE_AZURE_DISK_SIZE"`
+			DiskType       string            `envconfig:"DRONE_AZURE_DISK_TYPE"`
+			Tags           map[string]string `envconfig:"DRONE_AZURE_TAGS"`
+			UserData       string            `envconfig:"DRONE_AZURE_USERDATA"`
+			UserDataFile   string            `envconfig:"DRONE_AZURE_USERDATA_FILE"`
 		}
 
 		DigitalOcean struct {
@@ -180,6 +289,8 @@ type (
 			Zone                []string          `envconfig:"DRONE_GOOGLE_ZONE"`
 			UserDataKey         string            `envconfig:"DRONE_GOOGLE_USERDATA_KEY" default:"user-data"`
 			RateLimit           int               `envconfig:"DRONE_GOOGLE_READ_RATELIMIT" default:"25"`
+			ProvisioningModel   string            `envconfig:"DRONE_GOOGLE_PROVISIONING_MODEL" default:"STANDARD"`
+			TerminationAction   string            `envconfig:"DRONE_GOOGLE_TERMINATION_ACTION" default:"DELETE"`
 		}
 
 		HetznerCloud struct {
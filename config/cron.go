@@ -0,0 +1,129 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron is a parsed five field cron expression in the
+// minute, hour, day of month, month and day of week format.
+// The expression matches every minute for which all fields
+// match, for example "* 8-17 * * 1-5" matches weekdays
+// between 08:00 and 17:59.
+type Cron struct {
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+
+	// true if the day of month or day of week fields are
+	// unrestricted, used to match days in the same way
+	// as the standard cron implementation.
+	domStar bool
+	dowStar bool
+}
+
+// ParseCron parses a five field cron expression. Each field
+// supports wildcards, values, ranges, steps and lists.
+func ParseCron(s string) (*Cron, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron: expected 5 fields, got %d", len(fields))
+	}
+	var err error
+	expr := new(Cron)
+	if expr.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, err
+	}
+	if expr.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, err
+	}
+	if expr.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, err
+	}
+	if expr.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, err
+	}
+	if expr.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, err
+	}
+	// sunday can be expressed as 0 or 7.
+	if expr.dow&(1<<7) != 0 {
+		expr.dow |= 1
+	}
+	expr.domStar = fields[2] == "*"
+	expr.dowStar = fields[4] == "*"
+	return expr, nil
+}
+
+// Match returns true if the time matches the expression.
+func (c *Cron) Match(t time.Time) bool {
+	if c.minute&(1<<uint(t.Minute())) == 0 ||
+		c.hour&(1<<uint(t.Hour())) == 0 ||
+		c.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// helper function parses a cron field and returns the
+// matching values as a bitset.
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		lo, hi, step := min, max, 1
+
+		i := strings.Index(part, "/")
+		if i != -1 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("cron: invalid step %q", part)
+			}
+			step = n
+			part = part[:i]
+		}
+
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(bounds[0])
+			hi, err2 = strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("cron: invalid range %q", part)
+			}
+		default:
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return 0, fmt.Errorf("cron: invalid value %q", part)
+			}
+			lo, hi = n, n
+			// a value with a step matches from the value
+			// to the maximum, for example 5/15.
+			if i != -1 {
+				hi = max
+			}
+		}
+
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("cron: value %q out of range %d-%d", part, min, max)
+		}
+		for i := lo; i <= hi; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+	return bits, nil
+}
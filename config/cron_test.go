@@ -0,0 +1,74 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		expr  string
+		valid bool
+	}{
+		{"* * * * *", true},
+		{"* 8-17 * * 1-5", true},
+		{"*/15 0,12 1 1-12/2 7", true},
+		{"5/15 * * * *", true},
+		{"* * * *", false},
+		{"60 * * * *", false},
+		{"* 24 * * *", false},
+		{"* * 0 * *", false},
+		{"* 17-8 * * *", false},
+		{"*/0 * * * *", false},
+		{"a * * * *", false},
+	}
+	for _, test := range tests {
+		_, err := ParseCron(test.expr)
+		if got, want := err == nil, test.valid; got != want {
+			t.Errorf("Want valid %v for expression %q, got error %v", want, test.expr, err)
+		}
+	}
+}
+
+func TestCronMatch(t *testing.T) {
+	// 2020-06-01 is a Monday.
+	monday := time.Date(2020, 6, 1, 9, 30, 0, 0, time.UTC)
+	sunday := time.Date(2020, 6, 7, 9, 30, 0, 0, time.UTC)
+	night := time.Date(2020, 6, 1, 3, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr  string
+		time  time.Time
+		match bool
+	}{
+		{"* * * * *", monday, true},
+		{"* 8-17 * * 1-5", monday, true},
+		{"* 8-17 * * 1-5", sunday, false},
+		{"* 8-17 * * 1-5", night, false},
+		{"* * * * 0", sunday, true},
+		{"* * * * 7", sunday, true},
+		{"0-29 * * * *", monday, false},
+		{"*/30 * * * *", monday, true},
+		{"5/15 * * * *", monday, false},
+		{"* * 1 6 *", monday, true},
+		// day of month or day of week match, when both
+		// fields are restricted.
+		{"* * 7 * 1", monday, true},
+		{"* * 7 * 1", sunday, true},
+		{"* * 2 * 2", monday, false},
+	}
+	for _, test := range tests {
+		expr, err := ParseCron(test.expr)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if got, want := expr.Match(test.time), test.match; got != want {
+			t.Errorf("Want match %v for expression %q at %s", want, test.expr, test.time)
+		}
+	}
+}
@@ -45,6 +45,13 @@ func Load() (Config, error) {
 			)
 		}
 	}
+	if path := config.Schedule.File; path != "" {
+		schedules, err := LoadSchedules(path)
+		if err != nil {
+			return config, err
+		}
+		config.Schedule.Entries = schedules
+	}
 	// If environment variables don't contain `=`, we consider that it's an environment name, we fetch and expose the value
 	for i, env := range config.Agent.Environ {
 		if !strings.Contains(env, "=") {
@@ -52,7 +59,7 @@ func Load() (Config, error) {
 		}
 	}
 	godotenv.Load()
-	return config, nil
+	return config, Validate(config)
 }
 
 // MustLoad loads the configuration from the environmnet
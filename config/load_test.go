@@ -76,6 +76,8 @@ func TestLoad(t *testing.T) {
 		"DRONE_POOL_MIN_AGE":               "1h",
 		"DRONE_POOL_MIN":                   "1",
 		"DRONE_POOL_MAX":                   "5",
+		"DRONE_POLICY":                     "step",
+		"DRONE_POLICY_STEP":                "2",
 		"DRONE_SERVER_HOST":                "drone.company.com",
 		"DRONE_SERVER_PROTO":               "http",
 		"DRONE_SERVER_TOKEN":               "633eb230f5",
@@ -130,6 +132,12 @@ func TestLoad(t *testing.T) {
 		"DRONE_AMAZON_TAGS":                "os:linux,arch:amd64",
 		"DRONE_AMAZON_USERDATA":            "#cloud-init",
 		"DRONE_AMAZON_USERDATA_FILE":       "/path/to/cloud/init.yml",
+		"DRONE_AZURE_SUBSCRIPTION_ID":      "9fa0e3d2",
+		"DRONE_AZURE_RESOURCE_GROUP":       "drone",
+		"DRONE_AZURE_LOCATION":             "westeurope",
+		"DRONE_AZURE_SIZE":                 "Standard_D2s_v3",
+		"DRONE_AZURE_PRIVATE_IP":           "true",
+		"DRONE_AZURE_TAGS":                 "env:prod",
 		"DRONE_HETZNERCLOUD_TOKEN":         "12345678",
 		"DRONE_HETZNERCLOUD_IMAGE":         "ubuntu-16.04",
 		"DRONE_HETZNERCLOUD_DATACENTER":    "nbg1-dc3",
@@ -207,7 +215,25 @@ var jsonConfig = []byte(`{
   "Pool": {
     "Min": 1,
     "Max": 5,
-    "MinAge": 3600000000000
+    "MinAge": 3600000000000,
+    "BatchSize": 10,
+    "BatchConcurrency": 5
+  },
+  "Forecast": {
+    "Enabled": false,
+    "Method": "smoothing",
+    "Alpha": 0.5,
+    "Window": 600000000000,
+    "Retain": 691200000000000
+  },
+  "Events": {
+    "Retain": 2592000000000000
+  },
+  "Policy": {
+    "Name": "step",
+    "Step": 2,
+    "Target": 80,
+    "Cycles": 3
   },
   "Server": {
     "Host": "drone.company.com",
@@ -222,8 +248,12 @@ var jsonConfig = []byte(`{
     "Concurrency": 2,
     "KeepaliveTime": 360000000000,
     "KeepaliveTimeout": 30000000000,
+    "LabelsMatch": "exact",
     "NamePrefix": "agent-"
   },
+  "Leader": {
+    "TTL": 30000000000
+  },
   "HTTP": {
     "Proto": "http",
     "Host": "autoscaler.drone.company.com",
@@ -279,7 +309,9 @@ var jsonConfig = []byte(`{
       "arch": "amd64"
     },
     "UserData": "#cloud-init",
-    "UserDataFile": "/path/to/cloud/init.yml"
+    "UserDataFile": "/path/to/cloud/init.yml",
+    "InterruptThreshold": 2,
+    "InterruptWindow": 3600000000000
   },
   "Google": {
     "Zone": ["us-central1-b","us-central1-a"],
@@ -304,7 +336,19 @@ var jsonConfig = []byte(`{
     "UserData": "#cloud-init",
     "UserDataFile": "/path/to/cloud/init.yml",
     "UserDataKey": "user-data",
-	"RateLimit": 20
+	"RateLimit": 20,
+    "ProvisioningModel": "STANDARD",
+    "TerminationAction": "DELETE"
+  },
+  "Azure": {
+    "SubscriptionID": "9fa0e3d2",
+    "ResourceGroup": "drone",
+    "Location": "westeurope",
+    "Size": "Standard_D2s_v3",
+    "PrivateIP": true,
+    "Tags": {
+      "env": "prod"
+    }
   },
   "HetznerCloud": {
     "Token": "12345678",
@@ -362,7 +406,29 @@ var jsonConfig = []byte(`{
     "Interval": 3600000000000
   },
   "Pinger": {
-    "Interval": 600000000000
+    "Interval": 600000000000,
+    "Action": "error",
+    "MaxRestarts": 3
+  },
+  "Interrupt": {
+    "Interval": 30000000000
+  },
+  "Drain": {
+    "Timeout": 3600000000000
+  },
+  "Reconcile": {
+    "Action": "destroy",
+    "Interval": 3600000000000
+  },
+  "Recovery": {
+    "Budget": 5,
+    "Backoff": 60000000000,
+    "BackoffMax": 3600000000000
+  },
+  "Rollout": {
+    "Surge": 1,
+    "Unavailable": 1,
+    "Interval": 60000000000
   },
   "Check": {
     "Interval": 60000000000,
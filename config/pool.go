@@ -0,0 +1,110 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Pool defines a named pool of servers. Each pool has its
+// own configuration, which allows a single autoscaler to
+// manage servers with different sizes, labels, and os or
+// architecture.
+type Pool struct {
+	Name   string
+	Config Config
+}
+
+// LoadPools loads the pool definitions from the pool file.
+// Each pool inherits the configuration loaded from the
+// environment, and overrides individual values using the
+// lowercase field names, for example:
+//
+//	pools:
+//	- name: arm64
+//	  pool:
+//	    min: 0
+//	    max: 4
+//	  agent:
+//	    arch: arm64
+//	    labels:
+//	      gpu: "false"
+//	  amazon:
+//	    instance: t4g.medium
+//
+// If the pool file is not configured, a single unnamed pool
+// is returned with the base configuration.
+func LoadPools(base Config) ([]Pool, error) {
+	if base.Pool.File == "" {
+		return []Pool{{Config: base}}, nil
+	}
+	data, err := ioutil.ReadFile(base.Pool.File)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePools(data)
+}
+
+// ParsePools parses the pool definitions. The configuration
+// is loaded from the environment for each pool, to ensure
+// that pools do not share maps or slices, and then
+// overridden with the pool definition.
+func ParsePools(data []byte) ([]Pool, error) {
+	file := struct {
+		Pools []yaml.MapSlice `yaml:"pools"`
+	}{}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	if len(file.Pools) == 0 {
+		return nil, errors.New("pool file does not define any pools")
+	}
+
+	var pools []Pool
+	names := map[string]struct{}{}
+	for _, item := range file.Pools {
+		name := struct {
+			Name string `yaml:"name"`
+		}{}
+		// the pool definition is re-encoded so that it can be
+		// decoded twice; once for the name and once on top of
+		// the base configuration.
+		raw, err := yaml.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(raw, &name); err != nil {
+			return nil, err
+		}
+		if name.Name == "" {
+			return nil, errors.New("pool name is required")
+		}
+		if _, ok := names[name.Name]; ok {
+			return nil, fmt.Errorf("duplicate pool name %q", name.Name)
+		}
+		names[name.Name] = struct{}{}
+
+		config, err := Load()
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(raw, &config); err != nil {
+			return nil, fmt.Errorf("pool %s: %s", name.Name, err)
+		}
+		config.Pool.File = ""
+		if err := Validate(config); err != nil {
+			return nil, fmt.Errorf("pool %s: %s", name.Name, err)
+		}
+		pools = append(pools, Pool{
+			Name:   name.Name,
+			Config: config,
+		})
+	}
+	return pools, nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadPools_Default(t *testing.T) {
+	base := MustLoad()
+	pools, err := LoadPools(base)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(pools), 1; got != want {
+		t.Errorf("Want %d pools, got %d", want, got)
+		return
+	}
+	if got, want := pools[0].Name, ""; got != want {
+		t.Errorf("Want unnamed pool, got %q", got)
+	}
+}
+
+func TestParsePools(t *testing.T) {
+	data := []byte(`
+pools:
+- name: amd64
+  pool:
+    min: 1
+    max: 8
+    minage: 10m
+  agent:
+    concurrency: 4
+    labels:
+      size: large
+  amazon:
+    instance: c5.xlarge
+- name: arm64
+  agent:
+    arch: arm64
+  amazon:
+    instance: t4g.medium
+`)
+	pools, err := ParsePools(data)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(pools), 2; got != want {
+		t.Errorf("Want %d pools, got %d", want, got)
+		return
+	}
+
+	amd64 := pools[0].Config
+	if got, want := pools[0].Name, "amd64"; got != want {
+		t.Errorf("Want pool name %s, got %s", want, got)
+	}
+	if got, want := amd64.Pool.Min, 1; got != want {
+		t.Errorf("Want pool min %d, got %d", want, got)
+	}
+	if got, want := amd64.Pool.Max, 8; got != want {
+		t.Errorf("Want pool max %d, got %d", want, got)
+	}
+	if got, want := amd64.Pool.MinAge, time.Minute*10; got != want {
+		t.Errorf("Want pool min age %s, got %s", want, got)
+	}
+	if got, want := amd64.Agent.Concurrency, 4; got != want {
+		t.Errorf("Want concurrency %d, got %d", want, got)
+	}
+	if got, want := amd64.Agent.Labels["size"], "large"; got != want {
+		t.Errorf("Want label %s, got %s", want, got)
+	}
+	if got, want := amd64.Amazon.Instance, "c5.xlarge"; got != want {
+		t.Errorf("Want instance %s, got %s", want, got)
+	}
+
+	arm64 := pools[1].Config
+	if got, want := pools[1].Name, "arm64"; got != want {
+		t.Errorf("Want pool name %s, got %s", want, got)
+	}
+	if got, want := arm64.Agent.Arch, "arm64"; got != want {
+		t.Errorf("Want arch %s, got %s", want, got)
+	}
+	if got, want := arm64.Amazon.Instance, "t4g.medium"; got != want {
+		t.Errorf("Want instance %s, got %s", want, got)
+	}
+	// values not defined by the pool are inherited from
+	// the environment.
+	if got, want := arm64.Pool.Max, 4; got != want {
+		t.Errorf("Want default pool max %d, got %d", want, got)
+	}
+	if _, ok := arm64.Agent.Labels["size"]; ok {
+		t.Errorf("Want labels not shared between pools")
+	}
+}
+
+func TestParsePools_Invalid(t *testing.T) {
+	tests := []string{
+		"pools: []",
+		"pools:\n- pool:\n    max: 1",
+		"pools:\n- name: a\n- name: a",
+		"pools:\n- name: a\n  pinger:\n    action: destroy",
+	}
+	for _, test := range tests {
+		if _, err := ParsePools([]byte(test)); err == nil {
+			t.Errorf("Want error parsing pools %q", test)
+		}
+	}
+}
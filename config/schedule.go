@@ -0,0 +1,55 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package config
+
+import (
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Schedule defines a cron-style schedule that overrides the
+// pool size and capacity buffer while the schedule is active.
+// The schedule is active for every minute that matches the
+// cron expression, evaluated in the schedule timezone.
+type Schedule struct {
+	Name     string `yaml:"name"`
+	Cron     string `yaml:"cron"`
+	Timezone string `yaml:"timezone"`
+	Min      *int   `yaml:"min"`
+	Max      *int   `yaml:"max"`
+	Buffer   *int   `yaml:"buffer"`
+}
+
+// LoadSchedules loads the schedules from the schedule file,
+// for example:
+//
+//	schedules:
+//	- name: office-hours
+//	  cron: "* 7-17 * * 1-5"
+//	  timezone: Europe/Berlin
+//	  min: 4
+//	  max: 10
+//	  buffer: 2
+//	- name: night
+//	  cron: "* 0-5 * * *"
+//	  timezone: Europe/Berlin
+//	  min: 0
+func LoadSchedules(path string) ([]Schedule, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSchedules(data)
+}
+
+// ParseSchedules parses the schedules.
+func ParseSchedules(data []byte) ([]Schedule, error) {
+	file := struct {
+		Schedules []Schedule `yaml:"schedules"`
+	}{}
+	err := yaml.Unmarshal(data, &file)
+	return file.Schedules, err
+}
@@ -0,0 +1,39 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package config
+
+import "testing"
+
+func TestParseSchedules(t *testing.T) {
+	data := []byte(`
+schedules:
+- name: office-hours
+  cron: "* 8-17 * * 1-5"
+  timezone: Europe/Berlin
+  min: 4
+  max: 10
+- name: night
+  cron: "* 0-5 * * *"
+  min: 0
+`)
+	schedules, err := ParseSchedules(data)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(schedules), 2; got != want {
+		t.Errorf("Want %d schedules, got %d", want, got)
+		return
+	}
+	if got, want := schedules[0].Timezone, "Europe/Berlin"; got != want {
+		t.Errorf("Want timezone %s, got %s", want, got)
+	}
+	if got := schedules[0].Buffer; got != nil {
+		t.Errorf("Want unset buffer, got %d", *got)
+	}
+	if got := schedules[1].Min; got == nil || *got != 0 {
+		t.Errorf("Want min override of zero")
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Validate returns an error if the configuration contains
+// invalid values, which are otherwise only detected, or
+// silently ignored, at runtime.
+func Validate(config Config) error {
+	switch config.Policy.Name {
+	case "", "default", "step", "target", "consecutive":
+	default:
+		return fmt.Errorf("invalid scaling policy %q", config.Policy.Name)
+	}
+	switch config.Agent.LabelsMatch {
+	case "exact", "subset", "key":
+	default:
+		return fmt.Errorf("invalid agent labels match %q", config.Agent.LabelsMatch)
+	}
+	switch config.Pinger.Action {
+	case "error", "replace":
+	default:
+		return fmt.Errorf("invalid pinger action %q", config.Pinger.Action)
+	}
+	for _, schedule := range config.Schedule.Entries {
+		if _, err := ParseCron(schedule.Cron); err != nil {
+			return fmt.Errorf("schedule %s: %s", schedule.Name, err)
+		}
+		if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+			return fmt.Errorf("schedule %s: invalid timezone %q", schedule.Name, schedule.Timezone)
+		}
+	}
+	return nil
+}
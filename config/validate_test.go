@@ -0,0 +1,83 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package config
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	if err := Validate(MustLoad()); err != nil {
+		t.Errorf("Want default configuration valid, got %s", err)
+	}
+}
+
+func TestValidate_PingerAction(t *testing.T) {
+	for _, action := range []string{"error", "replace"} {
+		conf := MustLoad()
+		conf.Pinger.Action = action
+		if err := Validate(conf); err != nil {
+			t.Errorf("Want pinger action %q valid, got %s", action, err)
+		}
+	}
+	conf := MustLoad()
+	conf.Pinger.Action = "destroy"
+	if err := Validate(conf); err == nil {
+		t.Errorf("Want error for invalid pinger action")
+	}
+}
+
+func TestValidate_Schedules(t *testing.T) {
+	conf := MustLoad()
+	conf.Schedule.Entries = []Schedule{
+		{Name: "office-hours", Cron: "* 7-17 * * 1-5", Timezone: "Europe/Berlin"},
+		{Name: "night", Cron: "* 0-5 * * *"},
+	}
+	if err := Validate(conf); err != nil {
+		t.Errorf("Want schedules valid, got %s", err)
+	}
+
+	conf.Schedule.Entries = []Schedule{
+		{Name: "office-hours", Cron: "* 7-17 * *", Timezone: "Europe/Berlin"},
+	}
+	if err := Validate(conf); err == nil {
+		t.Errorf("Want error for invalid schedule expression")
+	}
+
+	conf.Schedule.Entries = []Schedule{
+		{Name: "office-hours", Cron: "* 7-17 * * 1-5", Timezone: "Europe/Atlantis"},
+	}
+	if err := Validate(conf); err == nil {
+		t.Errorf("Want error for invalid schedule timezone")
+	}
+}
+
+func TestValidate_Policy(t *testing.T) {
+	for _, name := range []string{"default", "step", "target", "consecutive"} {
+		conf := MustLoad()
+		conf.Policy.Name = name
+		if err := Validate(conf); err != nil {
+			t.Errorf("Want policy %q valid, got %s", name, err)
+		}
+	}
+	conf := MustLoad()
+	conf.Policy.Name = "greedy"
+	if err := Validate(conf); err == nil {
+		t.Errorf("Want error for unknown scaling policy")
+	}
+}
+
+func TestValidate_LabelsMatch(t *testing.T) {
+	for _, match := range []string{"exact", "subset", "key"} {
+		conf := MustLoad()
+		conf.Agent.LabelsMatch = match
+		if err := Validate(conf); err != nil {
+			t.Errorf("Want labels match %q valid, got %s", match, err)
+		}
+	}
+	conf := MustLoad()
+	conf.Agent.LabelsMatch = "superset"
+	if err := Validate(conf); err == nil {
+		t.Errorf("Want error for invalid labels match")
+	}
+}
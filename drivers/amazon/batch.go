@@ -0,0 +1,214 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package amazon
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/drivers/internal/batch"
+	"github.com/drone/autoscaler/logger"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// BatchCreate creates the instances with a single request to
+// run instances, and tags the instances with the name of the
+// respective create options once launched. A single request
+// only accepts one userdata, so the instances are created
+// individually if the userdata is different for each instance.
+func (p *provider) BatchCreate(ctx context.Context, opts []autoscaler.InstanceCreateOpts) ([]*autoscaler.Instance, error) {
+	if len(opts) == 0 {
+		return nil, nil
+	}
+
+	_, ok, err := batch.Render(p.userdata, opts)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return p.limiter.CreateEach(ctx, opts, p.Create)
+	}
+
+	// the alternate size and subnets are preferred after
+	// repeated spot interruptions.
+	size, sizeAlt := p.preferredSizes()
+	subnets := p.preferredSubnets()
+
+	attemptOverrides := attemptOverrides{
+		attempt: 1,
+		size:    size,
+	}
+
+	tryCreateInAllSubnets := func() ([]*autoscaler.Instance, error) {
+		var (
+			instances []*autoscaler.Instance
+			err       error
+		)
+		for _, subnet := range subnets {
+			attemptOverrides.subnet = subnet
+
+			instances, err = p.batchCreate(ctx, opts, attemptOverrides)
+			// if the instances were provisioned (with or without errors), return the instances.
+			if instances != nil {
+				return instances, err
+			}
+
+			attemptOverrides.attempt++
+		}
+
+		return nil, fmt.Errorf("failed to create instances in all subnets: %w", err)
+	}
+
+	instances, err := tryCreateInAllSubnets()
+	if instances != nil {
+		return instances, err
+	}
+
+	// if the instances were not provisioned, and fallback
+	// parameters were provided, retry using the fallback
+	if sizeAlt != "" {
+		attemptOverrides.size = sizeAlt
+		instances, err = tryCreateInAllSubnets()
+	}
+	return instances, err
+}
+
+func (p *provider) batchCreate(ctx context.Context, opts []autoscaler.InstanceCreateOpts, overrides attemptOverrides) ([]*autoscaler.Instance, error) {
+	p.init.Do(func() {
+		p.setup(ctx)
+	})
+
+	in, err := p.runInstancesInput(opts[0], overrides)
+	if err != nil {
+		return nil, err
+	}
+	in.MinCount = aws.Int64(1)
+	in.MaxCount = aws.Int64(int64(len(opts)))
+
+	client := p.getClient()
+
+	logger := logger.FromContext(ctx).
+		WithField("attempt", overrides.attempt).
+		WithField("size", overrides.size).
+		WithField("subnet", overrides.subnet).
+		WithField("region", p.region).
+		WithField("image", p.image).
+		WithField("count", len(opts))
+	logger.Debug("instance batch create")
+
+	results, err := client.RunInstances(in)
+	if err != nil {
+		logger.WithError(err).
+			Error("instance batch create failed")
+		return nil, err
+	}
+
+	// amazon may launch fewer instances than requested,
+	// in which case the remaining entries are nil.
+	instances := make([]*autoscaler.Instance, len(opts))
+	var ids []*string
+	for i, amazonInstance := range results.Instances {
+		if i == len(opts) {
+			break
+		}
+		instances[i] = &autoscaler.Instance{
+			Provider: autoscaler.ProviderAmazon,
+			ID:       *amazonInstance.InstanceId,
+			Name:     opts[i].Name,
+			Size:     *amazonInstance.InstanceType,
+			Region:   *amazonInstance.Placement.AvailabilityZone,
+			Image:    *amazonInstance.ImageId,
+			Metadata: map[string]string{},
+		}
+		if overrides.subnet != "" {
+			instances[i].Metadata["subnet"] = overrides.subnet
+		}
+		if amazonInstance.SpotInstanceRequestId != nil {
+			instances[i].Metadata["spot-request"] = *amazonInstance.SpotInstanceRequestId
+		}
+		ids = append(ids, amazonInstance.InstanceId)
+
+		// the instances are launched with the name of the
+		// first instance, and must be renamed.
+		tags := p.owner.Labels(opts[i].Name)
+		tags["Name"] = opts[i].Name
+		_, err := client.CreateTags(&ec2.CreateTagsInput{
+			Resources: []*string{amazonInstance.InstanceId},
+			Tags:      convertTags(tags),
+		})
+		if err != nil {
+			logger.WithError(err).
+				WithField("name", opts[i].Name).
+				Warnln("cannot tag instance")
+		}
+	}
+
+	logger.WithField("launched", len(ids)).
+		Infoln("instance batch create success")
+
+	// poll the amazon endpoint for server updates
+	// and exit when a network address is allocated
+	// for every instance.
+	interval := time.Duration(0)
+poller:
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Debugln("instance network deadline exceeded")
+			return instances, ctx.Err()
+		case <-time.After(interval):
+			interval = time.Minute
+
+			logger.Debugln("check instance network")
+
+			desc, err := client.DescribeInstances(
+				&ec2.DescribeInstancesInput{
+					InstanceIds: ids,
+				},
+			)
+			if err != nil {
+				logger.WithError(err).
+					Warnln("instance details failed")
+				continue
+			}
+
+			addresses := map[string]string{}
+			for _, reservation := range desc.Reservations {
+				for _, amazonInstance := range reservation.Instances {
+					address := amazonInstance.PublicIpAddress
+					if p.privateIP {
+						address = amazonInstance.PrivateIpAddress
+					}
+					if address != nil {
+						addresses[*amazonInstance.InstanceId] = *address
+					}
+				}
+			}
+
+			for _, instance := range instances {
+				if instance != nil && instance.Address == "" {
+					instance.Address = addresses[instance.ID]
+				}
+			}
+			for _, instance := range instances {
+				if instance != nil && instance.Address == "" {
+					continue poller
+				}
+			}
+			break poller
+		}
+	}
+
+	logger.Debugln("instance batch network ready")
+
+	if len(ids) < len(opts) {
+		return instances, fmt.Errorf("launched %d of %d instances", len(ids), len(opts))
+	}
+	return instances, nil
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/drivers/internal/owner"
 	"github.com/drone/autoscaler/logger"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -25,9 +26,14 @@ type attemptOverrides struct {
 }
 
 func (p *provider) Create(ctx context.Context, opts autoscaler.InstanceCreateOpts) (*autoscaler.Instance, error) {
+	// the alternate size and subnets are preferred after
+	// repeated spot interruptions.
+	size, sizeAlt := p.preferredSizes()
+	subnets := p.preferredSubnets()
+
 	attemptOverrides := attemptOverrides{
 		attempt: 1,
-		size:    p.size,
+		size:    size,
 	}
 
 	tryCreateInAllSubnets := func() (*autoscaler.Instance, error) {
@@ -35,7 +41,7 @@ func (p *provider) Create(ctx context.Context, opts autoscaler.InstanceCreateOpt
 			instance *autoscaler.Instance
 			err      error
 		)
-		for _, subnet := range p.subnets {
+		for _, subnet := range subnets {
 			attemptOverrides.subnet = subnet
 
 			instance, err = p.create(ctx, opts, attemptOverrides)
@@ -59,8 +65,8 @@ func (p *provider) Create(ctx context.Context, opts autoscaler.InstanceCreateOpt
 
 	// if the instance was not provisioned, and fallback
 	// parameters were provided, retry using the fallback
-	if p.sizeAlt != "" {
-		attemptOverrides.size = p.sizeAlt
+	if sizeAlt != "" {
+		attemptOverrides.size = sizeAlt
 		instance, err = tryCreateInAllSubnets()
 	}
 
@@ -73,96 +79,13 @@ func (p *provider) create(ctx context.Context, opts autoscaler.InstanceCreateOpt
 		p.setup(ctx)
 	})
 
-	buf := new(bytes.Buffer)
-	err := p.userdata.Execute(buf, &opts)
+	in, err := p.runInstancesInput(opts, overrides)
 	if err != nil {
 		return nil, err
 	}
 
 	client := p.getClient()
 
-	var iamProfile *ec2.IamInstanceProfileSpecification
-
-	if p.iamProfileArn != "" {
-		iamProfile = &ec2.IamInstanceProfileSpecification{
-			Arn: &p.iamProfileArn,
-		}
-	}
-
-	var marketOptions *ec2.InstanceMarketOptionsRequest
-
-	if p.spotInstance == true {
-		marketOptions = &ec2.InstanceMarketOptionsRequest{
-			MarketType: aws.String("spot"),
-		}
-	}
-
-	tags := createCopy(p.tags)
-	tags["Name"] = opts.Name
-
-	var metadataOptions *ec2.InstanceMetadataOptionsRequest
-	if p.imdsTokens != "" {
-		metadataOptions = &ec2.InstanceMetadataOptionsRequest{
-			HttpTokens: aws.String(p.imdsTokens),
-		}
-	}
-
-	in := &ec2.RunInstancesInput{
-		KeyName:               aws.String(p.key),
-		ImageId:               aws.String(p.image),
-		InstanceType:          aws.String(overrides.size),
-		MinCount:              aws.Int64(1),
-		MaxCount:              aws.Int64(1),
-		InstanceMarketOptions: marketOptions,
-		IamInstanceProfile:    iamProfile,
-		UserData:              aws.String(base64.StdEncoding.EncodeToString(buf.Bytes())),
-		MetadataOptions:       metadataOptions,
-		NetworkInterfaces: []*ec2.InstanceNetworkInterfaceSpecification{
-			{
-				AssociatePublicIpAddress: aws.Bool(!p.privateIP),
-				DeviceIndex:              aws.Int64(0),
-				SubnetId:                 aws.String(overrides.subnet),
-				Groups:                   aws.StringSlice(p.groups),
-			},
-		},
-		TagSpecifications: []*ec2.TagSpecification{
-			{
-				ResourceType: aws.String("instance"),
-				Tags:         convertTags(tags),
-			},
-			{
-				ResourceType: aws.String("volume"),
-				Tags:         convertTags(tags),
-			},
-		},
-		BlockDeviceMappings: []*ec2.BlockDeviceMapping{
-			{
-				DeviceName: aws.String(p.deviceName),
-				Ebs: &ec2.EbsBlockDevice{
-					VolumeSize:          aws.Int64(p.volumeSize),
-					VolumeType:          aws.String(p.volumeType),
-					DeleteOnTermination: aws.Bool(true),
-				},
-			},
-		},
-	}
-
-	if p.volumeType == "io1" || p.volumeType == "io2" || p.volumeType == "gp3" {
-		for _, blockDeviceMapping := range in.BlockDeviceMappings {
-			if p.volumeIops > 0 {
-				blockDeviceMapping.Ebs.Iops = aws.Int64(p.volumeIops)
-			}
-		}
-	}
-
-	if p.volumeType == "gp3" {
-		for _, blockDeviceMapping := range in.BlockDeviceMappings {
-			if p.volumeThroughput > 0 {
-				blockDeviceMapping.Ebs.Throughput = aws.Int64(p.volumeThroughput)
-			}
-		}
-	}
-
 	logger := logger.FromContext(ctx).
 		WithField("attempt", overrides.attempt).
 		WithField("size", overrides.size).
@@ -188,6 +111,13 @@ func (p *provider) create(ctx context.Context, opts autoscaler.InstanceCreateOpt
 		Size:     *amazonInstance.InstanceType,
 		Region:   *amazonInstance.Placement.AvailabilityZone,
 		Image:    *amazonInstance.ImageId,
+		Metadata: map[string]string{},
+	}
+	if overrides.subnet != "" {
+		instance.Metadata["subnet"] = overrides.subnet
+	}
+	if amazonInstance.SpotInstanceRequestId != nil {
+		instance.Metadata["spot-request"] = *amazonInstance.SpotInstanceRequestId
 	}
 
 	logger.WithField("name", instance.Name).
@@ -255,3 +185,97 @@ poller:
 
 	return instance, nil
 }
+
+// helper function returns the input used to run instances
+// for the create options.
+func (p *provider) runInstancesInput(opts autoscaler.InstanceCreateOpts, overrides attemptOverrides) (*ec2.RunInstancesInput, error) {
+	buf := new(bytes.Buffer)
+	err := p.userdata.Execute(buf, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var iamProfile *ec2.IamInstanceProfileSpecification
+
+	if p.iamProfileArn != "" {
+		iamProfile = &ec2.IamInstanceProfileSpecification{
+			Arn: &p.iamProfileArn,
+		}
+	}
+
+	var marketOptions *ec2.InstanceMarketOptionsRequest
+
+	if p.spotInstance == true {
+		marketOptions = &ec2.InstanceMarketOptionsRequest{
+			MarketType: aws.String("spot"),
+		}
+	}
+
+	tags := owner.Merge(p.tags, p.owner.Labels(opts.Name))
+	tags["Name"] = opts.Name
+
+	var metadataOptions *ec2.InstanceMetadataOptionsRequest
+	if p.imdsTokens != "" {
+		metadataOptions = &ec2.InstanceMetadataOptionsRequest{
+			HttpTokens: aws.String(p.imdsTokens),
+		}
+	}
+
+	in := &ec2.RunInstancesInput{
+		KeyName:               aws.String(p.key),
+		ImageId:               aws.String(p.image),
+		InstanceType:          aws.String(overrides.size),
+		MinCount:              aws.Int64(1),
+		MaxCount:              aws.Int64(1),
+		InstanceMarketOptions: marketOptions,
+		IamInstanceProfile:    iamProfile,
+		UserData:              aws.String(base64.StdEncoding.EncodeToString(buf.Bytes())),
+		MetadataOptions:       metadataOptions,
+		NetworkInterfaces: []*ec2.InstanceNetworkInterfaceSpecification{
+			{
+				AssociatePublicIpAddress: aws.Bool(!p.privateIP),
+				DeviceIndex:              aws.Int64(0),
+				SubnetId:                 aws.String(overrides.subnet),
+				Groups:                   aws.StringSlice(p.groups),
+			},
+		},
+		TagSpecifications: []*ec2.TagSpecification{
+			{
+				ResourceType: aws.String("instance"),
+				Tags:         convertTags(tags),
+			},
+			{
+				ResourceType: aws.String("volume"),
+				Tags:         convertTags(tags),
+			},
+		},
+		BlockDeviceMappings: []*ec2.BlockDeviceMapping{
+			{
+				DeviceName: aws.String(p.deviceName),
+				Ebs: &ec2.EbsBlockDevice{
+					VolumeSize:          aws.Int64(p.volumeSize),
+					VolumeType:          aws.String(p.volumeType),
+					DeleteOnTermination: aws.Bool(true),
+				},
+			},
+		},
+	}
+
+	if p.volumeType == "io1" || p.volumeType == "io2" || p.volumeType == "gp3" {
+		for _, blockDeviceMapping := range in.BlockDeviceMappings {
+			if p.volumeIops > 0 {
+				blockDeviceMapping.Ebs.Iops = aws.Int64(p.volumeIops)
+			}
+		}
+	}
+
+	if p.volumeType == "gp3" {
+		for _, blockDeviceMapping := range in.BlockDeviceMappings {
+			if p.volumeThroughput > 0 {
+				blockDeviceMapping.Ebs.Throughput = aws.Int64(p.volumeThroughput)
+			}
+		}
+	}
+
+	return in, nil
+}
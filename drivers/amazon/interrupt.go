@@ -0,0 +1,166 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package amazon
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/logger"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// Interrupted returns the spot instances that received an
+// interruption notice. The instance type and subnet of each
+// interrupted instance are recorded, and are avoided when
+// creating new instances after repeated interruptions.
+func (p *provider) Interrupted(ctx context.Context, instances []*autoscaler.Instance) ([]*autoscaler.Instance, error) {
+	if !p.spotInstance || len(instances) == 0 {
+		return nil, nil
+	}
+
+	logger := logger.FromContext(ctx)
+
+	ids := map[string]*autoscaler.Instance{}
+	var values []*string
+	for _, instance := range instances {
+		ids[instance.ID] = instance
+		values = append(values, aws.String(instance.ID))
+	}
+
+	out, err := p.getClient().DescribeSpotInstanceRequests(
+		&ec2.DescribeSpotInstanceRequestsInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("instance-id"),
+					Values: values,
+				},
+			},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	var interrupted []*autoscaler.Instance
+	for _, request := range out.SpotInstanceRequests {
+		if request.Status == nil || !isInterruption(aws.StringValue(request.Status.Code)) {
+			continue
+		}
+		instance, ok := ids[aws.StringValue(request.InstanceId)]
+		if !ok {
+			continue
+		}
+		interrupted = append(interrupted, instance)
+
+		p.interrupts.record(sizeKey(instance.Size), now)
+		if spec := request.LaunchSpecification; spec != nil {
+			subnet := aws.StringValue(spec.SubnetId)
+			if subnet == "" && len(spec.NetworkInterfaces) != 0 {
+				subnet = aws.StringValue(spec.NetworkInterfaces[0].SubnetId)
+			}
+			if subnet != "" {
+				p.interrupts.record(subnetKey(subnet), now)
+			}
+		}
+
+		logger.WithField("id", instance.ID).
+			WithField("name", instance.Name).
+			WithField("code", aws.StringValue(request.Status.Code)).
+			Debugln("instance interrupted")
+	}
+	return interrupted, nil
+}
+
+// helper function returns the primary and alternate instance
+// size. The alternate size is preferred after repeated
+// interruptions of the primary size.
+func (p *provider) preferredSizes() (string, string) {
+	if p.sizeAlt != "" && p.interrupts.avoid(sizeKey(p.size), time.Now()) {
+		return p.sizeAlt, p.size
+	}
+	return p.size, p.sizeAlt
+}
+
+// helper function returns the subnets ordered by preference.
+// Subnets with repeated interruptions are tried last.
+func (p *provider) preferredSubnets() []string {
+	now := time.Now()
+	subnets := make([]string, len(p.subnets))
+	copy(subnets, p.subnets)
+	sort.SliceStable(subnets, func(i, j int) bool {
+		return !p.interrupts.avoid(subnetKey(subnets[i]), now) &&
+			p.interrupts.avoid(subnetKey(subnets[j]), now)
+	})
+	return subnets
+}
+
+func sizeKey(size string) string     { return "size:" + size }
+func subnetKey(subnet string) string { return "subnet:" + subnet }
+
+// helper function returns true if the spot request status
+// code indicates the instance is, or will be, interrupted.
+// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/spot-request-status.html
+func isInterruption(code string) bool {
+	switch {
+	case strings.HasPrefix(code, "marked-for-"):
+		return true
+	case code == "instance-terminated-by-user",
+		code == "instance-stopped-by-user":
+		return false
+	case strings.HasPrefix(code, "instance-terminated-"),
+		strings.HasPrefix(code, "instance-stopped-"):
+		return true
+	default:
+		return false
+	}
+}
+
+// interruptions records recent interruptions by key, and
+// reports keys that were interrupted repeatedly within the
+// window and should be avoided.
+type interruptions struct {
+	sync.Mutex
+
+	threshold int
+	window    time.Duration
+	events    map[string][]time.Time
+}
+
+// record records an interruption for the key.
+func (i *interruptions) record(key string, t time.Time) {
+	i.Lock()
+	defer i.Unlock()
+	if i.events == nil {
+		i.events = map[string][]time.Time{}
+	}
+	i.events[key] = append(i.events[key], t)
+}
+
+// avoid returns true if the number of interruptions for the
+// key within the window reaches the threshold.
+func (i *interruptions) avoid(key string, now time.Time) bool {
+	i.Lock()
+	defer i.Unlock()
+	if i.threshold <= 0 || i.events == nil {
+		return false
+	}
+	var recent []time.Time
+	for _, t := range i.events[key] {
+		if now.Sub(t) < i.window {
+			recent = append(recent, t)
+		}
+	}
+	i.events[key] = recent
+	return len(recent) >= i.threshold
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package amazon
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsInterruption(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"marked-for-termination", true},
+		{"marked-for-stop", true},
+		{"instance-terminated-no-capacity", true},
+		{"instance-stopped-by-price", true},
+		{"instance-terminated-by-user", false},
+		{"instance-stopped-by-user", false},
+		{"fulfilled", false},
+	}
+	for _, test := range tests {
+		if got := isInterruption(test.code); got != test.want {
+			t.Errorf("Want interruption %v for code %s, got %v", test.want, test.code, got)
+		}
+	}
+}
+
+func TestInterruptions(t *testing.T) {
+	now := time.Now()
+	i := interruptions{threshold: 2, window: time.Hour}
+	i.record("size:t3.medium", now.Add(-time.Hour*2))
+	i.record("size:t3.medium", now)
+	if i.avoid("size:t3.medium", now) {
+		t.Errorf("Want interruptions outside the window ignored")
+	}
+	i.record("size:t3.medium", now)
+	if !i.avoid("size:t3.medium", now) {
+		t.Errorf("Want size avoided after repeated interruptions")
+	}
+	if i.avoid("size:t3.large", now) {
+		t.Errorf("Want size without interruptions not avoided")
+	}
+}
+
+func TestPreferredSizes(t *testing.T) {
+	p := New(
+		WithSize("t3.medium"),
+		WithSizeAlt("t3a.medium"),
+		WithSubnets([]string{"subnet-a", "subnet-b", "subnet-c"}),
+		WithInterruptThreshold(1),
+	).(*provider)
+
+	if size, alt := p.preferredSizes(); size != "t3.medium" || alt != "t3a.medium" {
+		t.Errorf("Want primary size preferred, got %s, %s", size, alt)
+	}
+
+	p.interrupts.record(sizeKey("t3.medium"), time.Now())
+	p.interrupts.record(subnetKey("subnet-a"), time.Now())
+
+	if size, alt := p.preferredSizes(); size != "t3a.medium" || alt != "t3.medium" {
+		t.Errorf("Want alternate size preferred, got %s, %s", size, alt)
+	}
+	subnets := p.preferredSubnets()
+	if got, want := subnets[0], "subnet-b"; got != want {
+		t.Errorf("Want subnet %s preferred, got %s", want, got)
+	}
+	if got, want := subnets[2], "subnet-a"; got != want {
+		t.Errorf("Want subnet %s tried last, got %s", want, got)
+	}
+	if got, want := p.subnets[0], "subnet-a"; got != want {
+		t.Errorf("Want configured subnets unchanged")
+	}
+}
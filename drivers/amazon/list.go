@@ -0,0 +1,101 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package amazon
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/drone/autoscaler"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// errNoTags is returned when listing instances without an
+// owner or any configured tags, since the instances that
+// belong to the autoscaler cannot be distinguished from
+// other instances.
+var errNoTags = errors.New("amazon: cannot list instances without tags")
+
+// List returns the instances that match the ownership tags,
+// or the configured tags if the owner is unknown. Terminated
+// instances are ignored.
+func (p *provider) List(ctx context.Context) ([]*autoscaler.Instance, error) {
+	filters, err := p.listFilters()
+	if err != nil {
+		return nil, err
+	}
+
+	var instances []*autoscaler.Instance
+	err = p.getClient().DescribeInstancesPagesWithContext(ctx,
+		&ec2.DescribeInstancesInput{Filters: filters},
+		func(page *ec2.DescribeInstancesOutput, _ bool) bool {
+			for _, reservation := range page.Reservations {
+				for _, in := range reservation.Instances {
+					instances = append(instances, p.convert(in))
+				}
+			}
+			return true
+		},
+	)
+	return instances, err
+}
+
+// helper function returns the filters that match instances
+// with the ownership tags or the configured tags.
+func (p *provider) listFilters() ([]*ec2.Filter, error) {
+	tags := p.owner.Selector()
+	if len(tags) == 0 {
+		tags = p.tags
+	}
+	if len(tags) == 0 {
+		return nil, errNoTags
+	}
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	filters := []*ec2.Filter{
+		{
+			Name:   aws.String("instance-state-name"),
+			Values: aws.StringSlice([]string{"pending", "running", "stopping", "stopped"}),
+		},
+	}
+	for _, key := range keys {
+		filters = append(filters, &ec2.Filter{
+			Name:   aws.String("tag:" + key),
+			Values: aws.StringSlice([]string{tags[key]}),
+		})
+	}
+	return filters, nil
+}
+
+// helper function converts the ec2 instance to an instance.
+func (p *provider) convert(in *ec2.Instance) *autoscaler.Instance {
+	instance := &autoscaler.Instance{
+		Provider: autoscaler.ProviderAmazon,
+		ID:       aws.StringValue(in.InstanceId),
+		Size:     aws.StringValue(in.InstanceType),
+		Image:    aws.StringValue(in.ImageId),
+	}
+	if in.Placement != nil {
+		instance.Region = aws.StringValue(in.Placement.AvailabilityZone)
+	}
+	for _, tag := range in.Tags {
+		if aws.StringValue(tag.Key) == "Name" {
+			instance.Name = aws.StringValue(tag.Value)
+		}
+	}
+	if p.privateIP {
+		instance.Address = aws.StringValue(in.PrivateIpAddress)
+	} else {
+		instance.Address = aws.StringValue(in.PublicIpAddress)
+	}
+	return instance
+}
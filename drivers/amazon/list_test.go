@@ -0,0 +1,87 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package amazon
+
+import (
+	"testing"
+
+	"github.com/drone/autoscaler/drivers/internal/owner"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestListFilters(t *testing.T) {
+	p := &provider{}
+	if _, err := p.listFilters(); err != errNoTags {
+		t.Errorf("Want error listing instances without tags")
+	}
+
+	p.tags = map[string]string{"role": "drone", "env": "ci"}
+	filters, err := p.listFilters()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(filters), 3; got != want {
+		t.Errorf("Want %d filters, got %d", want, got)
+		return
+	}
+	if got, want := aws.StringValue(filters[1].Name), "tag:env"; got != want {
+		t.Errorf("Want filter %s, got %s", want, got)
+	}
+	if got, want := aws.StringValue(filters[2].Name), "tag:role"; got != want {
+		t.Errorf("Want filter %s, got %s", want, got)
+	}
+
+	// the ownership tags are preferred to the configured
+	// tags when the owner is known.
+	p.owner = owner.Owner{ID: "drone", Pool: "amd64"}
+	filters, err = p.listFilters()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(filters), 3; got != want {
+		t.Errorf("Want %d filters, got %d", want, got)
+		return
+	}
+	if got, want := aws.StringValue(filters[1].Name), "tag:"+owner.KeyID; got != want {
+		t.Errorf("Want filter %s, got %s", want, got)
+	}
+	if got, want := aws.StringValue(filters[2].Name), "tag:"+owner.KeyPool; got != want {
+		t.Errorf("Want filter %s, got %s", want, got)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	in := &ec2.Instance{
+		InstanceId:       aws.String("i-1234"),
+		InstanceType:     aws.String("t3.medium"),
+		ImageId:          aws.String("ami-1234"),
+		PrivateIpAddress: aws.String("10.0.0.1"),
+		PublicIpAddress:  aws.String("1.2.3.4"),
+		Placement:        &ec2.Placement{AvailabilityZone: aws.String("us-east-1a")},
+		Tags:             []*ec2.Tag{{Key: aws.String("Name"), Value: aws.String("agent-1")}},
+	}
+
+	p := &provider{}
+	instance := p.convert(in)
+	if got, want := instance.Name, "agent-1"; got != want {
+		t.Errorf("Want name %s, got %s", want, got)
+	}
+	if got, want := instance.Address, "1.2.3.4"; got != want {
+		t.Errorf("Want address %s, got %s", want, got)
+	}
+	if got, want := instance.Region, "us-east-1a"; got != want {
+		t.Errorf("Want region %s, got %s", want, got)
+	}
+
+	p.privateIP = true
+	instance = p.convert(in)
+	if got, want := instance.Address, "10.0.0.1"; got != want {
+		t.Errorf("Want private address %s, got %s", want, got)
+	}
+}
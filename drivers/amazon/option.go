@@ -6,7 +6,10 @@ package amazon
 
 import (
 	"io/ioutil"
+	"time"
 
+	"github.com/drone/autoscaler/drivers/internal/batch"
+	"github.com/drone/autoscaler/drivers/internal/owner"
 	"github.com/drone/autoscaler/drivers/internal/userdata"
 )
 
@@ -34,6 +37,17 @@ func WithPrivateIP(private bool) Option {
 	}
 }
 
+// WithBatchConcurrency returns an option to set the number
+// of servers created in parallel when a batch cannot be
+// created with a single request.
+func WithBatchConcurrency(n int) Option {
+	return func(p *provider) {
+		if n > 0 {
+			p.limiter = batch.NewLimiter(n)
+		}
+	}
+}
+
 // WithRetries returns an option to set the retry count.
 func WithRetries(retries int) Option {
 	return func(p *provider) {
@@ -159,9 +173,36 @@ func WithInstanceMetadataTokens(t string) Option {
 	}
 }
 
+// WithInterruptThreshold returns an option to set the number
+// of spot interruptions, within the interrupt window, after
+// which the alternate instance size and subnets are preferred.
+// A zero value disables the preference.
+func WithInterruptThreshold(n int) Option {
+	return func(p *provider) {
+		p.interrupts.threshold = n
+	}
+}
+
+// WithInterruptWindow returns an option to set the window in
+// which spot interruptions are counted.
+func WithInterruptWindow(d time.Duration) Option {
+	return func(p *provider) {
+		p.interrupts.window = d
+	}
+}
+
 // WithMarketType returns an option to set the instance market type.
 func WithMarketType(t string) Option {
 	return func(p *provider) {
 		p.spotInstance = t == "spot"
 	}
 }
+
+// WithOwner returns an option to set the autoscaler identifier
+// and pool name added to the ownership metadata of every
+// instance.
+func WithOwner(id, pool string) Option {
+	return func(p *provider) {
+		p.owner = owner.Owner{ID: id, Pool: pool}
+	}
+}
@@ -7,8 +7,11 @@ package amazon
 import (
 	"sync"
 	"text/template"
+	"time"
 
 	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/drivers/internal/batch"
+	"github.com/drone/autoscaler/drivers/internal/owner"
 	"github.com/drone/autoscaler/drivers/internal/userdata"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -35,9 +38,13 @@ type provider struct {
 	subnets          []string
 	groups           []string
 	tags             map[string]string
+	owner            owner.Owner
 	iamProfileArn    string
 	spotInstance     bool
 	imdsTokens       string
+
+	interrupts interruptions
+	limiter    batch.Limiter
 }
 
 func (p *provider) getClient() *ec2.EC2 {
@@ -87,5 +94,11 @@ func New(opts ...Option) autoscaler.Provider {
 	if p.userdata == nil {
 		p.userdata = userdata.T
 	}
+	if p.interrupts.window == 0 {
+		p.interrupts.window = time.Hour
+	}
+	if p.limiter == nil {
+		p.limiter = batch.NewLimiter(batch.DefaultConcurrency)
+	}
 	return p
 }
@@ -0,0 +1,112 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package amazon
+
+import (
+	"context"
+	"time"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/logger"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func (p *provider) Start(ctx context.Context, instance *autoscaler.Instance) (*autoscaler.Instance, error) {
+	logger := logger.FromContext(ctx).
+		WithField("id", instance.ID).
+		WithField("name", instance.Name).
+		WithField("zone", instance.Region)
+
+	logger.Debugln("start instance")
+
+	client := p.getClient()
+
+	input := &ec2.StartInstancesInput{
+		InstanceIds: []*string{
+			aws.String(instance.ID),
+		},
+	}
+	_, err := client.StartInstances(input)
+	if awsErr, ok := err.(awserr.Error); ok {
+		switch awsErr.Code() {
+		case ec2.UnsuccessfulInstanceCreditSpecificationErrorCodeInvalidInstanceIdMalformed:
+			fallthrough
+		case ec2.UnsuccessfulInstanceCreditSpecificationErrorCodeInvalidInstanceIdNotFound:
+			logger.Debugln("instance does not exist")
+			return nil, autoscaler.ErrInstanceNotFound
+		}
+	}
+	if err != nil {
+		logger.WithError(err).
+			Errorln("cannot start instance")
+		return nil, err
+	}
+
+	// the public network address is released when the
+	// instance is stopped. poll the amazon endpoint for
+	// server updates and exit when a network address
+	// is allocated and the instance is running.
+	started := *instance
+	started.Address = ""
+
+	interval := time.Duration(0)
+poller:
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Debugln("instance network deadline exceeded")
+			return &started, ctx.Err()
+		case <-time.After(interval):
+			interval = time.Second * 15
+
+			logger.Debugln("check instance network")
+
+			desc, err := client.DescribeInstances(
+				&ec2.DescribeInstancesInput{
+					InstanceIds: []*string{
+						aws.String(instance.ID),
+					},
+				},
+			)
+			if err != nil {
+				logger.WithError(err).
+					Warnln("instance details failed")
+				continue
+			}
+			if len(desc.Reservations) == 0 ||
+				len(desc.Reservations[0].Instances) == 0 {
+				logger.Warnln("empty instances in details")
+				continue
+			}
+
+			amazonInstance := desc.Reservations[0].Instances[0]
+			if amazonInstance.State == nil ||
+				aws.StringValue(amazonInstance.State.Name) != ec2.InstanceStateNameRunning {
+				continue
+			}
+
+			if p.privateIP {
+				if amazonInstance.PrivateIpAddress != nil {
+					started.Address = *amazonInstance.PrivateIpAddress
+					break poller
+				}
+			}
+
+			if amazonInstance.PublicIpAddress != nil {
+				started.Address = *amazonInstance.PublicIpAddress
+				break poller
+			}
+		}
+	}
+
+	logger.
+		WithField("ip", started.Address).
+		Debugln("instance started")
+
+	return &started, nil
+}
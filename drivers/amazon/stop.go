@@ -0,0 +1,51 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package amazon
+
+import (
+	"context"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/logger"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func (p *provider) Stop(ctx context.Context, instance *autoscaler.Instance) error {
+	logger := logger.FromContext(ctx).
+		WithField("id", instance.ID).
+		WithField("ip", instance.Address).
+		WithField("name", instance.Name).
+		WithField("zone", instance.Region)
+
+	logger.Debugln("stop instance")
+
+	input := &ec2.StopInstancesInput{
+		InstanceIds: []*string{
+			aws.String(instance.ID),
+		},
+	}
+	_, err := p.getClient().StopInstances(input)
+	if awsErr, ok := err.(awserr.Error); ok {
+		switch awsErr.Code() {
+		case ec2.UnsuccessfulInstanceCreditSpecificationErrorCodeInvalidInstanceIdMalformed:
+			fallthrough
+		case ec2.UnsuccessfulInstanceCreditSpecificationErrorCodeInvalidInstanceIdNotFound:
+			logger.Debugln("instance does not exist")
+			return autoscaler.ErrInstanceNotFound
+		}
+	}
+	if err != nil {
+		logger.WithError(err).
+			Errorln("cannot stop instance")
+		return err
+	}
+
+	logger.Debugln("stopped")
+
+	return nil
+}
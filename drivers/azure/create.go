@@ -3,3 +3,285 @@
 // that can be found in the LICENSE file.
 
 package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/drivers/internal/owner"
+	"github.com/drone/autoscaler/logger"
+)
+
+// the interval at which the provisioning state of the
+// virtual machine is polled.
+var pollInterval = time.Second * 15
+
+type (
+	virtualMachine struct {
+		ID         string            `json:"id,omitempty"`
+		Name       string            `json:"name,omitempty"`
+		Location   string            `json:"location"`
+		Tags       map[string]string `json:"tags,omitempty"`
+		Properties vmProperties      `json:"properties"`
+	}
+
+	vmProperties struct {
+		ProvisioningState string                 `json:"provisioningState,omitempty"`
+		HardwareProfile   map[string]interface{} `json:"hardwareProfile,omitempty"`
+		StorageProfile    map[string]interface{} `json:"storageProfile,omitempty"`
+		OSProfile         map[string]interface{} `json:"osProfile,omitempty"`
+		NetworkProfile    map[string]interface{} `json:"networkProfile,omitempty"`
+	}
+
+	networkInterface struct {
+		Properties struct {
+			IPConfigurations []struct {
+				Properties struct {
+					PrivateIPAddress string `json:"privateIPAddress"`
+				} `json:"properties"`
+			} `json:"ipConfigurations"`
+		} `json:"properties"`
+	}
+
+	publicIPAddress struct {
+		Properties struct {
+			IPAddress string `json:"ipAddress"`
+		} `json:"properties"`
+	}
+)
+
+func (p *provider) Create(ctx context.Context, opts autoscaler.InstanceCreateOpts) (*autoscaler.Instance, error) {
+	p.init.Do(func() {
+		p.setup(ctx)
+	})
+
+	buf := new(bytes.Buffer)
+	err := p.userdata.Execute(buf, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := logger.FromContext(ctx).
+		WithField("location", p.location).
+		WithField("image", p.image).
+		WithField("size", p.size).
+		WithField("name", opts.Name)
+
+	path := p.resourcePath("Microsoft.Compute/virtualMachines", opts.Name)
+	in := p.virtualMachine(opts.Name, buf.Bytes())
+
+	logger.Debugln("instance create")
+
+	out := new(virtualMachine)
+	err = p.do(ctx, http.MethodPut, path, computeVersion, in, out)
+	if err != nil {
+		logger.WithError(err).
+			Errorln("cannot create instance")
+		return nil, err
+	}
+
+	instance := &autoscaler.Instance{
+		Provider: autoscaler.ProviderAzure,
+		ID:       path,
+		Name:     opts.Name,
+		Region:   p.location,
+		Image:    p.image,
+		Size:     p.size,
+	}
+	if out.ID != "" {
+		instance.ID = out.ID
+	}
+
+	logger.WithField("name", instance.Name).
+		Infoln("instance created")
+
+	// poll the azure endpoint for the provisioning state
+	// and exit when the virtual machine is provisioned.
+	interval := time.Duration(0)
+poller:
+	for {
+		select {
+		case <-ctx.Done():
+			logger.WithField("name", instance.Name).
+				Debugln("instance provisioning deadline exceeded")
+
+			return instance, ctx.Err()
+		case <-time.After(interval):
+			interval = pollInterval
+
+			logger.WithField("name", instance.Name).
+				Debugln("check instance provisioning state")
+
+			err = p.do(ctx, http.MethodGet, path, computeVersion, nil, out)
+			if err != nil {
+				logger.WithError(err).
+					Warnln("instance details failed")
+				continue
+			}
+
+			switch out.Properties.ProvisioningState {
+			case "Succeeded":
+				break poller
+			case "Failed", "Canceled":
+				logger.WithField("state", out.Properties.ProvisioningState).
+					Errorln("instance provisioning failed")
+				return instance, fmt.Errorf("azure: instance provisioning %s",
+					strings.ToLower(out.Properties.ProvisioningState))
+			}
+		}
+	}
+
+	instance.Address, err = p.address(ctx, opts.Name)
+	if err != nil {
+		logger.WithError(err).
+			Errorln("cannot find instance network")
+		return instance, err
+	}
+
+	logger.
+		WithField("name", instance.Name).
+		WithField("ip", instance.Address).
+		Debugln("instance network ready")
+
+	return instance, nil
+}
+
+// helper function returns the public or private address
+// of the named virtual machine.
+func (p *provider) address(ctx context.Context, name string) (string, error) {
+	if p.privateIP {
+		out := new(networkInterface)
+		path := p.resourcePath("Microsoft.Network/networkInterfaces", name+"-nic")
+		err := p.do(ctx, http.MethodGet, path, networkVersion, nil, out)
+		if err != nil {
+			return "", err
+		}
+		if len(out.Properties.IPConfigurations) == 0 {
+			return "", errors.New("azure: instance not assigned ip")
+		}
+		return out.Properties.IPConfigurations[0].Properties.PrivateIPAddress, nil
+	}
+
+	out := new(publicIPAddress)
+	path := p.resourcePath("Microsoft.Network/publicIPAddresses", name+"-ip")
+	err := p.do(ctx, http.MethodGet, path, networkVersion, nil, out)
+	if err != nil {
+		return "", err
+	}
+	if out.Properties.IPAddress == "" {
+		return "", errors.New("azure: instance not assigned ip")
+	}
+	return out.Properties.IPAddress, nil
+}
+
+// helper function returns the virtual machine definition.
+// The network interface, public ip address and os disk are
+// created inline and deleted together with the virtual
+// machine.
+func (p *provider) virtualMachine(name string, data []byte) *virtualMachine {
+	ipconfig := map[string]interface{}{
+		"subnet": map[string]interface{}{
+			"id": p.subnet,
+		},
+	}
+	if !p.privateIP {
+		ipconfig["publicIPAddressConfiguration"] = map[string]interface{}{
+			"name": name + "-ip",
+			"sku": map[string]interface{}{
+				"name": "Standard",
+			},
+			"properties": map[string]interface{}{
+				"deleteOption":             "Delete",
+				"publicIPAllocationMethod": "Static",
+			},
+		}
+	}
+
+	nic := map[string]interface{}{
+		"deleteOption": "Delete",
+		"primary":      true,
+		"ipConfigurations": []interface{}{
+			map[string]interface{}{
+				"name":       name + "-ipconfig",
+				"properties": ipconfig,
+			},
+		},
+	}
+	if p.securityGroup != "" {
+		nic["networkSecurityGroup"] = map[string]interface{}{
+			"id": p.securityGroup,
+		}
+	}
+
+	return &virtualMachine{
+		Location: p.location,
+		Tags:     owner.Merge(p.tags, p.owner.Labels(name)),
+		Properties: vmProperties{
+			HardwareProfile: map[string]interface{}{
+				"vmSize": p.size,
+			},
+			StorageProfile: map[string]interface{}{
+				"imageReference": imageReference(p.image),
+				"osDisk": map[string]interface{}{
+					"name":         name + "-disk",
+					"createOption": "FromImage",
+					"deleteOption": "Delete",
+					"diskSizeGB":   p.diskSize,
+					"managedDisk": map[string]interface{}{
+						"storageAccountType": p.diskType,
+					},
+				},
+			},
+			OSProfile: map[string]interface{}{
+				"computerName":  name,
+				"adminUsername": p.username,
+				"customData":    base64.StdEncoding.EncodeToString(data),
+				"linuxConfiguration": map[string]interface{}{
+					"disablePasswordAuthentication": true,
+					"ssh": map[string]interface{}{
+						"publicKeys": []interface{}{
+							map[string]interface{}{
+								"path":    fmt.Sprintf("/home/%s/.ssh/authorized_keys", p.username),
+								"keyData": p.key,
+							},
+						},
+					},
+				},
+			},
+			NetworkProfile: map[string]interface{}{
+				"networkApiVersion": "2020-11-01",
+				"networkInterfaceConfigurations": []interface{}{
+					map[string]interface{}{
+						"name":       name + "-nic",
+						"properties": nic,
+					},
+				},
+			},
+		},
+	}
+}
+
+// helper function returns the image reference for an image
+// in the publisher:offer:sku:version format, or for a custom
+// image resource id.
+func imageReference(image string) map[string]interface{} {
+	parts := strings.Split(image, ":")
+	if len(parts) != 4 {
+		return map[string]interface{}{
+			"id": image,
+		}
+	}
+	return map[string]interface{}{
+		"publisher": parts[0],
+		"offer":     parts[1],
+		"sku":       parts[2],
+		"version":   parts[3],
+	}
+}
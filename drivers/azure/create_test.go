@@ -3,3 +3,242 @@
 // that can be found in the LICENSE file.
 
 package azure
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/drone/autoscaler"
+
+	"github.com/h2non/gock"
+)
+
+const (
+	mockVirtualMachine   = "/subscriptions/sub/resourceGroups/drone/providers/Microsoft.Compute/virtualMachines/agent-807jVFwj"
+	mockPublicIPAddress  = "/subscriptions/sub/resourceGroups/drone/providers/Microsoft.Network/publicIPAddresses/agent-807jVFwj-ip"
+	mockNetworkInterface = "/subscriptions/sub/resourceGroups/drone/providers/Microsoft.Network/networkInterfaces/agent-807jVFwj-nic"
+)
+
+func TestCreate(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://management.azure.com").
+		Put(mockVirtualMachine).
+		MatchParam("api-version", computeVersion).
+		Reply(201).
+		BodyString(respVirtualMachineCreating)
+
+	gock.New("https://management.azure.com").
+		Get(mockVirtualMachine).
+		MatchParam("api-version", computeVersion).
+		Reply(200).
+		BodyString(respVirtualMachineSucceeded)
+
+	gock.New("https://management.azure.com").
+		Get(mockPublicIPAddress).
+		MatchParam("api-version", networkVersion).
+		Reply(200).
+		BodyString(`{ "properties": { "ipAddress": "20.115.40.12" } }`)
+
+	p := New(
+		WithClient(http.DefaultClient),
+		WithSubscription("sub"),
+		WithResourceGroup("drone"),
+		WithSSHKey("ssh-rsa AAAAB3NzaC1yc2E"),
+	).(*provider)
+	p.init.Do(func() {}) // pre-initialize
+
+	instance, err := p.Create(context.TODO(), autoscaler.InstanceCreateOpts{Name: "agent-807jVFwj"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if !gock.IsDone() {
+		t.Errorf("Expected all http requests to be made")
+	}
+
+	t.Run("Attributes", testInstance(instance))
+
+	if got, want := instance.Address, "20.115.40.12"; got != want {
+		t.Errorf("Want instance Address %v, got %v", want, got)
+	}
+}
+
+func TestCreate_PrivateIP(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://management.azure.com").
+		Put(mockVirtualMachine).
+		Reply(201).
+		BodyString(respVirtualMachineCreating)
+
+	gock.New("https://management.azure.com").
+		Get(mockVirtualMachine).
+		Reply(200).
+		BodyString(respVirtualMachineSucceeded)
+
+	gock.New("https://management.azure.com").
+		Get(mockNetworkInterface).
+		MatchParam("api-version", networkVersion).
+		Reply(200).
+		BodyString(`{ "properties": { "ipConfigurations": [ { "properties": { "privateIPAddress": "10.0.0.4" } } ] } }`)
+
+	p := New(
+		WithClient(http.DefaultClient),
+		WithSubscription("sub"),
+		WithResourceGroup("drone"),
+		WithPrivateIP(true),
+	).(*provider)
+	p.init.Do(func() {}) // pre-initialize
+
+	instance, err := p.Create(context.TODO(), autoscaler.InstanceCreateOpts{Name: "agent-807jVFwj"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := instance.Address, "10.0.0.4"; got != want {
+		t.Errorf("Want instance Address %v, got %v", want, got)
+	}
+}
+
+func TestCreate_CreateError(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://management.azure.com").
+		Put(mockVirtualMachine).
+		Reply(409).
+		BodyString(`{ "error": { "code": "OperationNotAllowed", "message": "Operation could not be completed as it results in exceeding approved quota." } }`)
+
+	p := New(
+		WithClient(http.DefaultClient),
+		WithSubscription("sub"),
+		WithResourceGroup("drone"),
+	).(*provider)
+	p.init.Do(func() {}) // pre-initialize
+
+	instance, err := p.Create(context.TODO(), autoscaler.InstanceCreateOpts{Name: "agent-807jVFwj"})
+	if err == nil {
+		t.Errorf("Expect error returned from azure")
+		return
+	}
+	if instance != nil {
+		t.Errorf("Expect nil instance when create fails")
+	}
+	if aerr, ok := err.(*apiError); !ok || aerr.Code != "OperationNotAllowed" {
+		t.Errorf("Expect azure api error, got %v", err)
+	}
+}
+
+func TestCreate_ProvisioningFailed(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://management.azure.com").
+		Put(mockVirtualMachine).
+		Reply(201).
+		BodyString(respVirtualMachineCreating)
+
+	gock.New("https://management.azure.com").
+		Get(mockVirtualMachine).
+		Reply(200).
+		BodyString(`{ "id": "` + mockVirtualMachine + `", "properties": { "provisioningState": "Failed" } }`)
+
+	p := New(
+		WithClient(http.DefaultClient),
+		WithSubscription("sub"),
+		WithResourceGroup("drone"),
+	).(*provider)
+	p.init.Do(func() {}) // pre-initialize
+
+	instance, err := p.Create(context.TODO(), autoscaler.InstanceCreateOpts{Name: "agent-807jVFwj"})
+	if err == nil {
+		t.Errorf("Expect error returned from azure")
+	}
+	if instance == nil {
+		t.Errorf("Expect non-nil instance so that it can be destroyed")
+	}
+}
+
+func TestImageReference(t *testing.T) {
+	ref := imageReference("Canonical:0001-com-ubuntu-server-focal:20_04-lts-gen2:latest")
+	if got, want := ref["publisher"], "Canonical"; got != want {
+		t.Errorf("Want publisher %v, got %v", want, got)
+	}
+	if got, want := ref["offer"], "0001-com-ubuntu-server-focal"; got != want {
+		t.Errorf("Want offer %v, got %v", want, got)
+	}
+	if got, want := ref["sku"], "20_04-lts-gen2"; got != want {
+		t.Errorf("Want sku %v, got %v", want, got)
+	}
+	if got, want := ref["version"], "latest"; got != want {
+		t.Errorf("Want version %v, got %v", want, got)
+	}
+
+	id := "/subscriptions/sub/resourceGroups/drone/providers/Microsoft.Compute/images/agent"
+	ref = imageReference(id)
+	if got, want := ref["id"], id; got != want {
+		t.Errorf("Want image id %v, got %v", want, got)
+	}
+}
+
+func testInstance(instance *autoscaler.Instance) func(t *testing.T) {
+	return func(t *testing.T) {
+		if instance == nil {
+			t.Errorf("Expect non-nil instance even if error")
+			return
+		}
+		if got, want := instance.ID, mockVirtualMachine; got != want {
+			t.Errorf("Want instance ID %v, got %v", want, got)
+		}
+		if got, want := instance.Image, "Canonical:0001-com-ubuntu-server-focal:20_04-lts-gen2:latest"; got != want {
+			t.Errorf("Want instance Image %v, got %v", want, got)
+		}
+		if got, want := instance.Name, "agent-807jVFwj"; got != want {
+			t.Errorf("Want instance Name %v, got %v", want, got)
+		}
+		if got, want := instance.Region, "eastus"; got != want {
+			t.Errorf("Want instance Region %v, got %v", want, got)
+		}
+		if got, want := instance.Size, "Standard_D2s_v3"; got != want {
+			t.Errorf("Want instance Size %v, got %v", want, got)
+		}
+		if got, want := instance.Provider, autoscaler.ProviderAzure; got != want {
+			t.Errorf("Want instance Provider %v, got %v", want, got)
+		}
+	}
+}
+
+// sample response for PUT virtualMachines/{name}
+const respVirtualMachineCreating = `
+{
+  "name": "agent-807jVFwj",
+  "id": "/subscriptions/sub/resourceGroups/drone/providers/Microsoft.Compute/virtualMachines/agent-807jVFwj",
+  "type": "Microsoft.Compute/virtualMachines",
+  "location": "eastus",
+  "properties": {
+    "vmId": "5c0ec2e6-0b3a-4bd0-9c43-7a3d1d2ea6b8",
+    "hardwareProfile": {
+      "vmSize": "Standard_D2s_v3"
+    },
+    "provisioningState": "Creating"
+  }
+}
+`
+
+// sample response for GET virtualMachines/{name}
+const respVirtualMachineSucceeded = `
+{
+  "name": "agent-807jVFwj",
+  "id": "/subscriptions/sub/resourceGroups/drone/providers/Microsoft.Compute/virtualMachines/agent-807jVFwj",
+  "type": "Microsoft.Compute/virtualMachines",
+  "location": "eastus",
+  "properties": {
+    "vmId": "5c0ec2e6-0b3a-4bd0-9c43-7a3d1d2ea6b8",
+    "hardwareProfile": {
+      "vmSize": "Standard_D2s_v3"
+    },
+    "provisioningState": "Succeeded"
+  }
+}
+`
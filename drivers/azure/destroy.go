@@ -3,3 +3,39 @@
 // that can be found in the LICENSE file.
 
 package azure
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/logger"
+)
+
+func (p *provider) Destroy(ctx context.Context, instance *autoscaler.Instance) error {
+	logger := logger.FromContext(ctx).
+		WithField("id", instance.ID).
+		WithField("ip", instance.Address).
+		WithField("name", instance.Name).
+		WithField("location", instance.Region)
+
+	logger.Debugln("deleting instance")
+
+	// the instance id is the fully qualified resource id of
+	// the virtual machine. The network interface, public ip
+	// and os disk are deleted with the virtual machine.
+	err := p.do(ctx, http.MethodDelete, instance.ID, computeVersion, nil, nil)
+	if aerr, ok := err.(*apiError); ok && aerr.Status == http.StatusNotFound {
+		logger.Debugln("instance does not exist")
+		return autoscaler.ErrInstanceNotFound
+	}
+	if err != nil {
+		logger.WithError(err).
+			Errorln("deleting instance failed")
+		return err
+	}
+
+	logger.Debugln("instance deleted")
+
+	return nil
+}
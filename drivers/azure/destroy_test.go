@@ -3,3 +3,78 @@
 // that can be found in the LICENSE file.
 
 package azure
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/drone/autoscaler"
+
+	"github.com/h2non/gock"
+)
+
+func TestDestroy(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://management.azure.com").
+		Delete(mockVirtualMachine).
+		MatchParam("api-version", computeVersion).
+		Reply(202)
+
+	mockInstance := &autoscaler.Instance{
+		ID: mockVirtualMachine,
+	}
+
+	p := New(
+		WithClient(http.DefaultClient),
+	)
+	err := p.Destroy(context.TODO(), mockInstance)
+	if err != nil {
+		t.Error(err)
+	}
+	if !gock.IsDone() {
+		t.Errorf("Expected all http requests to be made")
+	}
+}
+
+func TestDestroyDeleteError(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://management.azure.com").
+		Delete(mockVirtualMachine).
+		Reply(500)
+
+	mockInstance := &autoscaler.Instance{
+		ID: mockVirtualMachine,
+	}
+
+	p := New(
+		WithClient(http.DefaultClient),
+	)
+	err := p.Destroy(context.TODO(), mockInstance)
+	if err == nil {
+		t.Errorf("Expect error returned from azure")
+	}
+}
+
+func TestDestroyNotFound(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://management.azure.com").
+		Delete(mockVirtualMachine).
+		Reply(404).
+		BodyString(`{ "error": { "code": "ResourceNotFound", "message": "The Resource was not found." } }`)
+
+	mockInstance := &autoscaler.Instance{
+		ID: mockVirtualMachine,
+	}
+
+	p := New(
+		WithClient(http.DefaultClient),
+	)
+	err := p.Destroy(context.TODO(), mockInstance)
+	if err != autoscaler.ErrInstanceNotFound {
+		t.Errorf("Expect instance not found returned from azure")
+	}
+}
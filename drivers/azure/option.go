@@ -3,3 +3,177 @@
 // that can be found in the LICENSE file.
 
 package azure
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/drone/autoscaler/drivers/internal/owner"
+	"github.com/drone/autoscaler/drivers/internal/userdata"
+)
+
+// Option configures an Azure provider option.
+type Option func(*provider)
+
+// WithClient returns an option to set the http client used
+// to call the Azure Resource Manager api.
+func WithClient(client *http.Client) Option {
+	return func(p *provider) {
+		p.client = client
+	}
+}
+
+// WithAuthority returns an option to set the Azure Active
+// Directory authority used to authenticate. If unset, the
+// authority is derived from the resource manager endpoint.
+func WithAuthority(authority string) Option {
+	return func(p *provider) {
+		p.authority = authority
+	}
+}
+
+// WithCredentials returns an option to authenticate with
+// a service principal using client credentials.
+func WithCredentials(tenant, id, secret string) Option {
+	return func(p *provider) {
+		p.tenant = tenant
+		p.clientID = id
+		p.clientSecret = secret
+	}
+}
+
+// WithDiskSize returns an option to set the os disk size
+// in gigabytes.
+func WithDiskSize(size int64) Option {
+	return func(p *provider) {
+		p.diskSize = size
+	}
+}
+
+// WithDiskType returns an option to set the os disk
+// storage account type.
+func WithDiskType(diskType string) Option {
+	return func(p *provider) {
+		p.diskType = diskType
+	}
+}
+
+// WithEndpoint returns an option to set the Azure Resource
+// Manager endpoint (e.g. for sovereign clouds).
+func WithEndpoint(endpoint string) Option {
+	return func(p *provider) {
+		p.endpoint = endpoint
+	}
+}
+
+// WithImage returns an option to set the image in the
+// publisher:offer:sku:version format.
+func WithImage(image string) Option {
+	return func(p *provider) {
+		p.image = image
+	}
+}
+
+// WithLocation returns an option to set the location.
+func WithLocation(location string) Option {
+	return func(p *provider) {
+		p.location = location
+	}
+}
+
+// WithPrivateIP returns an option to set the private IP address.
+func WithPrivateIP(private bool) Option {
+	return func(p *provider) {
+		p.privateIP = private
+	}
+}
+
+// WithResourceGroup returns an option to set the resource group.
+func WithResourceGroup(group string) Option {
+	return func(p *provider) {
+		p.resourceGroup = group
+	}
+}
+
+// WithSecurityGroup returns an option to set the network
+// security group resource id.
+func WithSecurityGroup(group string) Option {
+	return func(p *provider) {
+		p.securityGroup = group
+	}
+}
+
+// WithSize returns an option to set the virtual machine size.
+func WithSize(size string) Option {
+	return func(p *provider) {
+		p.size = size
+	}
+}
+
+// WithSSHKey returns an option to set the ssh public key.
+func WithSSHKey(key string) Option {
+	return func(p *provider) {
+		p.key = key
+	}
+}
+
+// WithSubnet returns an option to set the subnet resource id.
+func WithSubnet(subnet string) Option {
+	return func(p *provider) {
+		p.subnet = subnet
+	}
+}
+
+// WithSubscription returns an option to set the subscription id.
+func WithSubscription(subscription string) Option {
+	return func(p *provider) {
+		p.subscription = subscription
+	}
+}
+
+// WithTags returns an option to set the resource tags.
+func WithTags(tags map[string]string) Option {
+	return func(p *provider) {
+		p.tags = tags
+	}
+}
+
+// WithUsername returns an option to set the admin username.
+func WithUsername(username string) Option {
+	return func(p *provider) {
+		p.username = username
+	}
+}
+
+// WithUserData returns an option to set the cloud-init
+// template from text.
+func WithUserData(text string) Option {
+	return func(p *provider) {
+		if text != "" {
+			p.userdata = userdata.Parse(text)
+		}
+	}
+}
+
+// WithUserDataFile returns an option to set the cloud-init
+// template from file.
+func WithUserDataFile(filepath string) Option {
+	return func(p *provider) {
+		if filepath != "" {
+			b, err := ioutil.ReadFile(filepath)
+			if err != nil {
+				panic(err)
+			}
+			p.userdata = userdata.Parse(string(b))
+		}
+	}
+}
+
+// WithOwner returns an option to set the autoscaler identifier
+// and pool name added to the ownership metadata of every
+// instance.
+func WithOwner(id, pool string) Option {
+	return func(p *provider) {
+		p.owner = owner.Owner{ID: id, Pool: pool}
+	}
+}
@@ -3,3 +3,70 @@
 // that can be found in the LICENSE file.
 
 package azure
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOptions(t *testing.T) {
+	p := New(
+		WithDiskSize(100),
+		WithDiskType("Premium_LRS"),
+		WithEndpoint("https://management.usgovcloudapi.net"),
+		WithImage("Canonical:UbuntuServer:18.04-LTS:latest"),
+		WithLocation("westeurope"),
+		WithPrivateIP(true),
+		WithResourceGroup("drone"),
+		WithSecurityGroup("/subscriptions/sub/resourceGroups/drone/providers/Microsoft.Network/networkSecurityGroups/agents"),
+		WithSize("Standard_D4s_v3"),
+		WithSSHKey("ssh-rsa AAAAB3NzaC1yc2E"),
+		WithSubnet("/subscriptions/sub/resourceGroups/drone/providers/Microsoft.Network/virtualNetworks/drone/subnets/default"),
+		WithSubscription("sub"),
+		WithTags(map[string]string{"env": "prod"}),
+		WithUsername("ubuntu"),
+	).(*provider)
+
+	if got, want := p.diskSize, int64(100); got != want {
+		t.Errorf("Want disk size %d, got %d", want, got)
+	}
+	if got, want := p.diskType, "Premium_LRS"; got != want {
+		t.Errorf("Want disk type %q, got %q", want, got)
+	}
+	if got, want := p.endpoint, "https://management.usgovcloudapi.net"; got != want {
+		t.Errorf("Want endpoint %q, got %q", want, got)
+	}
+	if got, want := p.image, "Canonical:UbuntuServer:18.04-LTS:latest"; got != want {
+		t.Errorf("Want image %q, got %q", want, got)
+	}
+	if got, want := p.location, "westeurope"; got != want {
+		t.Errorf("Want location %q, got %q", want, got)
+	}
+	if got, want := p.privateIP, true; got != want {
+		t.Errorf("Want private ip %v, got %v", want, got)
+	}
+	if got, want := p.resourceGroup, "drone"; got != want {
+		t.Errorf("Want resource group %q, got %q", want, got)
+	}
+	if got, want := p.securityGroup, "/subscriptions/sub/resourceGroups/drone/providers/Microsoft.Network/networkSecurityGroups/agents"; got != want {
+		t.Errorf("Want security group %q, got %q", want, got)
+	}
+	if got, want := p.size, "Standard_D4s_v3"; got != want {
+		t.Errorf("Want size %q, got %q", want, got)
+	}
+	if got, want := p.key, "ssh-rsa AAAAB3NzaC1yc2E"; got != want {
+		t.Errorf("Want key %q, got %q", want, got)
+	}
+	if got, want := p.subnet, "/subscriptions/sub/resourceGroups/drone/providers/Microsoft.Network/virtualNetworks/drone/subnets/default"; got != want {
+		t.Errorf("Want subnet %q, got %q", want, got)
+	}
+	if got, want := p.subscription, "sub"; got != want {
+		t.Errorf("Want subscription %q, got %q", want, got)
+	}
+	if got, want := p.tags, map[string]string{"env": "prod"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Want tags %v, got %v", want, got)
+	}
+	if got, want := p.username, "ubuntu"; got != want {
+		t.Errorf("Want username %q, got %q", want, got)
+	}
+}
@@ -3,3 +3,208 @@
 // that can be found in the LICENSE file.
 
 package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/drivers/internal/owner"
+	"github.com/drone/autoscaler/drivers/internal/userdata"
+
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const (
+	// default azure resource manager endpoint.
+	defaultEndpoint = "https://management.azure.com"
+
+	// default azure active directory authority.
+	defaultAuthority = "https://login.microsoftonline.com"
+
+	// api versions used when calling the compute and
+	// network resource providers.
+	computeVersion = "2023-03-01"
+	networkVersion = "2022-07-01"
+)
+
+// provider implements an Azure provider.
+type provider struct {
+	init sync.Once
+
+	endpoint      string
+	subscription  string
+	resourceGroup string
+	location      string
+	size          string
+	image         string
+	subnet        string
+	securityGroup string
+	privateIP     bool
+	username      string
+	key           string
+	diskSize      int64
+	diskType      string
+	tags          map[string]string
+	owner         owner.Owner
+	userdata      *template.Template
+
+	// client credentials used to authenticate with the
+	// resource manager, if the http client is not set.
+	authority    string
+	tenant       string
+	clientID     string
+	clientSecret string
+
+	client *http.Client
+}
+
+// authorities maps the resource manager endpoint of each
+// national cloud to the active directory authority.
+var authorities = map[string]string{
+	"https://management.azure.com":         "https://login.microsoftonline.com",
+	"https://management.chinacloudapi.cn":  "https://login.chinacloudapi.cn",
+	"https://management.usgovcloudapi.net": "https://login.microsoftonline.us",
+	"https://management.microsoftazure.de": "https://login.microsoftonline.de",
+}
+
+// New returns a new Azure provider.
+func New(opts ...Option) autoscaler.Provider {
+	p := new(provider)
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.endpoint == "" {
+		p.endpoint = defaultEndpoint
+	}
+	if p.location == "" {
+		p.location = "eastus"
+	}
+	if p.size == "" {
+		p.size = "Standard_D2s_v3"
+	}
+	if p.image == "" {
+		p.image = "Canonical:0001-com-ubuntu-server-focal:20_04-lts-gen2:latest"
+	}
+	if p.username == "" {
+		p.username = "drone"
+	}
+	if p.diskSize == 0 {
+		p.diskSize = 50
+	}
+	if p.diskType == "" {
+		p.diskType = "StandardSSD_LRS"
+	}
+	if p.userdata == nil {
+		p.userdata = userdata.T
+	}
+	p.endpoint = strings.TrimSuffix(p.endpoint, "/")
+	if p.authority == "" {
+		p.authority = authorities[p.endpoint]
+	}
+	if p.authority == "" {
+		p.authority = defaultAuthority
+	}
+	if p.client == nil && p.clientID != "" {
+		p.client = p.credentials().Client(context.Background())
+	}
+	if p.client == nil {
+		p.client = http.DefaultClient
+	}
+	return p
+}
+
+// helper function returns the service principal client
+// credentials configuration. The token is requested from
+// the authority of the cloud, for the resource manager
+// endpoint of the cloud.
+func (p *provider) credentials() *clientcredentials.Config {
+	return &clientcredentials.Config{
+		ClientID:     p.clientID,
+		ClientSecret: p.clientSecret,
+		TokenURL:     strings.TrimSuffix(p.authority, "/") + "/" + p.tenant + "/oauth2/v2.0/token",
+		Scopes:       []string{p.endpoint + "/.default"},
+	}
+}
+
+// helper function returns the fully qualified path of a
+// resource in the configured resource group.
+func (p *provider) resourcePath(kind, name string) string {
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/%s/%s",
+		p.subscription,
+		p.resourceGroup,
+		kind,
+		name,
+	)
+}
+
+// apiError is returned when the azure resource manager
+// returns a non-2xx status code.
+type apiError struct {
+	Status  int    `json:"-"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (e *apiError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("azure: server responded with status code %d", e.Status)
+	}
+	return fmt.Sprintf("azure: %s: %s", e.Code, e.Message)
+}
+
+// helper function sends a request to the azure resource
+// manager and decodes the json response into out.
+func (p *provider) do(ctx context.Context, method, path, version string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		buf := new(bytes.Buffer)
+		if err := json.NewEncoder(buf).Encode(in); err != nil {
+			return err
+		}
+		body = buf
+	}
+
+	uri := strings.TrimSuffix(p.endpoint, "/") + path + "?api-version=" + version
+	req, err := http.NewRequestWithContext(ctx, method, uri, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode > 299 {
+		// the resource manager wraps error details in an
+		// error object. Decoding is best effort since the
+		// status code is sufficient to classify the error.
+		wrapper := struct {
+			Error *apiError `json:"error"`
+		}{Error: new(apiError)}
+		json.NewDecoder(res.Body).Decode(&wrapper)
+		if wrapper.Error == nil {
+			wrapper.Error = new(apiError)
+		}
+		wrapper.Error.Status = res.StatusCode
+		return wrapper.Error
+	}
+
+	if out == nil || res.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
@@ -3,3 +3,81 @@
 // that can be found in the LICENSE file.
 
 package azure
+
+import (
+	"testing"
+
+	"github.com/drone/autoscaler/drivers/internal/userdata"
+)
+
+func TestDefaults(t *testing.T) {
+	p := New().(*provider)
+	if got, want := p.endpoint, "https://management.azure.com"; got != want {
+		t.Errorf("Want endpoint %q, got %q", want, got)
+	}
+	if got, want := p.location, "eastus"; got != want {
+		t.Errorf("Want location %q, got %q", want, got)
+	}
+	if got, want := p.size, "Standard_D2s_v3"; got != want {
+		t.Errorf("Want size %q, got %q", want, got)
+	}
+	if got, want := p.image, "Canonical:0001-com-ubuntu-server-focal:20_04-lts-gen2:latest"; got != want {
+		t.Errorf("Want image %q, got %q", want, got)
+	}
+	if got, want := p.username, "drone"; got != want {
+		t.Errorf("Want username %q, got %q", want, got)
+	}
+	if got, want := p.diskSize, int64(50); got != want {
+		t.Errorf("Want disk size %d, got %d", want, got)
+	}
+	if got, want := p.diskType, "StandardSSD_LRS"; got != want {
+		t.Errorf("Want disk type %q, got %q", want, got)
+	}
+	if p.userdata != userdata.T {
+		t.Errorf("Want default userdata template")
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	p := New(
+		WithCredentials("my-tenant", "my-client", "my-secret"),
+	).(*provider)
+	config := p.credentials()
+	if got, want := config.TokenURL, "https://login.microsoftonline.com/my-tenant/oauth2/v2.0/token"; got != want {
+		t.Errorf("Want token url %q, got %q", want, got)
+	}
+	if got, want := config.Scopes[0], "https://management.azure.com/.default"; got != want {
+		t.Errorf("Want scope %q, got %q", want, got)
+	}
+}
+
+// This test verifies the authority and scope are derived
+// from the resource manager endpoint of a national cloud.
+func TestCredentials_Endpoint(t *testing.T) {
+	p := New(
+		WithCredentials("my-tenant", "my-client", "my-secret"),
+		WithEndpoint("https://management.chinacloudapi.cn/"),
+	).(*provider)
+	config := p.credentials()
+	if got, want := config.TokenURL, "https://login.chinacloudapi.cn/my-tenant/oauth2/v2.0/token"; got != want {
+		t.Errorf("Want token url %q, got %q", want, got)
+	}
+	if got, want := config.Scopes[0], "https://management.chinacloudapi.cn/.default"; got != want {
+		t.Errorf("Want scope %q, got %q", want, got)
+	}
+}
+
+func TestCredentials_Authority(t *testing.T) {
+	p := New(
+		WithCredentials("my-tenant", "my-client", "my-secret"),
+		WithEndpoint("https://management.azurestack.local"),
+		WithAuthority("https://login.azurestack.local/"),
+	).(*provider)
+	config := p.credentials()
+	if got, want := config.TokenURL, "https://login.azurestack.local/my-tenant/oauth2/v2.0/token"; got != want {
+		t.Errorf("Want token url %q, got %q", want, got)
+	}
+	if got, want := config.Scopes[0], "https://management.azurestack.local/.default"; got != want {
+		t.Errorf("Want scope %q, got %q", want, got)
+	}
+}
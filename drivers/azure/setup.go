@@ -0,0 +1,82 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/drone/autoscaler/logger"
+)
+
+type sshPublicKey struct {
+	Name       string `json:"name"`
+	Properties struct {
+		PublicKey string `json:"publicKey"`
+	} `json:"properties"`
+}
+
+func (p *provider) setup(ctx context.Context) error {
+	if p.key == "" {
+		return p.setupKeypair(ctx)
+	}
+	return nil
+}
+
+func (p *provider) setupKeypair(ctx context.Context) error {
+	logger := logger.FromContext(ctx)
+
+	logger.Debugln("finding default ssh key")
+
+	path := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/sshPublicKeys",
+		p.subscription,
+		p.resourceGroup,
+	)
+	keys := struct {
+		Value []*sshPublicKey `json:"value"`
+	}{}
+	err := p.do(ctx, http.MethodGet, path, computeVersion, nil, &keys)
+	if err != nil {
+		return err
+	}
+
+	index := map[string]*sshPublicKey{}
+	for _, key := range keys.Value {
+		index[key.Name] = key
+	}
+
+	// if the resource group has multiple keys configured we
+	// will attempt to use an existing key based on naming
+	// convention.
+	for _, name := range []string{"drone", "id_rsa_drone"} {
+		key, ok := index[name]
+		if !ok {
+			continue
+		}
+		p.key = key.Properties.PublicKey
+
+		logger.
+			WithField("name", name).
+			Debugln("using default ssh key")
+		return nil
+	}
+
+	// if there were no matches but the resource group has at
+	// least one key already created we will select the first
+	// in the list.
+	if len(keys.Value) > 0 {
+		key := keys.Value[0]
+		p.key = key.Properties.PublicKey
+
+		logger.
+			WithField("name", key.Name).
+			Debugln("using default ssh key")
+		return nil
+	}
+
+	return errors.New("No matching keys")
+}
@@ -0,0 +1,113 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package azure
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/h2non/gock"
+)
+
+func TestSetupKey_ChooseFirst(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://management.azure.com").
+		Get("/subscriptions/sub/resourceGroups/drone/providers/Microsoft.Compute/sshPublicKeys").
+		Reply(200).
+		BodyString(respSingleKey)
+
+	p := New(
+		WithClient(http.DefaultClient),
+		WithSubscription("sub"),
+		WithResourceGroup("drone"),
+	).(*provider)
+
+	err := p.setup(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+
+	if got, want := p.key, "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQC1"; got != want {
+		t.Errorf("Want key %q, got %q", want, got)
+	}
+}
+
+func TestSetupKey_ChooseMatch(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://management.azure.com").
+		Get("/subscriptions/sub/resourceGroups/drone/providers/Microsoft.Compute/sshPublicKeys").
+		Reply(200).
+		BodyString(respMultiKey)
+
+	p := New(
+		WithClient(http.DefaultClient),
+		WithSubscription("sub"),
+		WithResourceGroup("drone"),
+	).(*provider)
+
+	err := p.setup(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+
+	if got, want := p.key, "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQC2"; got != want {
+		t.Errorf("Want key %q, got %q", want, got)
+	}
+}
+
+func TestSetupKey_NoKeys(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://management.azure.com").
+		Get("/subscriptions/sub/resourceGroups/drone/providers/Microsoft.Compute/sshPublicKeys").
+		Reply(200).
+		BodyString(`{ "value": [] }`)
+
+	p := New(
+		WithClient(http.DefaultClient),
+		WithSubscription("sub"),
+		WithResourceGroup("drone"),
+	).(*provider)
+
+	err := p.setup(context.TODO())
+	if err == nil {
+		t.Errorf("Expect error when no keys exist")
+	}
+}
+
+const respSingleKey = `
+{
+  "value": [
+    {
+      "name": "my-key",
+      "properties": {
+        "publicKey": "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQC1"
+      }
+    }
+  ]
+}
+`
+
+const respMultiKey = `
+{
+  "value": [
+    {
+      "name": "my-key",
+      "properties": {
+        "publicKey": "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQC1"
+      }
+    },
+    {
+      "name": "drone",
+      "properties": {
+        "publicKey": "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQC2"
+      }
+    }
+  ]
+}
+`
@@ -31,7 +31,7 @@ func (p *provider) Create(ctx context.Context, opts autoscaler.InstanceCreateOpt
 		Name:              opts.Name,
 		Region:            p.region,
 		Size:              p.size,
-		Tags:              p.tags,
+		Tags:              append(p.owner.Tags(opts.Name), p.tags...),
 		IPv6:              false,
 		PrivateNetworking: p.privateIP,
 		UserData:          buf.String(),
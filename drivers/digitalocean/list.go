@@ -0,0 +1,92 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package digitalocean
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/drivers/internal/owner"
+
+	"github.com/digitalocean/godo"
+)
+
+// errNoTags is returned when listing droplets without an
+// owner or any configured tags, since the droplets that
+// belong to the autoscaler cannot be distinguished from
+// other droplets.
+var errNoTags = errors.New("digitalocean: cannot list droplets without tags")
+
+// List returns the droplets that have all of the ownership
+// tags, or all of the configured tags if the owner is unknown.
+func (p *provider) List(ctx context.Context) ([]*autoscaler.Instance, error) {
+	tags := owner.Tags(p.owner.Selector())
+	if len(tags) == 0 {
+		tags = p.tags
+	}
+	if len(tags) == 0 {
+		return nil, errNoTags
+	}
+
+	client := newClient(ctx, p.token)
+	opt := &godo.ListOptions{PerPage: 200}
+
+	var instances []*autoscaler.Instance
+	for {
+		droplets, res, err := client.Droplets.ListByTag(ctx, tags[0], opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, droplet := range droplets {
+			if hasTags(droplet.Tags, tags) {
+				instances = append(instances, p.convert(droplet))
+			}
+		}
+		if res.Links == nil || res.Links.IsLastPage() {
+			break
+		}
+		page, err := res.Links.CurrentPage()
+		if err != nil {
+			return nil, err
+		}
+		opt.Page = page + 1
+	}
+	return instances, nil
+}
+
+// helper function converts the droplet to an instance.
+func (p *provider) convert(droplet godo.Droplet) *autoscaler.Instance {
+	instance := &autoscaler.Instance{
+		Provider: autoscaler.ProviderDigitalOcean,
+		ID:       strconv.Itoa(droplet.ID),
+		Name:     droplet.Name,
+		Size:     droplet.SizeSlug,
+	}
+	if droplet.Region != nil {
+		instance.Region = droplet.Region.Slug
+	}
+	if droplet.Image != nil {
+		instance.Image = droplet.Image.Slug
+	}
+	instance.Address, _ = droplet.PublicIPv4()
+	return instance
+}
+
+// helper function returns true if the droplet tags include
+// all of the required tags.
+func hasTags(tags, required []string) bool {
+	set := map[string]struct{}{}
+	for _, tag := range tags {
+		set[tag] = struct{}{}
+	}
+	for _, tag := range required {
+		if _, ok := set[tag]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package digitalocean
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drone/autoscaler"
+
+	"github.com/h2non/gock"
+)
+
+func TestList(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.digitalocean.com").
+		Get("/v2/droplets").
+		MatchParam("tag_name", "drone").
+		Reply(200).
+		BodyString(respDropletList)
+
+	p := New(
+		WithToken("77e027c7447f468068a7d4fea41e7149a75a94088082c66fcf555de3977f69d3"),
+		WithTags("drone", "ci"),
+	)
+
+	instances, err := p.(autoscaler.Lister).List(context.TODO())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !gock.IsDone() {
+		t.Errorf("Expected http requests not detected")
+	}
+	if got, want := len(instances), 1; got != want {
+		t.Errorf("Want %d instances, got %d", want, got)
+		return
+	}
+
+	instance := instances[0]
+	if got, want := instance.ID, "3164494"; got != want {
+		t.Errorf("Want droplet id %s, got %s", want, got)
+	}
+	if got, want := instance.Name, "agent-1"; got != want {
+		t.Errorf("Want droplet name %s, got %s", want, got)
+	}
+	if got, want := instance.Region, "nyc1"; got != want {
+		t.Errorf("Want droplet region %s, got %s", want, got)
+	}
+	if got, want := instance.Address, "104.131.186.241"; got != want {
+		t.Errorf("Want droplet address %s, got %s", want, got)
+	}
+}
+
+func TestList_Owner(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.digitalocean.com").
+		Get("/v2/droplets").
+		MatchParam("tag_name", "drone-autoscaler-pool:amd64").
+		Reply(200).
+		BodyString(respDropletListOwner)
+
+	p := New(
+		WithToken("77e027c7447f468068a7d4fea41e7149a75a94088082c66fcf555de3977f69d3"),
+		WithTags("drone"),
+		WithOwner("drone", "amd64"),
+	)
+
+	instances, err := p.(autoscaler.Lister).List(context.TODO())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !gock.IsDone() {
+		t.Errorf("Expected http requests not detected")
+	}
+	if got, want := len(instances), 1; got != want {
+		t.Errorf("Want %d instances, got %d", want, got)
+		return
+	}
+	if got, want := instances[0].Name, "agent-2"; got != want {
+		t.Errorf("Want droplet name %s, got %s", want, got)
+	}
+}
+
+func TestList_NoTags(t *testing.T) {
+	p := New(
+		WithToken("77e027c7447f468068a7d4fea41e7149a75a94088082c66fcf555de3977f69d3"),
+	)
+	_, err := p.(autoscaler.Lister).List(context.TODO())
+	if err != errNoTags {
+		t.Errorf("Want error listing droplets without tags")
+	}
+}
+
+// sample response for GET /v2/droplets?tag_name=drone
+const respDropletList = `
+{
+  "droplets": [
+    {
+      "id": 3164494,
+      "name": "agent-1",
+      "size_slug": "s-1vcpu-1gb",
+      "region": { "slug": "nyc1" },
+      "image": { "slug": "docker-18-04" },
+      "networks": {
+        "v4": [
+          { "ip_address": "104.131.186.241", "type": "public" }
+        ]
+      },
+      "tags": [ "drone", "ci" ]
+    },
+    {
+      "id": 3164495,
+      "name": "web-1",
+      "tags": [ "drone" ]
+    }
+  ],
+  "links": {},
+  "meta": { "total": 2 }
+}
+`
+
+// sample response for GET /v2/droplets?tag_name=drone-autoscaler-pool:amd64
+const respDropletListOwner = `
+{
+  "droplets": [
+    {
+      "id": 3164496,
+      "name": "agent-2",
+      "tags": [ "drone-autoscaler-pool:amd64", "drone-autoscaler:drone" ]
+    },
+    {
+      "id": 3164497,
+      "name": "agent-3",
+      "tags": [ "drone-autoscaler-pool:amd64", "drone-autoscaler:staging" ]
+    }
+  ],
+  "links": {},
+  "meta": { "total": 2 }
+}
+`
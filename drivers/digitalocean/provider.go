@@ -10,6 +10,7 @@ import (
 	"text/template"
 
 	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/drivers/internal/owner"
 
 	"github.com/digitalocean/godo"
 	"golang.org/x/oauth2"
@@ -28,6 +29,7 @@ type provider struct {
 	privateIP bool
 	userdata  *template.Template
 	tags      []string
+	owner     owner.Owner
 }
 
 // New returns a new Digital Ocean provider.
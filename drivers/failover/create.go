@@ -0,0 +1,44 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/logger"
+)
+
+func (p *provider) Create(ctx context.Context, opts autoscaler.InstanceCreateOpts) (*autoscaler.Instance, error) {
+	logger := logger.FromContext(ctx).
+		WithField("name", opts.Name)
+
+	var errs []error
+	for _, target := range p.order() {
+		instance, err := target.Provider.Create(ctx, opts)
+		if instance != nil {
+			target.tag(instance)
+		}
+
+		// if the instance was provisioned (with or without
+		// errors), return the instance. Creating a second
+		// instance would leak the first instance.
+		if err == nil || instance != nil {
+			return instance, err
+		}
+
+		logger.WithError(err).
+			WithField("provider", target.Type).
+			Warnln("cannot create instance, trying next provider")
+
+		errs = append(errs, fmt.Errorf("%s: %w", target.Type, err))
+	}
+	if len(errs) == 0 {
+		return nil, errors.New("failover: no providers configured")
+	}
+	return nil, fmt.Errorf("failover: cannot create instance: %w", errors.Join(errs...))
+}
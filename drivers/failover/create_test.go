@@ -0,0 +1,139 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package failover
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/mocks"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestCreate(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	opts := autoscaler.InstanceCreateOpts{Name: "agent-1"}
+
+	primary := mocks.NewMockProvider(controller)
+	primary.EXPECT().Create(gomock.Any(), opts).Return(nil, errors.New("out of capacity"))
+
+	secondary := mocks.NewMockProvider(controller)
+	secondary.EXPECT().Create(gomock.Any(), opts).Return(&autoscaler.Instance{ID: "1"}, nil)
+
+	p := New(
+		Target{Type: autoscaler.ProviderDigitalOcean, Provider: primary},
+		Target{Type: autoscaler.ProviderHetznerCloud, Provider: secondary},
+	)
+
+	instance, err := p.Create(context.TODO(), opts)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := instance.Provider, autoscaler.ProviderHetznerCloud; got != want {
+		t.Errorf("Want instance provider %s, got %s", want, got)
+	}
+}
+
+// This test verifies that the target name is recorded in the
+// instance metadata.
+func TestCreate_Target(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	opts := autoscaler.InstanceCreateOpts{Name: "agent-1"}
+
+	primary := mocks.NewMockProvider(controller)
+	primary.EXPECT().Create(gomock.Any(), opts).Return(&autoscaler.Instance{ID: "i-1"}, nil)
+
+	p := New(
+		Target{Name: "amazon:us-west-2", Type: autoscaler.ProviderAmazon, Provider: primary},
+	)
+
+	instance, err := p.Create(context.TODO(), opts)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := instance.Metadata["failover"], "amazon:us-west-2"; got != want {
+		t.Errorf("Want instance target %s, got %s", want, got)
+	}
+}
+
+func TestCreate_Error(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	primary := mocks.NewMockProvider(controller)
+	primary.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil, errors.New("out of capacity"))
+
+	secondary := mocks.NewMockProvider(controller)
+	secondary.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil, errors.New("outage"))
+
+	p := New(
+		Target{Type: autoscaler.ProviderDigitalOcean, Provider: primary},
+		Target{Type: autoscaler.ProviderHetznerCloud, Provider: secondary},
+	)
+
+	_, err := p.Create(context.TODO(), autoscaler.InstanceCreateOpts{})
+	if err == nil {
+		t.Errorf("Want error when all providers fail")
+	}
+}
+
+// This test verifies that the next provider is not used
+// when the instance was provisioned with errors.
+func TestCreate_PartialError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	instance := &autoscaler.Instance{ID: "1", Provider: autoscaler.ProviderAmazon}
+
+	primary := mocks.NewMockProvider(controller)
+	primary.EXPECT().Create(gomock.Any(), gomock.Any()).Return(instance, context.DeadlineExceeded)
+
+	p := New(
+		Target{Type: autoscaler.ProviderAmazon, Provider: primary},
+		Target{Type: autoscaler.ProviderHetznerCloud, Provider: mocks.NewMockProvider(controller)},
+	)
+
+	got, err := p.Create(context.TODO(), autoscaler.InstanceCreateOpts{})
+	if err != context.DeadlineExceeded {
+		t.Errorf("Want provisioning error returned")
+	}
+	if got != instance {
+		t.Errorf("Want provisioned instance returned")
+	}
+}
+
+func TestOrder(t *testing.T) {
+	p := &provider{
+		targets: []Target{
+			{Type: autoscaler.ProviderDigitalOcean},
+			{Type: autoscaler.ProviderHetznerCloud},
+		},
+	}
+	ordered := p.order()
+	if got, want := ordered[0].Type, autoscaler.ProviderDigitalOcean; got != want {
+		t.Errorf("Want providers in order, got %s first", got)
+	}
+
+	// targets without weight are always used last.
+	p.targets[1].Weight = 1
+	for i := 0; i < 10; i++ {
+		ordered = p.order()
+		if got, want := ordered[0].Type, autoscaler.ProviderHetznerCloud; got != want {
+			t.Errorf("Want weighted provider first, got %s", got)
+		}
+		if got, want := len(ordered), 2; got != want {
+			t.Errorf("Want %d providers, got %d", want, got)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package failover
+
+import (
+	"context"
+
+	"github.com/drone/autoscaler"
+)
+
+func (p *provider) Destroy(ctx context.Context, instance *autoscaler.Instance) error {
+	target, err := p.lookup(instance)
+	if err != nil {
+		return err
+	}
+	return target.Provider.Destroy(ctx, instance)
+}
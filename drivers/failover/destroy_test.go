@@ -0,0 +1,80 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package failover
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/mocks"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestDestroy(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	instance := &autoscaler.Instance{ID: "1", Provider: autoscaler.ProviderHetznerCloud}
+
+	primary := mocks.NewMockProvider(controller)
+
+	secondary := mocks.NewMockProvider(controller)
+	secondary.EXPECT().Destroy(gomock.Any(), instance).Return(nil)
+
+	p := New(
+		Target{Type: autoscaler.ProviderDigitalOcean, Provider: primary},
+		Target{Type: autoscaler.ProviderHetznerCloud, Provider: secondary},
+	)
+
+	err := p.Destroy(context.TODO(), instance)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDestroy_UnknownProvider(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	p := New(
+		Target{Type: autoscaler.ProviderDigitalOcean, Provider: mocks.NewMockProvider(controller)},
+	)
+
+	err := p.Destroy(context.TODO(), &autoscaler.Instance{Provider: autoscaler.ProviderAmazon})
+	if err == nil {
+		t.Errorf("Want error routing instance to unknown provider")
+	}
+}
+
+// This test verifies that instances are routed to the target
+// that created the instance, when the same provider type is
+// configured for multiple regions.
+func TestDestroy_Region(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	instance := &autoscaler.Instance{
+		ID:       "i-1",
+		Provider: autoscaler.ProviderAmazon,
+		Metadata: map[string]string{"failover": "amazon:us-west-2"},
+	}
+
+	primary := mocks.NewMockProvider(controller)
+
+	secondary := mocks.NewMockProvider(controller)
+	secondary.EXPECT().Destroy(gomock.Any(), instance).Return(nil)
+
+	p := New(
+		Target{Name: "amazon:us-east-1", Type: autoscaler.ProviderAmazon, Provider: primary},
+		Target{Name: "amazon:us-west-2", Type: autoscaler.ProviderAmazon, Provider: secondary},
+	)
+
+	err := p.Destroy(context.TODO(), instance)
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package failover
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/drone/autoscaler"
+)
+
+// hibernator wraps the failover provider and implements the
+// Hibernator interface, if at least one provider is capable
+// of hibernation.
+type hibernator struct {
+	autoscaler.Provider
+	failover *provider
+}
+
+// Unwrap returns the wrapped Provider.
+func (h *hibernator) Unwrap() autoscaler.Provider {
+	return h.Provider
+}
+
+func (h *hibernator) Start(ctx context.Context, instance *autoscaler.Instance) (*autoscaler.Instance, error) {
+	target, err := h.failover.lookup(instance)
+	if err != nil {
+		return nil, err
+	}
+	hibernator, ok := autoscaler.AsHibernator(target.Provider)
+	if !ok {
+		return nil, fmt.Errorf("failover: %s does not support hibernation", target.Type)
+	}
+	return hibernator.Start(ctx, instance)
+}
+
+func (h *hibernator) Stop(ctx context.Context, instance *autoscaler.Instance) error {
+	target, err := h.failover.lookup(instance)
+	if err != nil {
+		return err
+	}
+	hibernator, ok := autoscaler.AsHibernator(target.Provider)
+	if !ok {
+		return fmt.Errorf("failover: %s does not support hibernation", target.Type)
+	}
+	return hibernator.Stop(ctx, instance)
+}
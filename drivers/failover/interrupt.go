@@ -0,0 +1,54 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package failover
+
+import (
+	"context"
+
+	"github.com/drone/autoscaler"
+)
+
+// interrupter wraps the failover provider and implements the
+// Interrupter interface, if at least one provider is capable
+// of detecting interruptions.
+type interrupter struct {
+	autoscaler.Provider
+	failover *provider
+}
+
+// Unwrap returns the wrapped Provider.
+func (i *interrupter) Unwrap() autoscaler.Provider {
+	return i.Provider
+}
+
+// Interrupted returns the interrupted instances for each
+// provider that is capable of detecting interruptions.
+func (i *interrupter) Interrupted(ctx context.Context, instances []*autoscaler.Instance) ([]*autoscaler.Instance, error) {
+	groups := map[autoscaler.ProviderType][]*autoscaler.Instance{}
+	for _, instance := range instances {
+		target, err := i.failover.lookup(instance)
+		if err != nil {
+			continue
+		}
+		groups[target.Type] = append(groups[target.Type], instance)
+	}
+
+	var interrupted []*autoscaler.Instance
+	for _, target := range i.failover.targets {
+		if len(groups[target.Type]) == 0 {
+			continue
+		}
+		interrupter, ok := autoscaler.AsInterrupter(target.Provider)
+		if !ok {
+			continue
+		}
+		result, err := interrupter.Interrupted(ctx, groups[target.Type])
+		if err != nil {
+			return interrupted, err
+		}
+		interrupted = append(interrupted, result...)
+	}
+	return interrupted, nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package failover
+
+import (
+	"context"
+
+	"github.com/drone/autoscaler"
+)
+
+// lister wraps the failover provider and implements the
+// Lister interface, if at least one provider is capable of
+// listing instances.
+type lister struct {
+	autoscaler.Provider
+	failover *provider
+}
+
+// Unwrap returns the wrapped Provider.
+func (l *lister) Unwrap() autoscaler.Provider {
+	return l.Provider
+}
+
+// List returns the instances for each provider that is
+// capable of listing instances.
+func (l *lister) List(ctx context.Context) ([]*autoscaler.Instance, error) {
+	var instances []*autoscaler.Instance
+	for _, target := range l.failover.targets {
+		lister, ok := autoscaler.AsLister(target.Provider)
+		if !ok {
+			continue
+		}
+		result, err := lister.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, instance := range result {
+			target.tag(instance)
+		}
+		instances = append(instances, result...)
+	}
+	return instances, nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package failover
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/mocks"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestList(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	lister := struct {
+		*mocks.MockProvider
+		*mocks.MockLister
+	}{
+		mocks.NewMockProvider(controller),
+		mocks.NewMockLister(controller),
+	}
+	lister.MockLister.EXPECT().List(gomock.Any()).Return([]*autoscaler.Instance{{ID: "1"}}, nil)
+
+	p := New(
+		Target{Type: autoscaler.ProviderHetznerCloud, Provider: mocks.NewMockProvider(controller)},
+		Target{Type: autoscaler.ProviderDigitalOcean, Provider: lister},
+	)
+
+	l, ok := autoscaler.AsLister(p)
+	if !ok {
+		t.Errorf("Want lister implemented")
+		return
+	}
+	instances, err := l.List(context.TODO())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(instances), 1; got != want {
+		t.Errorf("Want %d instances, got %d", want, got)
+		return
+	}
+	if got, want := instances[0].Provider, autoscaler.ProviderDigitalOcean; got != want {
+		t.Errorf("Want instance provider %s, got %s", want, got)
+	}
+}
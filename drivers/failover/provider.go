@@ -0,0 +1,163 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+// Package failover provides a composite provider that creates
+// servers using an ordered or weighted list of providers, and
+// falls through to the next provider on error.
+package failover
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/drone/autoscaler"
+)
+
+// metadataTarget is the instance metadata key used to record
+// the name of the target that created the instance.
+const metadataTarget = "failover"
+
+// Target is a provider used by the failover provider.
+type Target struct {
+	// Name identifies the target, for example when the same
+	// provider type is configured for multiple regions. The
+	// name is recorded in the instance metadata, and is used
+	// to route requests for existing instances to the target
+	// that created them.
+	Name string
+
+	// Type is the provider type, used to route requests for
+	// existing instances to the provider that created them,
+	// if the instance metadata does not include the target.
+	Type autoscaler.ProviderType
+
+	// Provider is the provider used to manage instances.
+	Provider autoscaler.Provider
+
+	// Weight is the relative weight used to select the
+	// provider. If no provider is weighted, the providers
+	// are used in order.
+	Weight int
+}
+
+type provider struct {
+	targets []Target
+}
+
+// New returns a new failover provider. The provider only
+// implements the optional Hibernator, Interrupter and Lister
+// interfaces if at least one provider implements them.
+func New(targets ...Target) autoscaler.Provider {
+	p := &provider{targets: targets}
+
+	var wrapped autoscaler.Provider = p
+	if p.supports(func(target autoscaler.Provider) bool {
+		_, ok := autoscaler.AsHibernator(target)
+		return ok
+	}) {
+		wrapped = &hibernator{Provider: wrapped, failover: p}
+	}
+	if p.supports(func(target autoscaler.Provider) bool {
+		_, ok := autoscaler.AsInterrupter(target)
+		return ok
+	}) {
+		wrapped = &interrupter{Provider: wrapped, failover: p}
+	}
+	if p.supports(func(target autoscaler.Provider) bool {
+		_, ok := autoscaler.AsLister(target)
+		return ok
+	}) {
+		wrapped = &lister{Provider: wrapped, failover: p}
+	}
+	return wrapped
+}
+
+// helper function returns true if at least one target
+// provider supports the optional interface.
+func (p *provider) supports(fn func(autoscaler.Provider) bool) bool {
+	for _, target := range p.targets {
+		if fn(target.Provider) {
+			return true
+		}
+	}
+	return false
+}
+
+// helper function returns the targets in the order used to
+// create instances. Weighted targets are ordered by weighted
+// random selection without replacement.
+func (p *provider) order() []Target {
+	var total int
+	for _, target := range p.targets {
+		total += max(target.Weight, 0)
+	}
+	if total == 0 {
+		return p.targets
+	}
+
+	remaining := make([]Target, len(p.targets))
+	copy(remaining, p.targets)
+
+	var ordered []Target
+	for len(remaining) != 0 {
+		// targets without weight are used last, in order.
+		if total == 0 {
+			return append(ordered, remaining...)
+		}
+		n := rand.Intn(total)
+		for i, target := range remaining {
+			weight := max(target.Weight, 0)
+			if n < weight {
+				ordered = append(ordered, target)
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				total -= weight
+				break
+			}
+			n -= weight
+		}
+	}
+	return ordered
+}
+
+// helper function returns the target for the instance,
+// based on the target name recorded in the instance metadata,
+// or the provider type of the instance.
+func (p *provider) lookup(instance *autoscaler.Instance) (Target, error) {
+	if name := instance.Metadata[metadataTarget]; name != "" {
+		for _, target := range p.targets {
+			if target.Name == name {
+				return target, nil
+			}
+		}
+		return Target{}, fmt.Errorf("failover: no provider configured for %q", name)
+	}
+	for _, target := range p.targets {
+		if target.Type == instance.Provider {
+			return target, nil
+		}
+	}
+	// instances created before the failover provider was
+	// configured may not have a provider type, in which
+	// case the first provider is used.
+	if instance.Provider == "" && len(p.targets) != 0 {
+		return p.targets[0], nil
+	}
+	return Target{}, fmt.Errorf("failover: no provider configured for %q", instance.Provider)
+}
+
+// helper function records the target that created the
+// instance, so that requests for the instance are routed
+// to the target.
+func (t Target) tag(instance *autoscaler.Instance) {
+	if instance.Provider == "" {
+		instance.Provider = t.Type
+	}
+	if t.Name == "" {
+		return
+	}
+	if instance.Metadata == nil {
+		instance.Metadata = map[string]string{}
+	}
+	instance.Metadata[metadataTarget] = t.Name
+}
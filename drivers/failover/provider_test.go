@@ -0,0 +1,71 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package failover
+
+import (
+	"testing"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/mocks"
+
+	"github.com/golang/mock/gomock"
+)
+
+// This test verifies that the optional interfaces are not
+// implemented if no provider implements them.
+func TestNew_Unsupported(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	p := New(
+		Target{Type: autoscaler.ProviderDigitalOcean, Provider: mocks.NewMockProvider(controller)},
+		Target{Type: autoscaler.ProviderHetznerCloud, Provider: mocks.NewMockProvider(controller)},
+	)
+	if _, ok := autoscaler.AsHibernator(p); ok {
+		t.Errorf("Want hibernator not implemented")
+	}
+	if _, ok := autoscaler.AsInterrupter(p); ok {
+		t.Errorf("Want interrupter not implemented")
+	}
+	if _, ok := autoscaler.AsLister(p); ok {
+		t.Errorf("Want lister not implemented")
+	}
+}
+
+// This test verifies that the optional interfaces are
+// implemented if at least one provider implements them.
+func TestNew_Supported(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	hibernator := struct {
+		*mocks.MockProvider
+		*mocks.MockHibernator
+	}{
+		mocks.NewMockProvider(controller),
+		mocks.NewMockHibernator(controller),
+	}
+	interrupter := struct {
+		*mocks.MockProvider
+		*mocks.MockInterrupter
+	}{
+		mocks.NewMockProvider(controller),
+		mocks.NewMockInterrupter(controller),
+	}
+
+	p := New(
+		Target{Type: autoscaler.ProviderAmazon, Provider: hibernator},
+		Target{Type: autoscaler.ProviderGoogle, Provider: interrupter},
+	)
+	if _, ok := autoscaler.AsHibernator(p); !ok {
+		t.Errorf("Want hibernator implemented")
+	}
+	if _, ok := autoscaler.AsInterrupter(p); !ok {
+		t.Errorf("Want interrupter implemented")
+	}
+	if _, ok := autoscaler.AsLister(p); ok {
+		t.Errorf("Want lister not implemented")
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package google
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/drivers/internal/batch"
+	"github.com/drone/autoscaler/logger"
+
+	"google.golang.org/api/compute/v1"
+)
+
+// BatchCreate creates the instances with a single bulk insert
+// request. The instance metadata is shared by every instance in
+// the request, so the instances are created individually if the
+// userdata is different for each instance.
+func (p *provider) BatchCreate(ctx context.Context, opts []autoscaler.InstanceCreateOpts) ([]*autoscaler.Instance, error) {
+	if len(opts) == 0 {
+		return nil, nil
+	}
+
+	p.init.Do(func() {
+		p.setup(ctx)
+	})
+
+	userdata, ok, err := batch.Render(p.userdata, opts)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return p.limiter.CreateEach(ctx, opts, p.Create)
+	}
+
+	// select the preferred zone. instances in a batch are
+	// created in the same zone.
+	zone := p.zoneTracker.order(p.zones, time.Now())[0]
+
+	logger := logger.FromContext(ctx).
+		WithField("zone", zone).
+		WithField("image", p.image).
+		WithField("size", p.size).
+		WithField("count", len(opts))
+
+	logger.Debugln("instance bulk insert")
+
+	names := map[string]compute.BulkInsertInstanceResourcePerInstanceProperties{}
+	for _, o := range opts {
+		name := strings.ToLower(o.Name)
+		names[name] = compute.BulkInsertInstanceResourcePerInstanceProperties{
+			Name: name,
+		}
+	}
+
+	// spot and preemptible capacity may be unavailable, in
+	// which case the instances are created with the standard
+	// provisioning model.
+	models := []string{p.provisioningModel}
+	if p.provisioningModel != provisioningStandard {
+		models = append(models, provisioningStandard)
+	}
+	for _, model := range models {
+		err = p.bulkInsert(ctx, zone, model, userdata, names, len(opts))
+		if err == nil {
+			break
+		}
+		logger.WithError(err).
+			WithField("provisioning-model", model).
+			Errorln("instance bulk insert failed")
+
+		// only capacity errors are retried with the standard
+		// provisioning model, and lower the health score of
+		// the zone.
+		if !isCapacityError(err) {
+			break
+		}
+		p.zoneTracker.failure(zone, time.Now())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debugln("instance bulk insert operation complete")
+
+	// the bulk insert may create fewer instances than
+	// requested, in which case the remaining entries
+	// are nil.
+	var created int
+	instances := make([]*autoscaler.Instance, len(opts))
+	for i, o := range opts {
+		name := strings.ToLower(o.Name)
+		resp, err := p.service.Instances.Get(p.project, zone, name).Do()
+		if err != nil {
+			logger.WithError(err).
+				WithField("name", o.Name).
+				Warnln("cannot get instance details")
+			continue
+		}
+
+		address := resp.NetworkInterfaces[0].NetworkIP
+		if !p.privateIP {
+			address = resp.NetworkInterfaces[0].AccessConfigs[0].NatIP
+		}
+
+		instances[i] = &autoscaler.Instance{
+			Provider:            autoscaler.ProviderGoogle,
+			ID:                  name,
+			Name:                o.Name,
+			Image:               p.image,
+			Region:              zone,
+			Size:                p.size,
+			Address:             address,
+			ServiceAccountEmail: p.serviceAccountEmail,
+			Scopes:              p.scopes,
+			Metadata: map[string]string{
+				"project": p.project,
+				"zone":    resp.Zone,
+			},
+		}
+		created++
+	}
+
+	p.zoneTracker.success(zone, created)
+
+	logger.WithField("created", created).
+		Debugln("instances inserted")
+
+	if created < len(opts) {
+		return instances, fmt.Errorf("created %d of %d instances", created, len(opts))
+	}
+	return instances, nil
+}
+
+// helper function inserts count instances with the given
+// provisioning model, and waits for the operation to complete.
+func (p *provider) bulkInsert(ctx context.Context, zone, model, userdata string, names map[string]compute.BulkInsertInstanceResourcePerInstanceProperties, count int) error {
+	// the bulk insert instance properties reference the
+	// machine and disk types by name, not by url. The
+	// properties are shared by every instance, and are
+	// therefore not labeled with the server name.
+	in := p.instance("", zone, model, userdata)
+	for _, disk := range in.Disks {
+		disk.DeviceName = ""
+		disk.InitializeParams.DiskType = p.diskType
+	}
+	props := &compute.InstanceProperties{
+		MachineType:       p.size,
+		MinCpuPlatform:    in.MinCpuPlatform,
+		Metadata:          in.Metadata,
+		Tags:              in.Tags,
+		Disks:             in.Disks,
+		CanIpForward:      in.CanIpForward,
+		NetworkInterfaces: in.NetworkInterfaces,
+		Labels:            in.Labels,
+		Scheduling:        in.Scheduling,
+		ServiceAccounts:   in.ServiceAccounts,
+	}
+
+	op, err := p.service.Instances.BulkInsert(p.project, zone, &compute.BulkInsertInstanceResource{
+		Count:                 int64(count),
+		MinCount:              1,
+		InstanceProperties:    props,
+		PerInstanceProperties: names,
+	}).Do()
+	if err != nil {
+		return err
+	}
+	return p.waitZoneOperation(ctx, op.Name, zone)
+}
@@ -0,0 +1,81 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package google
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/drone/autoscaler"
+	"github.com/h2non/gock"
+)
+
+func TestBatchCreate(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://compute.googleapis.com").
+		Post("/compute/v1/projects/my-project/zones/us-central1-a/instances/bulkInsert").
+		Reply(200).
+		BodyString(`{ "name": "operation-name" }`)
+
+	gock.New("https://compute.googleapis.com").
+		Get("/compute/v1/projects/my-project/zones/us-central1-a/operations/operation-name").
+		Reply(200).
+		BodyString(`{ "status": "DONE" }`)
+
+	gock.New("https://compute.googleapis.com").
+		Get("/compute/v1/projects/my-project/zones/us-central1-a/instances/agent-1").
+		Reply(200).
+		BodyString(`{ "zone": "https://www.googleapis.com/compute/v1/projects/my-project/zones/us-central1-a", "networkInterfaces": [ { "accessConfigs": [ { "natIP": "1.2.3.4" } ] } ] }`)
+
+	gock.New("https://compute.googleapis.com").
+		Get("/compute/v1/projects/my-project/zones/us-central1-a/instances/agent-2").
+		Reply(404)
+
+	v, err := New(
+		WithClient(http.DefaultClient),
+		WithZones("us-central1-a"),
+		WithProject("my-project"),
+		WithUserData("#cloud-init"),
+	)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	p := v.(*provider)
+	p.init.Do(func() {})
+
+	instances, err := p.BatchCreate(context.TODO(), []autoscaler.InstanceCreateOpts{
+		{Name: "agent-1"},
+		{Name: "agent-2"},
+	})
+	if err == nil {
+		t.Errorf("Want error when fewer instances are created")
+	}
+	if got, want := len(instances), 2; got != want {
+		t.Errorf("Want %d instances, got %d", want, got)
+		return
+	}
+	if instances[0] == nil {
+		t.Errorf("Want first instance created")
+		return
+	}
+	if want, got := instances[0].Address, "1.2.3.4"; got != want {
+		t.Errorf("Want instance IP %q, got %q", want, got)
+	}
+	if want, got := instances[0].Name, "agent-1"; got != want {
+		t.Errorf("Want instance Name %q, got %q", want, got)
+	}
+	if want, got := instances[0].Metadata["project"], "my-project"; got != want {
+		t.Errorf("Want instance project metadata %q, got %q", want, got)
+	}
+	if want, got := instances[0].Metadata["zone"], "https://www.googleapis.com/compute/v1/projects/my-project/zones/us-central1-a"; got != want {
+		t.Errorf("Want instance zone metadata %q, got %q", want, got)
+	}
+	if instances[1] != nil {
+		t.Errorf("Want second instance not created")
+	}
+}
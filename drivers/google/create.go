@@ -8,10 +8,11 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"math/rand"
 	"strings"
+	"time"
 
 	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/drivers/internal/owner"
 	"github.com/drone/autoscaler/logger"
 
 	"google.golang.org/api/compute/v1"
@@ -29,19 +30,118 @@ func (p *provider) Create(ctx context.Context, opts autoscaler.InstanceCreateOpt
 		return nil, err
 	}
 
-	name := strings.ToLower(opts.Name)
+	// zones are ordered by health and by the number of
+	// instances in each zone, to spread instances evenly.
+	zones := p.zoneTracker.order(p.zones, time.Now())
+
+	// spot and preemptible capacity may be unavailable in
+	// every zone, in which case the instance is created with
+	// the standard provisioning model.
+	models := []string{p.provisioningModel}
+	if p.provisioningModel != provisioningStandard {
+		models = append(models, provisioningStandard)
+	}
+
+	var lastErr error
+	for _, model := range models {
+		for _, zone := range zones {
+			instance, err := p.create(ctx, opts, zone, model, buf.String())
+			if err == nil {
+				p.zoneTracker.success(zone, 1)
+				return instance, nil
+			}
+
+			// only capacity errors are retried in the
+			// remaining zones, and lower the health score
+			// of the zone.
+			if !isCapacityError(err) {
+				return nil, err
+			}
+			p.zoneTracker.failure(zone, time.Now())
+			lastErr = err
+
+			logger.FromContext(ctx).
+				WithError(err).
+				WithField("zone", zone).
+				WithField("provisioning-model", model).
+				Warnln("insufficient zone capacity, trying next zone")
+		}
+	}
+	return nil, fmt.Errorf("failed to create instance in all zones: %s: %w", strings.Join(zones, ", "), lastErr)
+}
 
-	// select random zone from the list
-	zone := p.zones[rand.Intn(len(p.zones))]
+func (p *provider) create(ctx context.Context, opts autoscaler.InstanceCreateOpts, zone, model, userdata string) (*autoscaler.Instance, error) {
+	name := strings.ToLower(opts.Name)
 
 	logger := logger.FromContext(ctx).
 		WithField("zone", zone).
 		WithField("image", p.image).
 		WithField("size", p.size).
+		WithField("provisioning-model", model).
 		WithField("name", opts.Name)
 
 	logger.Debugln("instance insert")
 
+	in := p.instance(name, zone, model, userdata)
+
+	op, err := p.service.Instances.Insert(p.project, zone, in).Do()
+	if err != nil {
+		logger.WithError(err).
+			Errorln("instance insert failed")
+		return nil, err
+	}
+
+	logger.Debugln("pending instance insert operation")
+
+	err = p.waitZoneOperation(ctx, op.Name, zone)
+	if err != nil {
+		logger.WithError(err).
+			Errorln("instance insert operation failed")
+		return nil, err
+	}
+
+	logger.Debugln("instance insert operation complete")
+
+	resp, err := p.service.Instances.Get(p.project, zone, name).Do()
+	if err != nil {
+		logger.WithError(err).
+			Errorln("cannot get instance details")
+		return nil, err
+	}
+
+	address := resp.NetworkInterfaces[0].NetworkIP
+
+	if !p.privateIP {
+		address = resp.NetworkInterfaces[0].AccessConfigs[0].NatIP
+	}
+
+	instance := &autoscaler.Instance{
+		Provider:            autoscaler.ProviderGoogle,
+		ID:                  name,
+		Name:                opts.Name,
+		Image:               p.image,
+		Region:              zone,
+		Size:                p.size,
+		Address:             address,
+		ServiceAccountEmail: p.serviceAccountEmail,
+		Scopes:              p.scopes,
+		Metadata: map[string]string{
+			"project": p.project,
+			"zone":    resp.Zone,
+		},
+	}
+
+	logger.
+		WithField("name", instance.Name).
+		WithField("ip", instance.Address).
+		Debugln("instance inserted")
+
+	return instance, nil
+}
+
+// helper function returns the instance resource used to
+// insert an instance with the given name and zone.
+func (p *provider) instance(name, zone, model, userdata string) *compute.Instance {
 	networkConfig := []*compute.AccessConfig{}
 	if !p.privateIP {
 		networkConfig = []*compute.AccessConfig{
@@ -61,7 +161,7 @@ func (p *provider) Create(ctx context.Context, opts autoscaler.InstanceCreateOpt
 			Items: []*compute.MetadataItems{
 				{
 					Key:   p.userdataKey,
-					Value: googleapi.String(buf.String()),
+					Value: googleapi.String(userdata),
 				},
 			},
 		},
@@ -91,12 +191,8 @@ func (p *provider) Create(ctx context.Context, opts autoscaler.InstanceCreateOpt
 				AccessConfigs: networkConfig,
 			},
 		},
-		Labels: p.labels,
-		Scheduling: &compute.Scheduling{
-			Preemptible:       false,
-			OnHostMaintenance: "MIGRATE",
-			AutomaticRestart:  googleapi.Bool(true),
-		},
+		Labels:             owner.Merge(p.labels, p.owner.Labels(name)),
+		Scheduling:         p.scheduling(model),
 		DeletionProtection: false,
 		ServiceAccounts: []*compute.ServiceAccount{
 			{
@@ -118,53 +214,32 @@ func (p *provider) Create(ctx context.Context, opts autoscaler.InstanceCreateOpt
 		}
 	}
 
-	op, err := p.service.Instances.Insert(p.project, zone, in).Do()
-	if err != nil {
-		logger.WithError(err).
-			Errorln("instance insert failed")
-		return nil, err
-	}
-
-	logger.Debugln("pending instance insert operation")
-
-	err = p.waitZoneOperation(ctx, op.Name, zone)
-	if err != nil {
-		logger.WithError(err).
-			Errorln("instance insert operation failed")
-		return nil, err
-	}
-
-	logger.Debugln("instance insert operation complete")
-
-	resp, err := p.service.Instances.Get(p.project, zone, name).Do()
-	if err != nil {
-		logger.WithError(err).
-			Errorln("cannot get instance details")
-		return nil, err
-	}
-
-	address := resp.NetworkInterfaces[0].NetworkIP
-
-	if !p.privateIP {
-		address = resp.NetworkInterfaces[0].AccessConfigs[0].NatIP
-	}
+	return in
+}
 
-	instance := &autoscaler.Instance{
-		Provider:            autoscaler.ProviderGoogle,
-		ID:                  name,
-		Name:                opts.Name,
-		Image:               p.image,
-		Region:              zone,
-		Size:                p.size,
-		Address:             address,
-		ServiceAccountEmail: p.serviceAccountEmail,
-		Scopes:              p.scopes,
+// helper function returns the scheduling options for the
+// provisioning model. Spot and preemptible instances cannot
+// be live migrated or automatically restarted.
+func (p *provider) scheduling(model string) *compute.Scheduling {
+	switch model {
+	case provisioningSpot:
+		return &compute.Scheduling{
+			ProvisioningModel:         provisioningSpot,
+			InstanceTerminationAction: p.terminationAction,
+			OnHostMaintenance:         "TERMINATE",
+			AutomaticRestart:          googleapi.Bool(false),
+		}
+	case provisioningPreemptible:
+		return &compute.Scheduling{
+			Preemptible:       true,
+			OnHostMaintenance: "TERMINATE",
+			AutomaticRestart:  googleapi.Bool(false),
+		}
+	default:
+		return &compute.Scheduling{
+			Preemptible:       false,
+			OnHostMaintenance: "MIGRATE",
+			AutomaticRestart:  googleapi.Bool(true),
+		}
 	}
-
-	logger.
-		WithField("name", instance.Name).
-		WithField("ip", instance.Address).
-		Debugln("instance inserted")
-
-	return instance, nil
 }
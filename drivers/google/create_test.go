@@ -6,10 +6,13 @@ package google
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"reflect"
 	"testing"
 
 	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/drivers/internal/owner"
 	"github.com/h2non/gock"
 
 	"google.golang.org/api/compute/v1"
@@ -121,6 +124,214 @@ func TestCreateWithMultiZones(t *testing.T) {
 	}
 }
 
+// This test verifies that the instance is created in the
+// next zone if the zone has insufficient capacity.
+func TestCreateZoneCapacityRetry(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://compute.googleapis.com").
+		Post("/compute/v1/projects/my-project/zones/us-central1-b/instances").
+		Reply(200).
+		BodyString(`{ "name": "operation-b" }`)
+
+	gock.New("https://compute.googleapis.com").
+		Get("/compute/v1/projects/my-project/zones/us-central1-b/operations/operation-b").
+		Reply(200).
+		BodyString(`{ "status": "DONE", "error": { "errors": [ { "code": "ZONE_RESOURCE_POOL_EXHAUSTED", "message": "zone exhausted" } ] } }`)
+
+	gock.New("https://compute.googleapis.com").
+		Post("/compute/v1/projects/my-project/zones/us-central1-a/instances").
+		JSON(insertInstanceMock).
+		Reply(200).
+		BodyString(`{ "name": "operation-name" }`)
+
+	gock.New("https://compute.googleapis.com").
+		Get("/compute/v1/projects/my-project/zones/us-central1-a/operations/operation-name").
+		Reply(200).
+		BodyString(`{ "status": "DONE" }`)
+
+	gock.New("https://compute.googleapis.com").
+		Get("/compute/v1/projects/my-project/zones/us-central1-a/instances/agent-807jvfwj").
+		Reply(200).
+		BodyString(`{ "networkInterfaces": [ { "accessConfigs": [ { "natIP": "1.2.3.4" } ] } ] }`)
+
+	v, err := New(
+		WithClient(http.DefaultClient),
+		WithZones("us-central1-a", "us-central1-b"),
+		WithProject("my-project"),
+		WithUserData("#cloud-init"),
+	)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	p := v.(*provider)
+	p.init.Do(func() {})
+
+	// spread instances so that us-central1-b is preferred.
+	p.zoneTracker.success("us-central1-a", 1)
+
+	instance, err := p.Create(context.TODO(), autoscaler.InstanceCreateOpts{Name: "agent-807jVFwj"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if want, got := instance.Region, "us-central1-a"; got != want {
+		t.Errorf("Want region %q, got %q", want, got)
+	}
+	if !gock.IsDone() {
+		t.Errorf("Expected http requests not detected")
+	}
+}
+
+// This test verifies that errors other than capacity
+// errors are not retried in the next zone.
+func TestCreateZoneNoRetry(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://compute.googleapis.com").
+		Post("/compute/v1/projects/my-project/zones/us-central1-a/instances").
+		Reply(400).
+		BodyString(`{ "error": { "code": 400, "message": "invalid machine type" } }`)
+
+	v, err := New(
+		WithClient(http.DefaultClient),
+		WithZones("us-central1-a", "us-central1-b"),
+		WithProject("my-project"),
+		WithUserData("#cloud-init"),
+	)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	p := v.(*provider)
+	p.init.Do(func() {})
+	p.zoneTracker.success("us-central1-b", 1)
+
+	_, err = p.Create(context.TODO(), autoscaler.InstanceCreateOpts{Name: "agent-807jVFwj"})
+	if err == nil {
+		t.Errorf("Want error creating instance")
+	}
+	// only capacity errors are recorded as zone failures.
+	if got := len(p.zoneTracker.failures["us-central1-a"]); got != 0 {
+		t.Errorf("Want no zone failures recorded, got %d", got)
+	}
+}
+
+// This test verifies that a standard instance is created
+// when spot capacity is unavailable in every zone.
+func TestCreateSpotFallback(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://compute.googleapis.com").
+		Post("/compute/v1/projects/my-project/zones/us-central1-a/instances").
+		Reply(400).
+		BodyString(`{ "error": { "code": 400, "message": "ZONE_RESOURCE_POOL_EXHAUSTED" } }`)
+
+	gock.New("https://compute.googleapis.com").
+		Post("/compute/v1/projects/my-project/zones/us-central1-a/instances").
+		JSON(insertInstanceMock).
+		Reply(200).
+		BodyString(`{ "name": "operation-name" }`)
+
+	gock.New("https://compute.googleapis.com").
+		Get("/compute/v1/projects/my-project/zones/us-central1-a/instances/agent-807jvfwj").
+		Reply(200).
+		BodyString(`{ "networkInterfaces": [ { "accessConfigs": [ { "natIP": "1.2.3.4" } ] } ] }`)
+
+	gock.New("https://compute.googleapis.com").
+		Get("/compute/v1/projects/my-project/zones/us-central1-a/operations/operation-name").
+		Reply(200).
+		BodyString(`{ "status": "DONE" }`)
+
+	v, err := New(
+		WithClient(http.DefaultClient),
+		WithZones("us-central1-a"),
+		WithProject("my-project"),
+		WithUserData("#cloud-init"),
+		WithProvisioningModel("spot"),
+	)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	p := v.(*provider)
+	p.init.Do(func() {})
+
+	instance, err := p.Create(context.TODO(), autoscaler.InstanceCreateOpts{Name: "agent-807jVFwj"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if want, got := instance.Address, "1.2.3.4"; got != want {
+		t.Errorf("Want instance IP %q, got %q", want, got)
+	}
+	if !gock.IsDone() {
+		t.Errorf("Expected http requests not detected")
+	}
+}
+
+// This test verifies that the error returned when every zone
+// has insufficient capacity wraps the last provider error.
+func TestCreateZoneExhausted(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://compute.googleapis.com").
+		Post("/compute/v1/projects/my-project/zones/us-central1-a/instances").
+		Reply(400).
+		BodyString(`{ "error": { "code": 400, "message": "ZONE_RESOURCE_POOL_EXHAUSTED" } }`)
+
+	v, err := New(
+		WithClient(http.DefaultClient),
+		WithZones("us-central1-a"),
+		WithProject("my-project"),
+		WithUserData("#cloud-init"),
+	)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	p := v.(*provider)
+	p.init.Do(func() {})
+
+	_, err = p.Create(context.TODO(), autoscaler.InstanceCreateOpts{Name: "agent-807jVFwj"})
+	var gerr *googleapi.Error
+	if !errors.As(err, &gerr) {
+		t.Errorf("Want wrapped provider error, got %v", err)
+	}
+	if got, want := len(p.zoneTracker.failures["us-central1-a"]), 1; got != want {
+		t.Errorf("Want %d zone failures recorded, got %d", want, got)
+	}
+}
+
+func TestScheduling(t *testing.T) {
+	p := &provider{terminationAction: "STOP"}
+
+	spot := p.scheduling(provisioningSpot)
+	if got, want := spot.ProvisioningModel, "SPOT"; got != want {
+		t.Errorf("Want provisioning model %s, got %s", want, got)
+	}
+	if got, want := spot.InstanceTerminationAction, "STOP"; got != want {
+		t.Errorf("Want termination action %s, got %s", want, got)
+	}
+	if got, want := spot.OnHostMaintenance, "TERMINATE"; got != want {
+		t.Errorf("Want on host maintenance %s, got %s", want, got)
+	}
+
+	preemptible := p.scheduling(provisioningPreemptible)
+	if !preemptible.Preemptible {
+		t.Errorf("Want preemptible scheduling")
+	}
+	if *preemptible.AutomaticRestart {
+		t.Errorf("Want automatic restart disabled")
+	}
+
+	standard := p.scheduling(provisioningStandard)
+	if standard.Preemptible || standard.ProvisioningModel != "" {
+		t.Errorf("Want standard scheduling")
+	}
+}
+
 var insertInstanceMock = &compute.Instance{
 	Name:           "agent-807jvfwj",
 	Zone:           "projects/my-project/zones/us-central1-a",
@@ -164,7 +375,9 @@ var insertInstanceMock = &compute.Instance{
 			},
 		},
 	},
-	Labels: map[string]string{},
+	Labels: map[string]string{
+		"drone-autoscaler-server": "agent-807jvfwj",
+	},
 	Scheduling: &compute.Scheduling{
 		Preemptible:       false,
 		OnHostMaintenance: "MIGRATE",
@@ -227,7 +440,9 @@ var insertInstanceMockB = &compute.Instance{
 			},
 		},
 	},
-	Labels: map[string]string{},
+	Labels: map[string]string{
+		"drone-autoscaler-server": "agent-807jvfwj",
+	},
 	Scheduling: &compute.Scheduling{
 		Preemptible:       false,
 		OnHostMaintenance: "MIGRATE",
@@ -246,3 +461,20 @@ var insertInstanceMockB = &compute.Instance{
 		},
 	},
 }
+
+func TestInstanceLabels(t *testing.T) {
+	p := &provider{
+		labels: map[string]string{"team": "ci"},
+		owner:  owner.Owner{ID: "drone.company.com", Pool: "amd64"},
+	}
+	in := p.instance("agent-807jvfwj", "us-central1-a", provisioningStandard, "")
+	want := map[string]string{
+		"team":                    "ci",
+		"drone-autoscaler":        "drone-company-com",
+		"drone-autoscaler-pool":   "amd64",
+		"drone-autoscaler-server": "agent-807jvfwj",
+	}
+	if !reflect.DeepEqual(in.Labels, want) {
+		t.Errorf("Want labels %v, got %v", want, in.Labels)
+	}
+}
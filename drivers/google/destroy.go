@@ -23,5 +23,9 @@ func (p *provider) Destroy(ctx context.Context, instance *autoscaler.Instance) e
 		}
 		return err
 	}
-	return p.waitZoneOperation(ctx, op.Name, instance.Region)
+	err = p.waitZoneOperation(ctx, op.Name, instance.Region)
+	if err == nil {
+		p.zoneTracker.release(instance.Region)
+	}
+	return err
 }
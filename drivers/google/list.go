@@ -0,0 +1,90 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package google
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/drone/autoscaler"
+
+	"google.golang.org/api/compute/v1"
+)
+
+// errNoLabels is returned when listing instances without an
+// owner or any configured labels, since the instances that
+// belong to the autoscaler cannot be distinguished from
+// other instances.
+var errNoLabels = errors.New("google: cannot list instances without labels")
+
+// List returns the instances in the configured zones that
+// match the ownership labels, or the configured labels if
+// the owner is unknown.
+func (p *provider) List(ctx context.Context) ([]*autoscaler.Instance, error) {
+	filter, err := p.listFilter()
+	if err != nil {
+		return nil, err
+	}
+
+	var instances []*autoscaler.Instance
+	for _, zone := range p.zones {
+		err := p.service.Instances.List(p.project, zone).
+			Filter(filter).
+			Pages(ctx, func(page *compute.InstanceList) error {
+				for _, in := range page.Items {
+					instances = append(instances, p.convert(in))
+				}
+				return nil
+			})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return instances, nil
+}
+
+// helper function returns the filter expression that matches
+// instances with the ownership labels or the configured
+// labels.
+func (p *provider) listFilter() (string, error) {
+	labels := p.owner.Selector()
+	if len(labels) == 0 {
+		labels = p.labels
+	}
+	if len(labels) == 0 {
+		return "", errNoLabels
+	}
+	var filters []string
+	for key, value := range labels {
+		filters = append(filters, fmt.Sprintf("(labels.%s = %q)", key, value))
+	}
+	sort.Strings(filters)
+	return strings.Join(filters, " "), nil
+}
+
+// helper function converts the compute instance to an
+// instance.
+func (p *provider) convert(in *compute.Instance) *autoscaler.Instance {
+	instance := &autoscaler.Instance{
+		Provider: autoscaler.ProviderGoogle,
+		ID:       in.Name,
+		Name:     in.Name,
+		Image:    p.image,
+		Region:   path.Base(in.Zone),
+		Size:     path.Base(in.MachineType),
+	}
+	if len(in.NetworkInterfaces) != 0 {
+		nic := in.NetworkInterfaces[0]
+		instance.Address = nic.NetworkIP
+		if !p.privateIP && len(nic.AccessConfigs) != 0 {
+			instance.Address = nic.AccessConfigs[0].NatIP
+		}
+	}
+	return instance
+}
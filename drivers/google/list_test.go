@@ -0,0 +1,88 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package google
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/drone/autoscaler"
+	"github.com/h2non/gock"
+)
+
+func TestList(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://compute.googleapis.com").
+		Get("/compute/v1/projects/my-project/zones/us-central1-a/instances").
+		MatchParam("filter", `\(labels.role = "drone"\)`).
+		Reply(200).
+		BodyString(`{
+			"items": [
+				{
+					"name": "agent-807jvfwj",
+					"zone": "projects/my-project/zones/us-central1-a",
+					"machineType": "projects/my-project/zones/us-central1-a/machineTypes/n1-standard-1",
+					"networkInterfaces": [
+						{ "networkIP": "10.0.0.1", "accessConfigs": [ { "natIP": "1.2.3.4" } ] }
+					]
+				}
+			]
+		}`)
+
+	p, err := New(
+		WithClient(http.DefaultClient),
+		WithZones("us-central1-a"),
+		WithProject("my-project"),
+		WithLabels(map[string]string{"role": "drone"}),
+	)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	instances, err := p.(autoscaler.Lister).List(context.TODO())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !gock.IsDone() {
+		t.Errorf("Expected http requests not detected")
+	}
+	if got, want := len(instances), 1; got != want {
+		t.Errorf("Want %d instances, got %d", want, got)
+		return
+	}
+
+	instance := instances[0]
+	if got, want := instance.ID, "agent-807jvfwj"; got != want {
+		t.Errorf("Want instance id %s, got %s", want, got)
+	}
+	if got, want := instance.Region, "us-central1-a"; got != want {
+		t.Errorf("Want instance zone %s, got %s", want, got)
+	}
+	if got, want := instance.Size, "n1-standard-1"; got != want {
+		t.Errorf("Want instance size %s, got %s", want, got)
+	}
+	if got, want := instance.Address, "1.2.3.4"; got != want {
+		t.Errorf("Want instance address %s, got %s", want, got)
+	}
+}
+
+func TestList_NoLabels(t *testing.T) {
+	p, err := New(
+		WithClient(http.DefaultClient),
+		WithProject("my-project"),
+	)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = p.(autoscaler.Lister).List(context.TODO())
+	if err != errNoLabels {
+		t.Errorf("Want error listing instances without labels")
+	}
+}
@@ -7,8 +7,11 @@ package google
 import (
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/drone/autoscaler/drivers/internal/batch"
+	"github.com/drone/autoscaler/drivers/internal/owner"
 	"github.com/drone/autoscaler/drivers/internal/userdata"
 	"golang.org/x/time/rate"
 
@@ -94,6 +97,24 @@ func WithPrivateIP(private bool) Option {
 	}
 }
 
+// WithProvisioningModel returns an option to set the
+// provisioning model (STANDARD, SPOT or PREEMPTIBLE). If spot
+// or preemptible capacity is unavailable in every zone, a
+// standard instance is created.
+func WithProvisioningModel(model string) Option {
+	return func(p *provider) {
+		p.provisioningModel = strings.ToUpper(model)
+	}
+}
+
+// WithTerminationAction returns an option to set the action
+// (STOP or DELETE) taken when a spot instance is preempted.
+func WithTerminationAction(action string) Option {
+	return func(p *provider) {
+		p.terminationAction = strings.ToUpper(action)
+	}
+}
+
 // WithProject returns an option to set the project.
 func WithProject(project string) Option {
 	return func(p *provider) {
@@ -163,9 +184,29 @@ func WithServiceAccountEmail(email string) Option {
 	}
 }
 
+// WithBatchConcurrency returns an option to set the number
+// of servers created in parallel when a batch cannot be
+// created with a single request.
+func WithBatchConcurrency(n int) Option {
+	return func(p *provider) {
+		if n > 0 {
+			p.limiter = batch.NewLimiter(n)
+		}
+	}
+}
+
 func WithRateLimit(limitAmount int) Option {
 	return func(p *provider) {
 		limit := rate.Every(1 * time.Second / time.Duration(limitAmount))
 		p.rateLimiter = rate.NewLimiter(limit, 1)
 	}
 }
+
+// WithOwner returns an option to set the autoscaler identifier
+// and pool name added to the ownership metadata of every
+// instance.
+func WithOwner(id, pool string) Option {
+	return func(p *provider) {
+		p.owner = owner.Owner{ID: id, Pool: pool}
+	}
+}
@@ -13,6 +13,8 @@ import (
 	"time"
 
 	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/drivers/internal/batch"
+	"github.com/drone/autoscaler/drivers/internal/owner"
 	"github.com/drone/autoscaler/drivers/internal/userdata"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
@@ -21,6 +23,13 @@ import (
 	"google.golang.org/api/googleapi"
 )
 
+// provisioning models.
+const (
+	provisioningStandard    = "STANDARD"
+	provisioningSpot        = "SPOT"
+	provisioningPreemptible = "PREEMPTIBLE"
+)
+
 var (
 	defaultTags = []string{
 		"allow-docker",
@@ -42,6 +51,7 @@ type provider struct {
 	diskType            string
 	image               string
 	labels              map[string]string
+	owner               owner.Owner
 	network             string
 	subnetwork          string
 	stackType           string
@@ -54,8 +64,12 @@ type provider struct {
 	zones               []string
 	userdata            *template.Template
 	userdataKey         string
+	provisioningModel   string
+	terminationAction   string
 
 	rateLimiter *rate.Limiter
+	zoneTracker zoneTracker
+	limiter     batch.Limiter
 
 	service *compute.Service
 }
@@ -102,6 +116,12 @@ func New(opts ...Option) (autoscaler.Provider, error) {
 	if p.serviceAccountEmail == "" {
 		p.serviceAccountEmail = "default"
 	}
+	if p.provisioningModel == "" {
+		p.provisioningModel = provisioningStandard
+	}
+	if p.terminationAction == "" {
+		p.terminationAction = "DELETE"
+	}
 
 	if p.rateLimiter == nil {
 		// If unspecified, set to the max read rate limit for the API 25/s
@@ -109,6 +129,10 @@ func New(opts ...Option) (autoscaler.Provider, error) {
 		p.rateLimiter = rate.NewLimiter(rate.Every(time.Second/25), 1)
 	}
 
+	if p.limiter == nil {
+		p.limiter = batch.NewLimiter(batch.DefaultConcurrency)
+	}
+
 	if p.service == nil {
 		client, err := google.DefaultClient(oauth2.NoContext, compute.ComputeScope)
 		if err != nil {
@@ -134,7 +158,10 @@ func (p *provider) waitZoneOperation(ctx context.Context, name string, zone stri
 				return err
 			}
 			if op.Error != nil {
-				return errors.New(op.Error.Errors[0].Message)
+				return &operationError{
+					Code:    op.Error.Errors[0].Code,
+					Message: op.Error.Errors[0].Message,
+				}
 			}
 			if op.Status == "DONE" {
 				return nil
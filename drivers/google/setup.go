@@ -14,12 +14,26 @@ import (
 )
 
 func (p *provider) setup(ctx context.Context) error {
+	p.setupZones(ctx)
 	if reflect.DeepEqual(p.tags, defaultTags) {
 		return p.setupFirewall(ctx)
 	}
 	return nil
 }
 
+// helper function seeds the number of instances in each zone
+// with the existing instances, so that instances continue to
+// be spread evenly across zones after a restart.
+func (p *provider) setupZones(ctx context.Context) {
+	instances, err := p.List(ctx)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).
+			Debugln("cannot list instances by zone")
+		return
+	}
+	p.zoneTracker.seed(instances)
+}
+
 func (p *provider) setupFirewall(ctx context.Context) error {
 	logger := logger.FromContext(ctx)
 
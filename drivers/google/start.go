@@ -0,0 +1,63 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package google
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/logger"
+	"google.golang.org/api/googleapi"
+)
+
+func (p *provider) Start(ctx context.Context, instance *autoscaler.Instance) (*autoscaler.Instance, error) {
+	logger := logger.FromContext(ctx).
+		WithField("zone", instance.Region).
+		WithField("name", instance.Name)
+
+	// An instance's Region is actually a Zone in the google provider
+	op, err := p.service.Instances.Start(p.project, instance.Region, instance.ID).Do()
+	if err != nil {
+		if gerr, ok := err.(*googleapi.Error); ok &&
+			gerr.Code == http.StatusNotFound {
+			return nil, autoscaler.ErrInstanceNotFound
+		}
+		logger.WithError(err).
+			Errorln("instance start failed")
+		return nil, err
+	}
+
+	logger.Debugln("pending instance start operation")
+
+	err = p.waitZoneOperation(ctx, op.Name, instance.Region)
+	if err != nil {
+		logger.WithError(err).
+			Errorln("instance start operation failed")
+		return nil, err
+	}
+
+	resp, err := p.service.Instances.Get(p.project, instance.Region, instance.ID).Do()
+	if err != nil {
+		logger.WithError(err).
+			Errorln("cannot get instance details")
+		return nil, err
+	}
+
+	// the ephemeral external address is released when the
+	// instance is stopped, and may change when started.
+	started := *instance
+	started.Address = resp.NetworkInterfaces[0].NetworkIP
+
+	if !p.privateIP {
+		started.Address = resp.NetworkInterfaces[0].AccessConfigs[0].NatIP
+	}
+
+	logger.
+		WithField("ip", started.Address).
+		Debugln("instance started")
+
+	return &started, nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package google
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/drone/autoscaler"
+	"github.com/h2non/gock"
+)
+
+func TestStart(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://compute.googleapis.com").
+		Post("/compute/v1/projects/my-project/zones/us-central1-a/instances/my-instance/start").
+		Reply(200).
+		BodyString(`{ "name": "operation-name" }`)
+
+	gock.New("https://compute.googleapis.com").
+		Get("/compute/v1/projects/my-project/zones/us-central1-a/operations/operation-name").
+		Reply(200).
+		BodyString(`{ "status": "DONE" }`)
+
+	gock.New("https://compute.googleapis.com").
+		Get("/compute/v1/projects/my-project/zones/us-central1-a/instances/my-instance").
+		Reply(200).
+		BodyString(`{ "networkInterfaces": [ { "networkIP": "10.0.0.2", "accessConfigs": [ { "natIP": "1.2.3.4" } ] } ] }`)
+
+	mockContext := context.TODO()
+	mockInstance := &autoscaler.Instance{
+		ID:      "my-instance",
+		Name:    "agent-807jvfwj",
+		Region:  "us-central1-a",
+		Address: "1.1.1.1",
+	}
+
+	p, err := New(
+		WithClient(http.DefaultClient),
+		WithZones("us-central1-a"),
+		WithProject("my-project"),
+	)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	instance, err := p.(autoscaler.Hibernator).Start(mockContext, mockInstance)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := instance.Address, "1.2.3.4"; got != want {
+		t.Errorf("Want instance address %s, got %s", want, got)
+	}
+	if got, want := instance.ID, "my-instance"; got != want {
+		t.Errorf("Want instance id %s, got %s", want, got)
+	}
+}
+
+func TestStart_NotFound(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://compute.googleapis.com").
+		Post("/compute/v1/projects/my-project/zones/us-central1-a/instances/my-instance/start").
+		Reply(404)
+
+	mockContext := context.TODO()
+	mockInstance := &autoscaler.Instance{
+		ID:     "my-instance",
+		Region: "us-central1-a",
+	}
+
+	p, err := New(
+		WithClient(http.DefaultClient),
+		WithZones("us-central1-a"),
+		WithProject("my-project"),
+	)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	_, err = p.(autoscaler.Hibernator).Start(mockContext, mockInstance)
+	if err != autoscaler.ErrInstanceNotFound {
+		t.Errorf("Want instance not found error, got %v", err)
+	}
+}
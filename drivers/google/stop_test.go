@@ -0,0 +1,81 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package google
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/drone/autoscaler"
+	"github.com/h2non/gock"
+)
+
+func TestStop(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://compute.googleapis.com").
+		Post("/compute/v1/projects/my-project/zones/us-central1-a/instances/my-instance/stop").
+		Reply(200).
+		BodyString(`{ "name": "operation-name" }`)
+
+	gock.New("https://compute.googleapis.com").
+		Get("/compute/v1/projects/my-project/zones/us-central1-a/operations/operation-name").
+		Reply(200).
+		BodyString(`{ "status": "DONE" }`)
+
+	mockContext := context.TODO()
+	mockInstance := &autoscaler.Instance{
+		ID:     "my-instance",
+		Region: "us-central1-a",
+	}
+
+	p, err := New(
+		WithClient(http.DefaultClient),
+		WithZones("us-central1-a"),
+		WithProject("my-project"),
+	)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = p.(autoscaler.Hibernator).Stop(mockContext, mockInstance)
+	if err != nil {
+		t.Error(err)
+	}
+	if !gock.IsDone() {
+		t.Errorf("Expected http requests not detected")
+	}
+}
+
+func TestStop_Error(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://compute.googleapis.com").
+		Post("/compute/v1/projects/my-project/zones/us-central1-a/instances/my-instance/stop").
+		Reply(500)
+
+	mockContext := context.TODO()
+	mockInstance := &autoscaler.Instance{
+		ID:     "my-instance",
+		Region: "us-central1-a",
+	}
+
+	p, err := New(
+		WithClient(http.DefaultClient),
+		WithZones("us-central1-a"),
+		WithProject("my-project"),
+	)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = p.(autoscaler.Hibernator).Stop(mockContext, mockInstance)
+	if err == nil {
+		t.Errorf("Expect error stopping server")
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package google
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drone/autoscaler"
+
+	"google.golang.org/api/googleapi"
+)
+
+// defines the window in which zone failures lower the
+// health score of the zone.
+const zoneFailureWindow = time.Minute * 15
+
+// capacity-class error codes returned when a zone does not
+// have the resources or quota to create the instance. Rate
+// limit errors are not capacity errors, since retrying in
+// another zone would amplify the throttling.
+var capacityErrors = []string{
+	"ZONE_RESOURCE_POOL_EXHAUSTED",
+	"ZONE_RESOURCE_POOL_EXHAUSTED_WITH_DETAILS",
+	"QUOTA_EXCEEDED",
+	"RESOURCE_EXHAUSTED",
+	"quotaExceeded",
+}
+
+// operationError is returned when a zone operation fails.
+type operationError struct {
+	Code    string
+	Message string
+}
+
+func (e *operationError) Error() string {
+	return e.Message
+}
+
+// helper function returns true if the error indicates the
+// zone does not have the capacity or quota to create the
+// instance, in which case the instance can be created in a
+// different zone.
+func isCapacityError(err error) bool {
+	var operr *operationError
+	if errors.As(err, &operr) {
+		return matchCapacityError(operr.Code) ||
+			matchCapacityError(operr.Message)
+	}
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) {
+		for _, item := range gerr.Errors {
+			if matchCapacityError(item.Reason) {
+				return true
+			}
+		}
+		return matchCapacityError(gerr.Message)
+	}
+	return false
+}
+
+func matchCapacityError(s string) bool {
+	for _, code := range capacityErrors {
+		if strings.Contains(s, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// zoneTracker tracks recent failures and the number of
+// instances created in each zone, and orders the zones by
+// preference. Zones with recent failures are de-prioritized,
+// and instances are spread evenly across healthy zones.
+type zoneTracker struct {
+	sync.Mutex
+
+	failures  map[string][]time.Time
+	instances map[string]int
+}
+
+// order returns the zones ordered by preference.
+func (z *zoneTracker) order(zones []string, now time.Time) []string {
+	z.Lock()
+	defer z.Unlock()
+
+	failures := map[string]int{}
+	for _, zone := range zones {
+		failures[zone] = z.recentFailures(zone, now)
+	}
+
+	// zones are shuffled so that ties are broken randomly.
+	ordered := make([]string, len(zones))
+	for i, j := range rand.Perm(len(zones)) {
+		ordered[i] = zones[j]
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i], ordered[j]
+		if failures[a] != failures[b] {
+			return failures[a] < failures[b]
+		}
+		return z.instances[a] < z.instances[b]
+	})
+	return ordered
+}
+
+// success records n instances created in the zone.
+func (z *zoneTracker) success(zone string, n int) {
+	z.Lock()
+	defer z.Unlock()
+	if z.instances == nil {
+		z.instances = map[string]int{}
+	}
+	z.instances[zone] += n
+}
+
+// seed replaces the number of instances in each zone with
+// the number of existing instances.
+func (z *zoneTracker) seed(instances []*autoscaler.Instance) {
+	z.Lock()
+	defer z.Unlock()
+	z.instances = map[string]int{}
+	for _, instance := range instances {
+		z.instances[instance.Region]++
+	}
+}
+
+// failure records an instance creation failure in the zone.
+func (z *zoneTracker) failure(zone string, now time.Time) {
+	z.Lock()
+	defer z.Unlock()
+	if z.failures == nil {
+		z.failures = map[string][]time.Time{}
+	}
+	z.failures[zone] = append(z.failures[zone], now)
+}
+
+// release records an instance removed from the zone.
+func (z *zoneTracker) release(zone string) {
+	z.Lock()
+	defer z.Unlock()
+	if z.instances[zone] > 0 {
+		z.instances[zone]--
+	}
+}
+
+// helper function returns the number of failures in the
+// zone within the failure window. The caller must hold
+// the lock.
+func (z *zoneTracker) recentFailures(zone string, now time.Time) int {
+	var recent []time.Time
+	for _, t := range z.failures[zone] {
+		if now.Sub(t) < zoneFailureWindow {
+			recent = append(recent, t)
+		}
+	}
+	if z.failures != nil {
+		z.failures[zone] = recent
+	}
+	return len(recent)
+}
@@ -0,0 +1,103 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package google
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/drone/autoscaler"
+
+	"google.golang.org/api/googleapi"
+)
+
+func TestZoneTracker_Spread(t *testing.T) {
+	zones := []string{"us-central1-a", "us-central1-b", "us-central1-c"}
+
+	z := new(zoneTracker)
+	z.success("us-central1-a", 2)
+	z.success("us-central1-b", 1)
+
+	ordered := z.order(zones, time.Now())
+	if got, want := ordered[0], "us-central1-c"; got != want {
+		t.Errorf("Want zone %s preferred, got %s", want, got)
+	}
+	if got, want := ordered[2], "us-central1-a"; got != want {
+		t.Errorf("Want zone %s last, got %s", want, got)
+	}
+
+	z.release("us-central1-a")
+	z.release("us-central1-a")
+	z.release("us-central1-a")
+	if got, want := z.instances["us-central1-a"], 0; got != want {
+		t.Errorf("Want zone instance count %d, got %d", want, got)
+	}
+}
+
+func TestZoneTracker_Seed(t *testing.T) {
+	zones := []string{"us-central1-a", "us-central1-b"}
+
+	z := new(zoneTracker)
+	z.success("us-central1-b", 5)
+	z.seed([]*autoscaler.Instance{
+		{Region: "us-central1-a"},
+		{Region: "us-central1-a"},
+		{Region: "us-central1-b"},
+	})
+	if got, want := z.instances["us-central1-a"], 2; got != want {
+		t.Errorf("Want zone instance count %d, got %d", want, got)
+	}
+	if got, want := z.instances["us-central1-b"], 1; got != want {
+		t.Errorf("Want zone instance count %d, got %d", want, got)
+	}
+	ordered := z.order(zones, time.Now())
+	if got, want := ordered[0], "us-central1-b"; got != want {
+		t.Errorf("Want zone %s preferred, got %s", want, got)
+	}
+}
+
+func TestZoneTracker_Failures(t *testing.T) {
+	zones := []string{"us-central1-a", "us-central1-b"}
+	now := time.Now()
+
+	z := new(zoneTracker)
+	z.success("us-central1-b", 5)
+	z.failure("us-central1-a", now)
+
+	// zones with recent failures are de-prioritized, even
+	// if the zone has fewer instances.
+	ordered := z.order(zones, now)
+	if got, want := ordered[0], "us-central1-b"; got != want {
+		t.Errorf("Want zone %s preferred, got %s", want, got)
+	}
+
+	// failures outside the window are ignored.
+	ordered = z.order(zones, now.Add(zoneFailureWindow))
+	if got, want := ordered[0], "us-central1-a"; got != want {
+		t.Errorf("Want zone %s preferred, got %s", want, got)
+	}
+}
+
+func TestIsCapacityError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&operationError{Code: "ZONE_RESOURCE_POOL_EXHAUSTED"}, true},
+		{&operationError{Code: "QUOTA_EXCEEDED"}, true},
+		{&operationError{Code: "INVALID_FIELD_VALUE"}, false},
+		{&googleapi.Error{Code: 429}, false},
+		{&googleapi.Error{Code: 403, Errors: []googleapi.ErrorItem{{Reason: "rateLimitExceeded"}}}, false},
+		{&googleapi.Error{Code: 403, Errors: []googleapi.ErrorItem{{Reason: "quotaExceeded"}}}, true},
+		{&googleapi.Error{Code: 400, Message: "invalid machine type"}, false},
+		{errors.New("ZONE_RESOURCE_POOL_EXHAUSTED"), false},
+	}
+	for i, test := range tests {
+		if got := isCapacityError(test.err); got != test.want {
+			t.Errorf("Want capacity error %v at index %d, got %v", test.want, i, got)
+		}
+	}
+}
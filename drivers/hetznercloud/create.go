@@ -7,12 +7,14 @@ package hetznercloud
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"strconv"
 
 	"github.com/drone/autoscaler"
 	"github.com/drone/autoscaler/logger"
 
 	"github.com/hetznercloud/hcloud-go/hcloud"
+	"github.com/hetznercloud/hcloud-go/hcloud/schema"
 )
 
 func (p *provider) Create(ctx context.Context, opts autoscaler.InstanceCreateOpts) (*autoscaler.Instance, error) {
@@ -60,7 +62,7 @@ func (p *provider) Create(ctx context.Context, opts autoscaler.InstanceCreateOpt
 
 	logger.Debugln("instance create")
 
-	resp, _, err := p.client.Server.Create(ctx, req)
+	server, err := p.create(ctx, req, p.owner.Labels(opts.Name))
 	if err != nil {
 		logger.WithError(err).
 			Errorln("cannot create instance")
@@ -71,13 +73,75 @@ func (p *provider) Create(ctx context.Context, opts autoscaler.InstanceCreateOpt
 		WithField("name", req.Name).
 		Infoln("instance created")
 
+	// the datacenter is assigned by hetzner when the
+	// datacenter is not configured.
+	metadata := map[string]string{}
+	if server.Datacenter != nil {
+		metadata["datacenter"] = server.Datacenter.Name
+	}
+
 	return &autoscaler.Instance{
 		Provider: autoscaler.ProviderHetznerCloud,
-		ID:       strconv.Itoa(resp.Server.ID),
-		Name:     resp.Server.Name,
-		Address:  resp.Server.PublicNet.IPv4.IP.String(),
+		ID:       strconv.Itoa(server.ID),
+		Name:     server.Name,
+		Address:  server.PublicNet.IPv4.IP.String(),
 		Size:     req.ServerType.Name,
 		Region:   datacenter,
 		Image:    req.Image.Name,
+		Metadata: metadata,
 	}, nil
 }
+
+// helper function creates the server with the labels. The
+// server is created with a raw api request, since the hetzner
+// client does not support labels. The labels are set in the
+// create request, so that a server is never created without
+// the labels used to identify the server.
+func (p *provider) create(ctx context.Context, opts hcloud.ServerCreateOpts, labels map[string]string) (*hcloud.Server, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	body := struct {
+		schema.ServerCreateRequest
+		Labels map[string]string `json:"labels,omitempty"`
+	}{
+		ServerCreateRequest: schema.ServerCreateRequest{
+			Name:       opts.Name,
+			ServerType: opts.ServerType.Name,
+			Image:      opts.Image.Name,
+			UserData:   opts.UserData,
+		},
+		Labels: labels,
+	}
+	if opts.ServerType.ID != 0 {
+		body.ServerType = opts.ServerType.ID
+	}
+	if opts.Image.ID != 0 {
+		body.Image = opts.Image.ID
+	}
+	for _, key := range opts.SSHKeys {
+		body.SSHKeys = append(body.SSHKeys, key.ID)
+	}
+	if opts.Datacenter != nil {
+		body.Datacenter = opts.Datacenter.Name
+		if opts.Datacenter.ID != 0 {
+			body.Datacenter = strconv.Itoa(opts.Datacenter.ID)
+		}
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := p.client.NewRequest(ctx, "POST", "/servers", bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	res := new(schema.ServerCreateResponse)
+	_, err = p.client.Do(req, res)
+	if err != nil {
+		return nil, err
+	}
+	return hcloud.ServerFromSchema(res.Server), nil
+}
@@ -6,6 +6,9 @@ package hetznercloud
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
 	"testing"
 
 	"github.com/drone/autoscaler"
@@ -16,13 +19,29 @@ import (
 func TestCreate(t *testing.T) {
 	defer gock.Off()
 
+	// the server is labeled in the create request.
 	gock.New("https://api.hetzner.cloud").
 		Post("/v1/servers").
+		AddMatcher(func(req *http.Request, _ *gock.Request) (bool, error) {
+			body := struct {
+				Name   string            `json:"name"`
+				Labels map[string]string `json:"labels"`
+			}{}
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				return false, err
+			}
+			return body.Name == "agent1" && reflect.DeepEqual(body.Labels, map[string]string{
+				"drone-autoscaler":        "drone",
+				"drone-autoscaler-pool":   "amd64",
+				"drone-autoscaler-server": "agent1",
+			}), nil
+		}).
 		Reply(200).
 		BodyString(respInstanceCreate)
 
 	p := New(
 		WithToken("LRK9DAWQ1ZAEFSrCNEEzLCUwhYX1U3g7wMg4dTlkkDC96fyDuyJ39nVbVjCKSDfj"),
+		WithOwner("drone", "amd64"),
 	).(*provider)
 	p.init.Do(func() {}) // pre-initialize
 
@@ -30,6 +49,9 @@ func TestCreate(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
+	if !gock.IsDone() {
+		t.Errorf("Expected http requests not detected")
+	}
 
 	t.Run("Attributes", testInstance(instance))
 }
@@ -9,6 +9,7 @@ import (
 	"text/template"
 
 	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/drivers/internal/owner"
 	"github.com/drone/autoscaler/drivers/internal/userdata"
 
 	"github.com/hetznercloud/hcloud-go/hcloud"
@@ -24,6 +25,7 @@ type provider struct {
 	image      string
 	userdata   *template.Template
 	key        int
+	owner      owner.Owner
 
 	client *hcloud.Client
 }
@@ -0,0 +1,50 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package hetznercloud
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/logger"
+
+	"github.com/hetznercloud/hcloud-go/hcloud"
+)
+
+func (p *provider) Start(ctx context.Context, instance *autoscaler.Instance) (*autoscaler.Instance, error) {
+	logger := logger.FromContext(ctx).
+		WithField("region", instance.Region).
+		WithField("image", instance.Image).
+		WithField("size", instance.Size).
+		WithField("name", instance.Name)
+
+	id, err := strconv.Atoi(instance.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debugln("powering on instance")
+
+	// the primary ip address is retained when the server
+	// is powered off, so the instance address is unchanged.
+	_, _, err = p.client.Server.Poweron(ctx, &hcloud.Server{ID: id})
+
+	if err != nil {
+		if err.Error() == "hcloud: server responded with status code 404" {
+			logger.WithError(err).
+				Debugln("instance does not exist")
+			return nil, autoscaler.ErrInstanceNotFound
+		}
+
+		logger.WithError(err).
+			Errorln("powering on instance failed")
+		return nil, err
+	}
+
+	logger.Debugln("instance powered on")
+
+	return instance, nil
+}
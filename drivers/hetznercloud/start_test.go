@@ -0,0 +1,62 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package hetznercloud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drone/autoscaler"
+
+	"github.com/h2non/gock"
+)
+
+func TestStart(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.hetzner.cloud").
+		Post("/v1/servers/3164494/actions/poweron").
+		Reply(201).
+		BodyString(`{ "action": { "id": 13, "command": "start_server", "status": "running" } }`)
+
+	mockContext := context.TODO()
+	mockInstance := &autoscaler.Instance{
+		ID:      "3164494",
+		Address: "1.2.3.4",
+	}
+
+	p := New(
+		WithToken("LRK9DAWQ1ZAEFSrCNEEzLCUwhYX1U3g7wMg4dTlkkDC96fyDuyJ39nVbVjCKSDfj"),
+	)
+	instance, err := p.(autoscaler.Hibernator).Start(mockContext, mockInstance)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := instance.Address, "1.2.3.4"; got != want {
+		t.Errorf("Want instance address %s, got %s", want, got)
+	}
+}
+
+func TestStartError(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.hetzner.cloud").
+		Post("/v1/servers/3164494/actions/poweron").
+		Reply(500)
+
+	mockContext := context.TODO()
+	mockInstance := &autoscaler.Instance{
+		ID: "3164494",
+	}
+
+	p := New(
+		WithToken("LRK9DAWQ1ZAEFSrCNEEzLCUwhYX1U3g7wMg4dTlkkDC96fyDuyJ39nVbVjCKSDfj"),
+	)
+	_, err := p.(autoscaler.Hibernator).Start(mockContext, mockInstance)
+	if err == nil {
+		t.Errorf("Expect error returned from hetzner cloud")
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package hetznercloud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drone/autoscaler"
+
+	"github.com/h2non/gock"
+)
+
+func TestStop(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.hetzner.cloud").
+		Post("/v1/servers/3164494/actions/shutdown").
+		Reply(201).
+		BodyString(`{ "action": { "id": 13, "command": "shutdown_server", "status": "running" } }`)
+
+	mockContext := context.TODO()
+	mockInstance := &autoscaler.Instance{
+		ID: "3164494",
+	}
+
+	p := New(
+		WithToken("LRK9DAWQ1ZAEFSrCNEEzLCUwhYX1U3g7wMg4dTlkkDC96fyDuyJ39nVbVjCKSDfj"),
+	)
+	err := p.(autoscaler.Hibernator).Stop(mockContext, mockInstance)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStopNotFound(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.hetzner.cloud").
+		Post("/v1/servers/3164494/actions/shutdown").
+		Reply(404).
+		BodyString(destroyNotFoundResponse)
+
+	mockContext := context.TODO()
+	mockInstance := &autoscaler.Instance{
+		ID: "3164494",
+	}
+
+	p := New(
+		WithToken("LRK9DAWQ1ZAEFSrCNEEzLCUwhYX1U3g7wMg4dTlkkDC96fyDuyJ39nVbVjCKSDfj"),
+	)
+	err := p.(autoscaler.Hibernator).Stop(mockContext, mockInstance)
+	if err != autoscaler.ErrInstanceNotFound {
+		t.Errorf("Expect instance not found returned from hetzner cloud")
+	}
+}
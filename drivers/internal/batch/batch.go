@@ -0,0 +1,91 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+// Package batch provides helper functions for providers that
+// create servers with a single request, and can only send the
+// same userdata to every server in the request.
+package batch
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"text/template"
+
+	"github.com/drone/autoscaler"
+)
+
+// Render renders the userdata for each of the create options,
+// and returns the userdata if it is the same for every server.
+// If the userdata is different, for example because it includes
+// the server certificates, Render returns false and the servers
+// must be created individually.
+func Render(t *template.Template, opts []autoscaler.InstanceCreateOpts) (string, bool, error) {
+	var userdata string
+	for i := range opts {
+		buf := new(bytes.Buffer)
+		if err := t.Execute(buf, &opts[i]); err != nil {
+			return "", false, err
+		}
+		if i != 0 && buf.String() != userdata {
+			return "", false, nil
+		}
+		userdata = buf.String()
+	}
+	return userdata, true, nil
+}
+
+// DefaultConcurrency is the default number of servers created
+// in parallel by CreateEach.
+const DefaultConcurrency = 5
+
+// Limiter limits the number of servers created in parallel
+// by CreateEach. A Limiter is shared by concurrent calls, so
+// that concurrent batches do not exceed the limit.
+type Limiter chan struct{}
+
+// NewLimiter returns a Limiter that creates up to n servers
+// in parallel.
+func NewLimiter(n int) Limiter {
+	if n < 1 {
+		n = 1
+	}
+	return make(Limiter, n)
+}
+
+// CreateEach creates a server for each of the create options
+// individually, and in parallel up to the limit. The returned
+// slice has the same length and order as the create options,
+// and a nil entry indicates the server was not created. If any
+// server returns an error, the error is an autoscaler.BatchError
+// with the error of each server.
+func (l Limiter) CreateEach(ctx context.Context, opts []autoscaler.InstanceCreateOpts, create func(context.Context, autoscaler.InstanceCreateOpts) (*autoscaler.Instance, error)) ([]*autoscaler.Instance, error) {
+	var wg sync.WaitGroup
+	instances := make([]*autoscaler.Instance, len(opts))
+	errs := make(autoscaler.BatchError, len(opts))
+	for i := range opts {
+		select {
+		case l <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			instances[i], errs[i] = create(ctx, opts[i])
+			<-l
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+
+	// the create function may return an instance with an
+	// error, which is retained so the instance is saved.
+	for _, err := range errs {
+		if err != nil {
+			return instances, errs
+		}
+	}
+	return instances, nil
+}
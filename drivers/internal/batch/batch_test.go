@@ -0,0 +1,109 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package batch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/drone/autoscaler"
+)
+
+func TestRender(t *testing.T) {
+	opts := []autoscaler.InstanceCreateOpts{
+		{Name: "agent-1", TLSCert: []byte("cert-1")},
+		{Name: "agent-2", TLSCert: []byte("cert-2")},
+	}
+
+	userdata, ok, err := Render(template.Must(template.New("_").Parse("#cloud-config")), opts)
+	if err != nil {
+		t.Error(err)
+	}
+	if !ok {
+		t.Errorf("Want identical userdata rendered once")
+	}
+	if got, want := userdata, "#cloud-config"; got != want {
+		t.Errorf("Want userdata %q, got %q", want, got)
+	}
+
+	_, ok, err = Render(template.Must(template.New("_").Parse("{{ printf \"%s\" .TLSCert }}")), opts)
+	if err != nil {
+		t.Error(err)
+	}
+	if ok {
+		t.Errorf("Want different userdata reported")
+	}
+}
+
+func TestCreateEach(t *testing.T) {
+	opts := []autoscaler.InstanceCreateOpts{
+		{Name: "agent-1"},
+		{Name: "agent-2"},
+	}
+	mockerr := errors.New("mock error")
+	create := func(_ context.Context, opts autoscaler.InstanceCreateOpts) (*autoscaler.Instance, error) {
+		if opts.Name == "agent-2" {
+			return nil, mockerr
+		}
+		return &autoscaler.Instance{Name: opts.Name}, nil
+	}
+
+	instances, err := NewLimiter(2).CreateEach(context.Background(), opts, create)
+	errs, ok := err.(autoscaler.BatchError)
+	if !ok {
+		t.Errorf("Want batch error returned, got %v", err)
+		return
+	}
+	if errs[0] != nil {
+		t.Errorf("Want no error for the server created, got %v", errs[0])
+	}
+	if errs[1] != mockerr {
+		t.Errorf("Want create error for the server not created, got %v", errs[1])
+	}
+	if got, want := len(instances), 2; got != want {
+		t.Errorf("Want %d instances, got %d", want, got)
+		return
+	}
+	if instances[0] == nil || instances[0].Name != "agent-1" {
+		t.Errorf("Want first instance created")
+	}
+	if instances[1] != nil {
+		t.Errorf("Want nil entry for the server not created")
+	}
+}
+
+// This test verifies that the number of servers created in
+// parallel does not exceed the limit.
+func TestCreateEach_Limit(t *testing.T) {
+	opts := make([]autoscaler.InstanceCreateOpts, 10)
+
+	var mu sync.Mutex
+	var active, peak int
+	create := func(_ context.Context, opts autoscaler.InstanceCreateOpts) (*autoscaler.Instance, error) {
+		mu.Lock()
+		active++
+		if active > peak {
+			peak = active
+		}
+		mu.Unlock()
+		time.Sleep(time.Millisecond * 10)
+		mu.Lock()
+		active--
+		mu.Unlock()
+		return &autoscaler.Instance{}, nil
+	}
+
+	_, err := NewLimiter(3).CreateEach(context.Background(), opts, create)
+	if err != nil {
+		t.Error(err)
+	}
+	if peak > 3 {
+		t.Errorf("Want at most 3 servers created in parallel, got %d", peak)
+	}
+}
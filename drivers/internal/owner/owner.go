@@ -0,0 +1,107 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+// Package owner provides the ownership metadata added to
+// every server created by the autoscaler, used to identify
+// the autoscaler deployment and pool that owns a server when
+// several deployments share the same provider account.
+package owner
+
+import (
+	"sort"
+	"strings"
+)
+
+// ownership label keys.
+const (
+	KeyID     = "drone-autoscaler"
+	KeyPool   = "drone-autoscaler-pool"
+	KeyServer = "drone-autoscaler-server"
+)
+
+// maximum label value length, which is the most restrictive
+// length supported by the providers.
+const maxLength = 63
+
+// Owner identifies the autoscaler deployment and the pool
+// that owns a server.
+type Owner struct {
+	ID   string
+	Pool string
+}
+
+// Labels returns the ownership labels for the named server.
+// Empty values are omitted.
+func (o Owner) Labels(server string) map[string]string {
+	labels := map[string]string{}
+	add := func(key, value string) {
+		if value = Sanitize(value); value != "" {
+			labels[key] = value
+		}
+	}
+	add(KeyID, o.ID)
+	add(KeyPool, o.Pool)
+	add(KeyServer, server)
+	return labels
+}
+
+// Selector returns the labels that identify the servers owned
+// by the autoscaler deployment and pool. The selector is empty
+// if the owner is unknown.
+func (o Owner) Selector() map[string]string {
+	if Sanitize(o.ID) == "" {
+		return nil
+	}
+	return o.Labels("")
+}
+
+// Tags returns the ownership labels as tags in key:value
+// format, for providers that only support string tags.
+func (o Owner) Tags(server string) []string {
+	return Tags(o.Labels(server))
+}
+
+// Tags converts the labels to sorted tags in key:value format.
+func Tags(labels map[string]string) []string {
+	var tags []string
+	for key, value := range labels {
+		tags = append(tags, key+":"+value)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// Merge returns a new map with the labels in b added to the
+// labels in a.
+func Merge(a, b map[string]string) map[string]string {
+	out := make(map[string]string, len(a)+len(b))
+	for key, value := range a {
+		out[key] = value
+	}
+	for key, value := range b {
+		out[key] = value
+	}
+	return out
+}
+
+// Sanitize returns the value converted to lowercase, with
+// characters other than letters, numbers, dashes and
+// underscores replaced with dashes. The value is truncated
+// to 63 characters.
+func Sanitize(value string) string {
+	value = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return '-'
+		}
+	}, value)
+	if len(value) > maxLength {
+		value = value[:maxLength]
+	}
+	return strings.Trim(value, "-_")
+}
@@ -0,0 +1,68 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package owner
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLabels(t *testing.T) {
+	o := Owner{ID: "drone.company.com", Pool: "amd64"}
+	got := o.Labels("agent-807jvfwj")
+	want := map[string]string{
+		KeyID:     "drone-company-com",
+		KeyPool:   "amd64",
+		KeyServer: "agent-807jvfwj",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Want labels %v, got %v", want, got)
+	}
+
+	got = Owner{ID: "drone"}.Labels("")
+	want = map[string]string{KeyID: "drone"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Want empty labels omitted, got %v", got)
+	}
+}
+
+func TestSelector(t *testing.T) {
+	if got := (Owner{Pool: "amd64"}).Selector(); got != nil {
+		t.Errorf("Want empty selector without owner, got %v", got)
+	}
+	got := Owner{ID: "drone", Pool: "amd64"}.Selector()
+	want := map[string]string{KeyID: "drone", KeyPool: "amd64"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Want selector %v, got %v", want, got)
+	}
+}
+
+func TestTags(t *testing.T) {
+	got := Owner{ID: "drone", Pool: "amd64"}.Tags("agent-1")
+	want := []string{
+		"drone-autoscaler-pool:amd64",
+		"drone-autoscaler-server:agent-1",
+		"drone-autoscaler:drone",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Want tags %v, got %v", want, got)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	tests := map[string]string{
+		"drone.company.com:443": "drone-company-com-443",
+		"Agent_1":               "agent_1",
+		"-pool-":                "pool",
+		"":                      "",
+		strings.Repeat("a", 70): strings.Repeat("a", 63),
+	}
+	for in, want := range tests {
+		if got := Sanitize(in); got != want {
+			t.Errorf("Want %q sanitized to %q, got %q", in, want, got)
+		}
+	}
+}
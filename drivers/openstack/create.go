@@ -9,6 +9,7 @@ import (
 	"context"
 
 	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/drivers/internal/owner"
 	"github.com/drone/autoscaler/logger"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/floatingips"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/keypairs"
@@ -61,7 +62,7 @@ func (p *provider) Create(ctx context.Context, opts autoscaler.InstanceCreateOpt
 		Networks:       nets,
 		UserData:       buf.Bytes(),
 		ServiceClient:  p.computeClient,
-		Metadata:       p.metadata,
+		Metadata:       owner.Merge(p.metadata, p.owner.Labels(opts.Name)),
 		SecurityGroups: p.groups,
 	}
 	createOpts := keypairs.CreateOptsExt{
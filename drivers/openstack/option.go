@@ -7,6 +7,7 @@ package openstack
 import (
 	"io/ioutil"
 
+	"github.com/drone/autoscaler/drivers/internal/owner"
 	"github.com/drone/autoscaler/drivers/internal/userdata"
 	"github.com/gophercloud/gophercloud"
 )
@@ -107,3 +108,12 @@ func WithUserDataFile(filepath string) Option {
 		}
 	}
 }
+
+// WithOwner returns an option to set the autoscaler identifier
+// and pool name added to the ownership metadata of every
+// instance.
+func WithOwner(id, pool string) Option {
+	return func(p *provider) {
+		p.owner = owner.Owner{ID: id, Pool: pool}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"text/template"
 
 	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/drivers/internal/owner"
 	"github.com/drone/autoscaler/drivers/internal/userdata"
 	"github.com/gophercloud/gophercloud"
 	"github.com/gophercloud/gophercloud/openstack"
@@ -31,6 +32,7 @@ type provider struct {
 	userdata *template.Template
 	groups   []string
 	metadata map[string]string
+	owner    owner.Owner
 
 	computeClient *gophercloud.ServiceClient
 	networkClient *gophercloud.ServiceClient
@@ -42,6 +42,7 @@ func (p *provider) Create(ctx context.Context, opts autoscaler.InstanceCreateOpt
 		ProjectID:    p.project,
 		BillingCycle: p.billing,
 		UserData:     buf.String(),
+		Tags:         append(p.owner.Tags(opts.Name), p.tags...),
 	}
 
 	logger.Debugln("instance create")
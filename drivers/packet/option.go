@@ -7,6 +7,7 @@ package packet
 import (
 	"io/ioutil"
 
+	"github.com/drone/autoscaler/drivers/internal/owner"
 	"github.com/drone/autoscaler/drivers/internal/userdata"
 )
 
@@ -94,3 +95,12 @@ func WithUserDataFile(filepath string) Option {
 		}
 	}
 }
+
+// WithOwner returns an option to set the autoscaler identifier
+// and pool name added to the ownership metadata of every
+// instance.
+func WithOwner(id, pool string) Option {
+	return func(p *provider) {
+		p.owner = owner.Owner{ID: id, Pool: pool}
+	}
+}
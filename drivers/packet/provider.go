@@ -9,6 +9,7 @@ import (
 	"text/template"
 
 	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/drivers/internal/owner"
 	"github.com/drone/autoscaler/drivers/internal/userdata"
 	"github.com/packethost/packngo"
 )
@@ -28,6 +29,7 @@ type provider struct {
 	sshkey   string
 	hostname string
 	tags     []string
+	owner    owner.Owner
 	userdata *template.Template
 
 	client *packngo.Client
@@ -29,7 +29,7 @@ func (p *provider) Create(ctx context.Context, opts autoscaler.InstanceCreateOpt
 		DynamicIPRequired: scw.BoolPtr(p.dynamicIP),
 		CommercialType:    p.size,
 		Image:             p.image,
-		Tags:              p.tags,
+		Tags:              append(p.owner.Tags(opts.Name), p.tags...),
 		SecurityGroup:     p.securityGroup,
 	}
 
@@ -7,6 +7,7 @@ package scaleway
 import (
 	"io/ioutil"
 
+	"github.com/drone/autoscaler/drivers/internal/owner"
 	"github.com/drone/autoscaler/drivers/internal/userdata"
 	"github.com/scaleway/scaleway-sdk-go/scw"
 )
@@ -110,3 +111,13 @@ func WithUserDataFile(filepath string) Option {
 		return nil
 	}
 }
+
+// WithOwner returns an option to set the autoscaler identifier
+// and pool name added to the ownership metadata of every
+// instance.
+func WithOwner(id, pool string) Option {
+	return func(p *provider) error {
+		p.owner = owner.Owner{ID: id, Pool: pool}
+		return nil
+	}
+}
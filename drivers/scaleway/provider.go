@@ -12,6 +12,7 @@ import (
 	"github.com/scaleway/scaleway-sdk-go/scw"
 
 	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/drivers/internal/owner"
 )
 
 // provider implements a Scaleway provider.
@@ -27,6 +28,7 @@ type provider struct {
 	size          string
 	image         string
 	tags          []string
+	owner         owner.Owner
 	userdata      *template.Template
 
 	client *scw.Client
@@ -19,4 +19,28 @@ type Engine interface {
 	Paused() bool
 	// Resume resumes the Engine if paused.
 	Resume()
+	// Pools returns the runtime status of each pool.
+	Pools() []*PoolStatus
+}
+
+// PoolStatus provides the runtime status of a pool.
+type PoolStatus struct {
+	Name     string `json:"name"`
+	Min      int    `json:"min"`
+	Max      int    `json:"max"`
+	Buffer   int    `json:"buffer"`
+	Schedule string `json:"schedule,omitempty"`
+
+	// Rollout provides the progress of the rolling
+	// replacement of outdated servers, if enabled.
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+}
+
+// RolloutStatus provides the progress of the rolling
+// replacement of servers running an outdated agent
+// configuration.
+type RolloutStatus struct {
+	Hash     string `json:"hash"`
+	Updated  int    `json:"updated"`
+	Outdated int    `json:"outdated"`
 }
@@ -6,6 +6,7 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"time"
 
@@ -15,12 +16,23 @@ import (
 	"github.com/drone/autoscaler/metrics"
 )
 
+// errNotCreated is recorded for a server that was not created
+// in a batch, and for which the provider returned no error.
+var errNotCreated = errors.New("server not created in batch")
+
 type allocator struct {
 	wg sync.WaitGroup
 
-	servers  autoscaler.ServerStore
-	provider autoscaler.Provider
-	metrics  metrics.Collector
+	servers    autoscaler.ServerStore
+	provider   autoscaler.Provider
+	hibernator autoscaler.Hibernator
+	metrics    metrics.Collector
+
+	// batch is used to create pending servers in batches
+	// of up to batchSize servers. If nil, servers are
+	// created individually.
+	batch     autoscaler.BatchCreator
+	batchSize int
 }
 
 func (a *allocator) Allocate(ctx context.Context) error {
@@ -31,26 +43,60 @@ func (a *allocator) Allocate(ctx context.Context) error {
 		return err
 	}
 
+	var batch []*autoscaler.Server
 	for _, server := range servers {
-		server.State = autoscaler.StateCreating
-		err = a.servers.Update(ctx, server)
+		err = transition(ctx, a.servers, server, autoscaler.StateCreating)
+		if err == autoscaler.ErrStateConflict {
+			continue
+		}
 		if err != nil {
 			logger.WithError(err).
 				WithField("server", server.Name).
 				WithField("state", "creating").
 				Errorln("failed to update server state")
+			a.allocateBatch(ctx, batch)
 			return err
 		}
 
+		// hibernated servers are started individually
+		// and are never included in a batch.
+		if a.batch != nil && a.batchSize > 1 && server.ID == "" {
+			batch = append(batch, server)
+			if len(batch) == a.batchSize {
+				a.allocateBatch(ctx, batch)
+				batch = nil
+			}
+			continue
+		}
+
 		a.wg.Add(1)
 		go func(server *autoscaler.Server) {
 			a.allocate(ctx, server)
 			a.wg.Done()
 		}(server)
 	}
+	a.allocateBatch(ctx, batch)
 	return nil
 }
 
+// helper function creates the batch of servers in the
+// background. A batch with a single server is created
+// individually.
+func (a *allocator) allocateBatch(ctx context.Context, servers []*autoscaler.Server) {
+	if len(servers) == 0 {
+		return
+	}
+	a.wg.Add(1)
+	go func(servers []*autoscaler.Server) {
+		if len(servers) == 1 {
+			a.allocate(ctx, servers[0])
+		} else {
+			a.allocateMany(ctx, servers)
+		}
+		a.wg.Done()
+	}(servers)
+}
+
 func (a *allocator) allocate(ctx context.Context, server *autoscaler.Server) error {
 	logger := logger.FromContext(ctx)
 	defer func() {
@@ -61,6 +107,12 @@ func (a *allocator) allocate(ctx context.Context, server *autoscaler.Server) err
 		}
 	}()
 
+	// a pending server with an instance identifier is a
+	// hibernated server that was resumed by the planner.
+	if a.hibernator != nil && server.ID != "" {
+		return a.resume(ctx, server)
+	}
+
 	ca, err := certs.GenerateCA()
 	if err != nil {
 		return err
@@ -112,9 +164,14 @@ func (a *allocator) allocate(ctx context.Context, server *autoscaler.Server) err
 		server.TLSCert = opts.TLSCert
 		server.TLSKey = opts.TLSKey
 		server.Started = time.Now().Unix()
+		copyMetadata(server, instance)
 	}
 
-	err = a.servers.Update(ctx, server)
+	err = update(ctx, a.servers, server, autoscaler.StateCreating)
+	if err == autoscaler.ErrStateConflict {
+		a.merge(ctx, server)
+		return err
+	}
 	if err != nil {
 		a.metrics.IncrServerCreateError()
 		logger.WithError(err).
@@ -125,3 +182,224 @@ func (a *allocator) allocate(ctx context.Context, server *autoscaler.Server) err
 
 	return nil
 }
+
+// helper function starts a hibernated server. The server
+// certificates are retained when the server is stopped, and
+// are re-used when the server is started.
+func (a *allocator) resume(ctx context.Context, server *autoscaler.Server) error {
+	logger := logger.FromContext(ctx).
+		WithField("server", server.Name)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Hour)
+	defer cancel()
+
+	instance, err := a.hibernator.Start(ctx, &autoscaler.Instance{
+		ID:       server.ID,
+		Provider: server.Provider,
+		Name:     server.Name,
+		Address:  server.Address,
+		Region:   server.Region,
+		Image:    server.Image,
+		Size:     server.Size,
+		Metadata: server.Labels,
+	})
+	if err != nil {
+		logger.WithError(err).
+			Errorln("failed to start server")
+
+		server.Error = err.Error()
+		server.State = autoscaler.StateError
+	} else {
+		logger.Debugln("started server")
+
+		server.State = autoscaler.StateCreated
+	}
+
+	if instance != nil {
+		server.Address = instance.Address
+		server.Started = time.Now().Unix()
+		copyMetadata(server, instance)
+	}
+
+	// the instance identifier of a hibernated server is
+	// already saved, and is destroyed by the collector if
+	// the server state changed while the server was starting.
+	err = update(ctx, a.servers, server, autoscaler.StateCreating)
+	if err != nil && err != autoscaler.ErrStateConflict {
+		logger.WithError(err).
+			Errorln("failed to update server state")
+	}
+	return err
+}
+
+// helper function creates the servers with a single batch
+// request. Each server has its own certificates, so that the
+// certificates of one server cannot be used to access the
+// other servers in the batch.
+func (a *allocator) allocateMany(ctx context.Context, servers []*autoscaler.Server) error {
+	logger := logger.FromContext(ctx).
+		WithField("count", len(servers))
+	defer func() {
+		if err := recover(); err != nil {
+			logger.WithError(err.(error)).
+				Errorln("unexpected panic")
+		}
+	}()
+
+	var opts []autoscaler.InstanceCreateOpts
+	for _, server := range servers {
+		ca, err := certs.GenerateCA()
+		if err != nil {
+			return err
+		}
+
+		cert, err := certs.GenerateCert(server.Name, ca)
+		if err != nil {
+			return err
+		}
+
+		opts = append(opts, autoscaler.InstanceCreateOpts{
+			Name:    server.Name,
+			CAKey:   ca.Key,
+			CACert:  ca.Cert,
+			TLSKey:  cert.Key,
+			TLSCert: cert.Cert,
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Hour)
+	defer cancel()
+
+	start := time.Now()
+	instances, err := a.batch.BatchCreate(ctx, opts)
+	if err != nil {
+		logger.WithError(err).
+			Errorln("failed to provision server batch")
+	}
+
+	// the servers created individually return an error for
+	// each server. Otherwise the batch error applies to the
+	// servers that were not created.
+	errs := make([]error, len(servers))
+	var batchErr autoscaler.BatchError
+	if errors.As(err, &batchErr) {
+		copy(errs, batchErr)
+	}
+
+	for i, server := range servers {
+		var instance *autoscaler.Instance
+		if i < len(instances) {
+			instance = instances[i]
+		}
+		if instance == nil && errs[i] == nil {
+			errs[i] = errNotCreated
+			if err != nil && batchErr == nil {
+				errs[i] = err
+			}
+		}
+
+		if errs[i] != nil {
+			a.metrics.IncrServerCreateError()
+			logger.WithError(errs[i]).
+				WithField("server", server.Name).
+				Errorln("failed to provision server")
+
+			server.Error = errs[i].Error()
+			server.State = autoscaler.StateError
+		} else {
+			a.metrics.TrackServerCreateTime(start)
+			logger.WithField("server", server.Name).
+				Debugln("provisioned server")
+
+			server.State = autoscaler.StateCreated
+		}
+
+		if instance != nil {
+			server.ID = instance.ID
+			server.Address = instance.Address
+			server.Image = instance.Image
+			server.Provider = instance.Provider
+			server.Region = instance.Region
+			server.Size = instance.Size
+			server.CACert = opts[i].CACert
+			server.CAKey = opts[i].CAKey
+			server.TLSCert = opts[i].TLSCert
+			server.TLSKey = opts[i].TLSKey
+			server.Started = time.Now().Unix()
+			copyMetadata(server, instance)
+		}
+
+		err := update(ctx, a.servers, server, autoscaler.StateCreating)
+		if err == autoscaler.ErrStateConflict {
+			a.merge(ctx, server)
+		} else if err != nil {
+			a.metrics.IncrServerCreateError()
+			logger.WithError(err).
+				WithField("server", server.Name).
+				Errorln("failed to update server state")
+		}
+	}
+	return nil
+}
+
+// helper function saves the instance details of a server
+// that changed state while the server was being created, for
+// example a server that was shutdown using the api. The
+// current server state is retained, so that the instance is
+// destroyed by the collector instead of being leaked. A server
+// that was already stopped is shutdown again.
+func (a *allocator) merge(ctx context.Context, server *autoscaler.Server) error {
+	if server.ID == "" {
+		return nil
+	}
+
+	logger := logger.FromContext(ctx).
+		WithField("server", server.Name)
+
+	current, err := a.servers.Find(ctx, server.Name)
+	if err != nil {
+		logger.WithError(err).
+			Errorln("cannot find server")
+		return err
+	}
+
+	from := current.State
+	if current.State == autoscaler.StateStopped {
+		current.State = autoscaler.StateShutdown
+	}
+	current.ID = server.ID
+	current.Address = server.Address
+	current.Image = server.Image
+	current.Provider = server.Provider
+	current.Region = server.Region
+	current.Size = server.Size
+	current.CACert = server.CACert
+	current.CAKey = server.CAKey
+	current.TLSCert = server.TLSCert
+	current.TLSKey = server.TLSKey
+	current.Started = server.Started
+	current.Labels = server.Labels
+
+	err = update(ctx, a.servers, current, from)
+	if err != nil {
+		logger.WithError(err).
+			WithField("id", server.ID).
+			Errorln("cannot save the instance details")
+		return err
+	}
+	return nil
+}
+
+// helper function copies the instance metadata to the
+// server labels.
+func copyMetadata(server *autoscaler.Server, instance *autoscaler.Instance) {
+	if len(instance.Metadata) == 0 {
+		return
+	}
+	if server.Labels == nil {
+		server.Labels = autoscaler.Labels{}
+	}
+	for key, value := range instance.Metadata {
+		server.Labels[key] = value
+	}
+}
@@ -5,6 +5,7 @@
 package engine
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"testing"
@@ -21,15 +22,17 @@ func TestAllocate(t *testing.T) {
 	defer controller.Finish()
 
 	mockctx := context.Background()
-	mockInstance := &autoscaler.Instance{}
+	mockInstance := &autoscaler.Instance{
+		Metadata: map[string]string{"subnet": "subnet-0ab1c2d3"},
+	}
 	mockServers := []*autoscaler.Server{
 		{State: autoscaler.StatePending},
 	}
 
 	store := mocks.NewMockServerStore(controller)
 	store.EXPECT().ListState(mockctx, autoscaler.StatePending).Return(mockServers, nil)
-	store.EXPECT().Update(mockctx, mockServers[0]).Return(nil)
-	store.EXPECT().Update(gomock.Any(), mockServers[0]).Return(nil)
+	store.EXPECT().Transition(mockctx, mockServers[0].Name, autoscaler.StatePending, autoscaler.StateCreating).Return(nil)
+	store.EXPECT().CompareAndUpdate(gomock.Any(), mockServers[0], autoscaler.StateCreating).Return(nil)
 
 	provider := mocks.NewMockProvider(controller)
 	provider.EXPECT().Create(gomock.Any(), gomock.Any()).Return(mockInstance, nil)
@@ -44,6 +47,9 @@ func TestAllocate(t *testing.T) {
 	if got, want := mockServers[0].State, autoscaler.StateCreated; got != want {
 		t.Errorf("Want server state Created, got %v", got)
 	}
+	if got, want := mockServers[0].Labels["subnet"], "subnet-0ab1c2d3"; got != want {
+		t.Errorf("Want server label subnet %q, got %q", want, got)
+	}
 }
 
 func TestAllocate_ServerCreateError(t *testing.T) {
@@ -58,8 +64,8 @@ func TestAllocate_ServerCreateError(t *testing.T) {
 
 	store := mocks.NewMockServerStore(controller)
 	store.EXPECT().ListState(mockctx, autoscaler.StatePending).Return(mockServers, nil)
-	store.EXPECT().Update(mockctx, mockServers[0]).Return(nil)
-	store.EXPECT().Update(gomock.Any(), mockServers[0]).Return(nil)
+	store.EXPECT().Transition(mockctx, mockServers[0].Name, autoscaler.StatePending, autoscaler.StateCreating).Return(nil)
+	store.EXPECT().CompareAndUpdate(gomock.Any(), mockServers[0], autoscaler.StateCreating).Return(nil)
 
 	provider := mocks.NewMockProvider(controller)
 	provider.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil, mockerr)
@@ -101,13 +107,257 @@ func TestAllocate_ServerUpdateError(t *testing.T) {
 
 	store := mocks.NewMockServerStore(controller)
 	store.EXPECT().ListState(mockctx, autoscaler.StatePending).Return(mockServers, nil)
-	store.EXPECT().Update(mockctx, mockServers[0]).Return(mockerr)
+	store.EXPECT().Transition(mockctx, mockServers[0].Name, autoscaler.StatePending, autoscaler.StateCreating).Return(mockerr)
 
 	a := allocator{servers: store, metrics: &metrics.NopCollector{}}
 	if got, want := a.Allocate(mockctx), mockerr; got != want {
 		t.Errorf("Want error updating server")
 	}
-	if got, want := mockServers[0].State, autoscaler.StateCreating; got != want {
-		t.Errorf("Want server state Staging, got %v", got)
+	if got, want := mockServers[0].State, autoscaler.StatePending; got != want {
+		t.Errorf("Want server state Pending, got %v", got)
+	}
+}
+
+// This test verifies that a server is skipped, and not
+// created, if the server state was changed concurrently.
+func TestAllocate_StateConflict(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockctx := context.Background()
+	mockServers := []*autoscaler.Server{
+		{Name: "agent-1", State: autoscaler.StatePending},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().ListState(mockctx, autoscaler.StatePending).Return(mockServers, nil)
+	store.EXPECT().Transition(mockctx, "agent-1", autoscaler.StatePending, autoscaler.StateCreating).Return(autoscaler.ErrStateConflict)
+
+	// the provider must not create a server when the
+	// server state was changed concurrently.
+	provider := mocks.NewMockProvider(controller)
+
+	a := allocator{servers: store, provider: provider, metrics: &metrics.NopCollector{}}
+	err := a.Allocate(mockctx)
+	a.wg.Wait()
+
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := mockServers[0].State, autoscaler.StatePending; got != want {
+		t.Errorf("Want server state Pending, got %v", got)
+	}
+}
+
+// This test verifies that a server shutdown while the server
+// is being created is not overwritten, and that the instance
+// details are saved so that the instance is destroyed.
+func TestAllocate_ShutdownWhileCreating(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockctx := context.Background()
+	mockInstance := &autoscaler.Instance{ID: "i-1234", Address: "1.2.3.4"}
+	mockServers := []*autoscaler.Server{
+		{Name: "agent-1", State: autoscaler.StatePending},
+	}
+	mockCurrent := &autoscaler.Server{Name: "agent-1", State: autoscaler.StateShutdown}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().ListState(mockctx, autoscaler.StatePending).Return(mockServers, nil)
+	store.EXPECT().Transition(mockctx, "agent-1", autoscaler.StatePending, autoscaler.StateCreating).Return(nil)
+	store.EXPECT().CompareAndUpdate(gomock.Any(), mockServers[0], autoscaler.StateCreating).Return(autoscaler.ErrStateConflict)
+	store.EXPECT().Find(gomock.Any(), "agent-1").Return(mockCurrent, nil)
+	store.EXPECT().CompareAndUpdate(gomock.Any(), mockCurrent, autoscaler.StateShutdown).Return(nil)
+
+	provider := mocks.NewMockProvider(controller)
+	provider.EXPECT().Create(gomock.Any(), gomock.Any()).Return(mockInstance, nil)
+
+	a := allocator{servers: store, provider: provider, metrics: &metrics.NopCollector{}}
+	a.Allocate(mockctx)
+	a.wg.Wait()
+
+	if got, want := mockCurrent.State, autoscaler.StateShutdown; got != want {
+		t.Errorf("Want server state Shutdown, got %v", got)
+	}
+	if got, want := mockCurrent.ID, "i-1234"; got != want {
+		t.Errorf("Want server id %s, got %s", want, got)
+	}
+}
+
+func TestAllocate_Resume(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockctx := context.Background()
+	mockInstance := &autoscaler.Instance{ID: "i-1234", Address: "1.2.3.4"}
+	mockServers := []*autoscaler.Server{
+		{ID: "i-1234", State: autoscaler.StatePending, TLSCert: []byte("cert")},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().ListState(mockctx, autoscaler.StatePending).Return(mockServers, nil)
+	store.EXPECT().Transition(mockctx, mockServers[0].Name, autoscaler.StatePending, autoscaler.StateCreating).Return(nil)
+	store.EXPECT().CompareAndUpdate(gomock.Any(), mockServers[0], autoscaler.StateCreating).Return(nil)
+
+	// the provider must not create a new server when
+	// a hibernated server is resumed.
+	provider := mocks.NewMockProvider(controller)
+
+	hibernator := mocks.NewMockHibernator(controller)
+	hibernator.EXPECT().Start(gomock.Any(), gomock.Any()).Return(mockInstance, nil)
+
+	a := allocator{servers: store, provider: provider, hibernator: hibernator, metrics: &metrics.NopCollector{}}
+	err := a.Allocate(mockctx)
+	a.wg.Wait()
+
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := mockServers[0].State, autoscaler.StateCreated; got != want {
+		t.Errorf("Want server state Created, got %v", got)
+	}
+	if got, want := mockServers[0].Address, "1.2.3.4"; got != want {
+		t.Errorf("Want server address %s, got %s", want, got)
+	}
+	if got, want := string(mockServers[0].TLSCert), "cert"; got != want {
+		t.Errorf("Want server certificate retained")
+	}
+}
+
+func TestAllocate_Batch(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockctx := context.Background()
+	mockServers := []*autoscaler.Server{
+		{Name: "agent-1", State: autoscaler.StatePending},
+		{Name: "agent-2", State: autoscaler.StatePending},
+		{Name: "agent-3", State: autoscaler.StatePending},
+	}
+	mockInstances := []*autoscaler.Instance{
+		{ID: "i-1", Address: "1.1.1.1"},
+		nil, // not created
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().ListState(mockctx, autoscaler.StatePending).Return(mockServers, nil)
+	store.EXPECT().Transition(gomock.Any(), gomock.Any(), autoscaler.StatePending, autoscaler.StateCreating).Return(nil).Times(3)
+	store.EXPECT().CompareAndUpdate(gomock.Any(), gomock.Any(), autoscaler.StateCreating).Return(nil).Times(3)
+
+	// the third server exceeds the batch size, and is
+	// created individually.
+	provider := mocks.NewMockProvider(controller)
+	provider.EXPECT().Create(gomock.Any(), gomock.Any()).Return(&autoscaler.Instance{ID: "i-3"}, nil)
+
+	var batchOpts []autoscaler.InstanceCreateOpts
+	batch := mocks.NewMockBatchCreator(controller)
+	batch.EXPECT().BatchCreate(gomock.Any(), gomock.Any()).Do(func(_ context.Context, opts []autoscaler.InstanceCreateOpts) {
+		batchOpts = opts
+	}).Return(mockInstances, errors.New("mock error"))
+
+	a := allocator{
+		servers:   store,
+		provider:  provider,
+		batch:     batch,
+		batchSize: 2,
+		metrics:   &metrics.NopCollector{},
+	}
+	err := a.Allocate(mockctx)
+	a.wg.Wait()
+
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := mockServers[0].State, autoscaler.StateCreated; got != want {
+		t.Errorf("Want server state Created, got %v", got)
+	}
+	if got, want := mockServers[0].ID, "i-1"; got != want {
+		t.Errorf("Want server id %s, got %s", want, got)
+	}
+	if got, want := mockServers[1].State, autoscaler.StateError; got != want {
+		t.Errorf("Want server state Error, got %v", got)
+	}
+	if got, want := mockServers[2].State, autoscaler.StateCreated; got != want {
+		t.Errorf("Want server state Created, got %v", got)
+	}
+	if got, want := len(batchOpts), 2; got != want {
+		t.Errorf("Want %d servers in batch, got %d", want, got)
+		return
+	}
+	// each server in the batch must have its own certificates.
+	if bytes.Equal(batchOpts[0].CAKey, batchOpts[1].CAKey) {
+		t.Errorf("Want a certificate authority per server")
+	}
+	if bytes.Equal(batchOpts[0].TLSKey, batchOpts[1].TLSKey) {
+		t.Errorf("Want a tls key per server")
+	}
+	if got, want := string(mockServers[0].TLSKey), string(batchOpts[0].TLSKey); got != want {
+		t.Errorf("Want server tls key saved")
+	}
+}
+
+// This test verifies that servers created individually in a
+// batch are saved in the error state with their own error,
+// including servers that return an instance with an error.
+func TestAllocate_BatchError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockctx := context.Background()
+	mockServers := []*autoscaler.Server{
+		{Name: "agent-1", State: autoscaler.StatePending},
+		{Name: "agent-2", State: autoscaler.StatePending},
+		{Name: "agent-3", State: autoscaler.StatePending},
+	}
+	mockInstances := []*autoscaler.Instance{
+		{ID: "i-1", Address: "1.1.1.1"},
+		{ID: "i-2"}, // created with error
+		nil,         // not created
+	}
+	mockErrs := autoscaler.BatchError{
+		nil,
+		errors.New("provisioning failed"),
+		errors.New("quota exceeded"),
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().ListState(mockctx, autoscaler.StatePending).Return(mockServers, nil)
+	store.EXPECT().Transition(gomock.Any(), gomock.Any(), autoscaler.StatePending, autoscaler.StateCreating).Return(nil).Times(3)
+	store.EXPECT().CompareAndUpdate(gomock.Any(), gomock.Any(), autoscaler.StateCreating).Return(nil).Times(3)
+
+	batch := mocks.NewMockBatchCreator(controller)
+	batch.EXPECT().BatchCreate(gomock.Any(), gomock.Any()).Return(mockInstances, mockErrs)
+
+	a := allocator{
+		servers:   store,
+		provider:  mocks.NewMockProvider(controller),
+		batch:     batch,
+		batchSize: 3,
+		metrics:   &metrics.NopCollector{},
+	}
+	err := a.Allocate(mockctx)
+	a.wg.Wait()
+
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := mockServers[0].State, autoscaler.StateCreated; got != want {
+		t.Errorf("Want server state Created, got %v", got)
+	}
+	if got, want := mockServers[1].State, autoscaler.StateError; got != want {
+		t.Errorf("Want server state Error, got %v", got)
+	}
+	if got, want := mockServers[1].ID, "i-2"; got != want {
+		t.Errorf("Want server id %s saved, got %s", want, got)
+	}
+	if got, want := mockServers[1].Error, "provisioning failed"; got != want {
+		t.Errorf("Want server error %q, got %q", want, got)
+	}
+	if got, want := mockServers[2].State, autoscaler.StateError; got != want {
+		t.Errorf("Want server state Error, got %v", got)
+	}
+	if got, want := mockServers[2].Error, "quota exceeded"; got != want {
+		t.Errorf("Want server error %q, got %q", want, got)
 	}
 }
@@ -0,0 +1,126 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package engine
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drone/autoscaler"
+)
+
+// a backoff defers the allocation of servers after servers
+// fail with the same reason, which prevents a broken image or
+// configuration from causing a create and fail storm. The
+// allocation is deferred exponentially for each failure, and
+// is stopped when the retry budget is exhausted. Failures are
+// forgotten after the maximum backoff.
+type backoff struct {
+	mu sync.Mutex
+
+	base   time.Duration // initial backoff
+	max    time.Duration // maximum backoff
+	budget int           // failures before allocation stops
+
+	until  time.Time
+	reason string
+}
+
+// Update computes the backoff from the servers in the error
+// state, grouped by normalized failure reason. Adopted orphaned
+// servers are not failures, and are ignored.
+func (b *backoff) Update(errored []*autoscaler.Server, now time.Time) {
+	failures := map[string]int{}
+	latest := map[string]time.Time{}
+	for _, server := range errored {
+		if server.Error == adoptedReason {
+			continue
+		}
+		updated := time.Unix(server.Updated, 0)
+		if now.Sub(updated) >= b.max {
+			continue
+		}
+		reason := failureReason(server)
+		failures[reason]++
+		if updated.After(latest[reason]) {
+			latest[reason] = updated
+		}
+	}
+
+	var until time.Time
+	var reason string
+	for key, count := range failures {
+		next := latest[key].Add(b.delay(count))
+		if b.budget > 0 && count >= b.budget {
+			// the retry budget is exhausted, and allocation
+			// is stopped until the failures are forgotten.
+			next = latest[key].Add(b.max)
+		}
+		if next.After(until) {
+			until = next
+			reason = key
+		}
+	}
+
+	b.mu.Lock()
+	b.until = until
+	b.reason = reason
+	b.mu.Unlock()
+}
+
+// Deferred returns true and the failure reason if server
+// allocation is deferred.
+func (b *backoff) Deferred(now time.Time) (string, bool) {
+	if b == nil {
+		return "", false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.reason, now.Before(b.until)
+}
+
+// helper function returns the exponential backoff for the
+// number of failures, capped at the maximum backoff.
+func (b *backoff) delay(failures int) time.Duration {
+	delay := b.base
+	for i := 1; i < failures && delay < b.max; i++ {
+		delay = delay * 2
+	}
+	if delay > b.max {
+		delay = b.max
+	}
+	return delay
+}
+
+var (
+	// matches quoted values, such as resource names.
+	failureQuoted = regexp.MustCompile(`"[^"]*"|'[^']*'|\[[^\]]*\]`)
+
+	// matches words that contain digits, such as request
+	// identifiers, instance identifiers and addresses.
+	failureIdent = regexp.MustCompile(`[\w.:/-]*\d[\w.:/-]*`)
+)
+
+// helper function returns the failure reason of the server
+// with values that are unique to the server or the request
+// removed, so that servers that fail for the same reason are
+// grouped, even if the provider errors are not identical.
+func failureReason(server *autoscaler.Server) string {
+	reason := server.Error
+	// provider errors often include the request details,
+	// such as the status code and request identifier, on
+	// subsequent lines.
+	if i := strings.IndexByte(reason, '\n'); i != -1 {
+		reason = reason[:i]
+	}
+	if server.Name != "" {
+		reason = strings.Replace(reason, server.Name, "*", -1)
+	}
+	reason = failureQuoted.ReplaceAllString(reason, "*")
+	reason = failureIdent.ReplaceAllString(reason, "*")
+	return strings.Join(strings.Fields(reason), " ")
+}
@@ -0,0 +1,160 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/drone/autoscaler"
+)
+
+func TestBackoff(t *testing.T) {
+	now := time.Now()
+	b := &backoff{
+		base:   time.Minute,
+		max:    time.Hour,
+		budget: 5,
+	}
+
+	// a single failure defers allocation by the base backoff.
+	b.Update([]*autoscaler.Server{
+		{Error: "image not found", Updated: now.Add(-time.Second * 30).Unix()},
+	}, now)
+	if reason, ok := b.Deferred(now); !ok || reason != "image not found" {
+		t.Errorf("Want allocation deferred after failure")
+	}
+	if _, ok := b.Deferred(now.Add(time.Minute)); ok {
+		t.Errorf("Want allocation resumed after backoff")
+	}
+
+	// three failures with the same reason defer allocation
+	// by four times the base backoff.
+	b.Update([]*autoscaler.Server{
+		{Error: "image not found", Updated: now.Add(-time.Minute * 10).Unix()},
+		{Error: "image not found", Updated: now.Add(-time.Minute * 5).Unix()},
+		{Error: "image not found", Updated: now.Add(-time.Minute * 3).Unix()},
+		{Error: "quota exceeded", Updated: now.Add(-time.Minute * 3).Unix()},
+	}, now)
+	if _, ok := b.Deferred(now); !ok {
+		t.Errorf("Want allocation deferred after failures")
+	}
+	if _, ok := b.Deferred(now.Add(time.Minute * 2)); ok {
+		t.Errorf("Want allocation resumed after backoff")
+	}
+
+	// failures older than the max backoff are forgotten.
+	b.Update([]*autoscaler.Server{
+		{Error: "image not found", Updated: now.Add(-time.Hour * 2).Unix()},
+	}, now)
+	if _, ok := b.Deferred(now); ok {
+		t.Errorf("Want old failures ignored")
+	}
+}
+
+func TestBackoff_Budget(t *testing.T) {
+	now := time.Now()
+	b := &backoff{
+		base:   time.Second,
+		max:    time.Hour,
+		budget: 3,
+	}
+
+	var errored []*autoscaler.Server
+	for i := 0; i < 3; i++ {
+		errored = append(errored, &autoscaler.Server{
+			Error:   "image not found",
+			Updated: now.Add(-time.Minute).Unix(),
+		})
+	}
+	b.Update(errored, now)
+	if _, ok := b.Deferred(now.Add(time.Minute * 30)); !ok {
+		t.Errorf("Want allocation stopped when the retry budget is exhausted")
+	}
+	if _, ok := b.Deferred(now.Add(time.Hour)); ok {
+		t.Errorf("Want allocation resumed when the failures are forgotten")
+	}
+}
+
+// This test verifies that adopted orphaned servers do not
+// count toward the retry budget.
+func TestBackoff_Adopted(t *testing.T) {
+	now := time.Now()
+	b := &backoff{
+		base:   time.Minute,
+		max:    time.Hour,
+		budget: 2,
+	}
+	b.Update([]*autoscaler.Server{
+		{Name: "agent-1", Error: adoptedReason, Updated: now.Unix()},
+		{Name: "agent-2", Error: adoptedReason, Updated: now.Unix()},
+		{Name: "agent-3", Error: adoptedReason, Updated: now.Unix()},
+	}, now)
+	if _, ok := b.Deferred(now); ok {
+		t.Errorf("Want adopted servers ignored")
+	}
+}
+
+func TestBackoff_Nil(t *testing.T) {
+	var b *backoff
+	if _, ok := b.Deferred(time.Now()); ok {
+		t.Errorf("Want allocation not deferred when backoff disabled")
+	}
+}
+
+// This test verifies that provider errors that differ only by
+// request identifiers and resource names are grouped, so that
+// the backoff increases with each failure.
+func TestBackoff_DistinctErrors(t *testing.T) {
+	now := time.Now()
+	b := &backoff{
+		base:   time.Minute,
+		max:    time.Hour,
+		budget: 3,
+	}
+
+	b.Update([]*autoscaler.Server{
+		{
+			Name:    "agent-tvxkqwmz",
+			Error:   "InvalidAMIID.NotFound: The image id '[ami-0b5eea76982371e91]' does not exist\n\tstatus code: 400, request id: 5f3c6a2e-8d2b-4d1a-9c7e-2a6f1b0d4e11",
+			Updated: now.Add(-time.Second * 90).Unix(),
+		},
+		{
+			Name:    "agent-pqrsabcd",
+			Error:   "InvalidAMIID.NotFound: The image id '[ami-0b5eea76982371e91]' does not exist\n\tstatus code: 400, request id: 0a9e1c4d-77f0-4b8e-bf52-91d3c6e8a7b2",
+			Updated: now.Add(-time.Second * 30).Unix(),
+		},
+		{
+			Name:    "agent-zxcvbnmq",
+			Error:   "cannot create instance agent-zxcvbnmq in subnet subnet-0ab1c2d3: InvalidAMIID.NotFound",
+			Updated: now.Add(-time.Minute).Unix(),
+		},
+	}, now)
+
+	reason, ok := b.Deferred(now)
+	if !ok {
+		t.Errorf("Want allocation deferred after failures")
+	}
+	if got, want := reason, "InvalidAMIID.NotFound: The image id * does not exist"; got != want {
+		t.Errorf("Want failure reason %q, got %q", want, got)
+	}
+	// two failures with the same reason defer allocation by
+	// twice the base backoff.
+	if _, ok := b.Deferred(now.Add(time.Minute)); !ok {
+		t.Errorf("Want failures grouped by normalized reason")
+	}
+
+	b.Update([]*autoscaler.Server{
+		{Name: "agent-1", Error: "googleapi: Error 403: Quota 'CPUS' exceeded. Limit: 24.0 in region us-central1., quotaExceeded", Updated: now.Add(-time.Minute * 3).Unix()},
+		{Name: "agent-2", Error: "googleapi: Error 403: Quota 'CPUS' exceeded. Limit: 32.0 in region europe-west4., quotaExceeded", Updated: now.Add(-time.Minute * 2).Unix()},
+		{Name: "agent-3", Error: "googleapi: Error 403: Quota 'IN_USE_ADDRESSES' exceeded. Limit: 8.0 in region us-east1., quotaExceeded", Updated: now.Add(-time.Minute).Unix()},
+	}, now)
+
+	// the retry budget is exhausted, and allocation is
+	// stopped until the failures are forgotten.
+	if _, ok := b.Deferred(now.Add(time.Minute * 30)); !ok {
+		t.Errorf("Want allocation stopped after the retry budget is exhausted")
+	}
+}
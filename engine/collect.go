@@ -12,6 +12,7 @@ import (
 	"github.com/docker/docker/api/types/container"
 	"github.com/drone/autoscaler"
 	"github.com/drone/autoscaler/logger"
+	"github.com/drone/drone-go/drone"
 )
 
 type collector struct {
@@ -21,6 +22,11 @@ type collector struct {
 	servers  autoscaler.ServerStore
 	provider autoscaler.Provider
 	client   clientFunc
+
+	// the drone client is used to drain servers before
+	// they are stopped. Servers are not drained if nil.
+	drone        drone.Client
+	drainTimeout time.Duration
 }
 
 func (c *collector) Collect(ctx context.Context) error {
@@ -31,9 +37,18 @@ func (c *collector) Collect(ctx context.Context) error {
 		return err
 	}
 
+	if c.drone != nil {
+		servers, err = c.drain(ctx, servers)
+		if err != nil {
+			return err
+		}
+	}
+
 	for _, server := range servers {
-		server.State = autoscaler.StateStopping
-		err = c.servers.Update(ctx, server)
+		err = transition(ctx, c.servers, server, autoscaler.StateStopping)
+		if err == autoscaler.ErrStateConflict {
+			continue
+		}
 		if err != nil {
 			logger.WithError(err).
 				WithField("server", server.Name).
@@ -74,7 +89,10 @@ func (c *collector) collect(ctx context.Context, server *autoscaler.Server) erro
 		server.Stopped = time.Now().Unix()
 		server.State = autoscaler.StateStopped
 
-		err := c.servers.Update(ctx, server)
+		err := update(ctx, c.servers, server, autoscaler.StateStopping)
+		if err == autoscaler.ErrStateConflict {
+			return err
+		}
 		if err != nil {
 			logger.WithError(err).
 				WithField("server", server.Name).
@@ -132,6 +150,7 @@ func (c *collector) collect(ctx context.Context, server *autoscaler.Server) erro
 		Region:   server.Region,
 		Image:    server.Image,
 		Size:     server.Size,
+		Metadata: server.Labels,
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, time.Hour)
@@ -160,7 +179,10 @@ func (c *collector) collect(ctx context.Context, server *autoscaler.Server) erro
 		server.State = autoscaler.StateStopped
 	}
 
-	err = c.servers.Update(ctx, server)
+	err = update(ctx, c.servers, server, autoscaler.StateStopping)
+	if err == autoscaler.ErrStateConflict {
+		return err
+	}
 	if err != nil {
 		logger.WithError(err).
 			WithField("server", server.Name).
@@ -35,8 +35,8 @@ func TestCollect(t *testing.T) {
 
 	store := mocks.NewMockServerStore(controller)
 	store.EXPECT().ListState(mockctx, autoscaler.StateShutdown).Return(mockServers, nil)
-	store.EXPECT().Update(mockctx, mockServers[0]).Return(nil)
-	store.EXPECT().Update(gomock.Any(), mockServers[0]).Return(nil)
+	store.EXPECT().Transition(mockctx, mockServers[0].Name, autoscaler.StateShutdown, autoscaler.StateStopping).Return(nil)
+	store.EXPECT().CompareAndUpdate(gomock.Any(), mockServers[0], autoscaler.StateStopping).Return(nil)
 
 	provider := mocks.NewMockProvider(controller)
 	provider.EXPECT().Destroy(gomock.Any(), gomock.Any()).Return(nil)
@@ -78,8 +78,8 @@ func TestCollect_DockerStopError(t *testing.T) {
 
 	store := mocks.NewMockServerStore(controller)
 	store.EXPECT().ListState(mockctx, autoscaler.StateShutdown).Return(mockServers, nil)
-	store.EXPECT().Update(mockctx, mockServers[0]).Return(nil)
-	store.EXPECT().Update(gomock.Any(), mockServers[0]).Return(nil)
+	store.EXPECT().Transition(mockctx, mockServers[0].Name, autoscaler.StateShutdown, autoscaler.StateStopping).Return(nil)
+	store.EXPECT().CompareAndUpdate(gomock.Any(), mockServers[0], autoscaler.StateStopping).Return(nil)
 
 	provider := mocks.NewMockProvider(controller)
 	provider.EXPECT().Destroy(gomock.Any(), gomock.Any()).Return(nil)
@@ -122,8 +122,8 @@ func TestCollect_ServerDestroyError(t *testing.T) {
 
 	store := mocks.NewMockServerStore(controller)
 	store.EXPECT().ListState(mockctx, autoscaler.StateShutdown).Return(mockServers, nil)
-	store.EXPECT().Update(mockctx, mockServers[0]).Return(nil)
-	store.EXPECT().Update(gomock.Any(), mockServers[0]).Return(nil)
+	store.EXPECT().Transition(mockctx, mockServers[0].Name, autoscaler.StateShutdown, autoscaler.StateStopping).Return(nil)
+	store.EXPECT().CompareAndUpdate(gomock.Any(), mockServers[0], autoscaler.StateStopping).Return(nil)
 
 	provider := mocks.NewMockProvider(controller)
 	provider.EXPECT().Destroy(gomock.Any(), gomock.Any()).Return(mockerr)
@@ -175,14 +175,14 @@ func TestCollect_ServerUpdateError(t *testing.T) {
 
 	store := mocks.NewMockServerStore(controller)
 	store.EXPECT().ListState(mockctx, autoscaler.StateShutdown).Return(mockServers, nil)
-	store.EXPECT().Update(mockctx, mockServers[0]).Return(mockerr)
+	store.EXPECT().Transition(mockctx, mockServers[0].Name, autoscaler.StateShutdown, autoscaler.StateStopping).Return(mockerr)
 
 	c := collector{servers: store}
 	if got, want := c.Collect(mockctx), mockerr; got != want {
 		t.Errorf("Want error updating server")
 	}
-	if got, want := mockServers[0].State, autoscaler.StateStopping; got != want {
-		t.Errorf("Want server state Stopping, got %v", got)
+	if got, want := mockServers[0].State, autoscaler.StateShutdown; got != want {
+		t.Errorf("Want server state Shutdown, got %v", got)
 	}
 }
 
@@ -197,7 +197,7 @@ func TestCollect_ServerNeverProvisioned(t *testing.T) {
 	}
 
 	store := mocks.NewMockServerStore(controller)
-	store.EXPECT().Update(gomock.Any(), mockServer).Return(nil).Times(1)
+	store.EXPECT().CompareAndUpdate(gomock.Any(), mockServer, autoscaler.StateStopping).Return(nil).Times(1)
 
 	c := collector{servers: store}
 	if err := c.collect(mockctx, mockServer); err != nil {
@@ -0,0 +1,191 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package engine
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/logger"
+	"github.com/drone/drone-go/drone"
+)
+
+// helper function drains the servers scheduled for shutdown
+// and returns the drained servers that are ready to stop.
+// The agent on each server is restarted with zero capacity,
+// so that it no longer accepts stages, and the server is
+// drained once the queue has no stages for the server. This
+// prevents stages from being scheduled onto a server that is
+// about to be destroyed.
+func (c *collector) drain(ctx context.Context, servers []*autoscaler.Server) ([]*autoscaler.Server, error) {
+	logger := logger.FromContext(ctx)
+
+	var drained []*autoscaler.Server
+	paused := map[string]struct{}{}
+	for _, server := range servers {
+		// if the server was never created there is no
+		// agent to drain.
+		if server.ID == "" || server.Address == "" {
+			drained = append(drained, server)
+			continue
+		}
+
+		err := transition(ctx, c.servers, server, autoscaler.StateDraining)
+		if err == autoscaler.ErrStateConflict {
+			continue
+		}
+		if err != nil {
+			logger.WithError(err).
+				WithField("server", server.Name).
+				WithField("state", "draining").
+				Errorln("failed to update server state")
+			return nil, err
+		}
+		paused[server.Name] = struct{}{}
+
+		// the agent is stopped gracefully, which means
+		// restarting the agent on a busy server waits for
+		// the running stages to complete, and is therefore
+		// performed in the background.
+		c.wg.Add(1)
+		go func(server *autoscaler.Server) {
+			err := c.pause(ctx, server)
+			if err != nil {
+				logger.WithError(err).
+					WithField("server", server.Name).
+					Warnln("cannot restart the agent with zero capacity")
+			}
+			c.wg.Done()
+		}(server)
+	}
+
+	// the queue is fetched after the agents are paused, to
+	// include stages assigned before the agents were paused.
+	busy, err := c.listBusy()
+	if err != nil {
+		logger.WithError(err).
+			Errorln("cannot ascertain busy server list")
+		return nil, err
+	}
+
+	draining, err := c.servers.ListState(ctx, autoscaler.StateDraining)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, server := range draining {
+		// servers paused in this cycle are drained in a
+		// subsequent cycle, once the agent is restarted and
+		// no longer accepts stages.
+		if _, ok := paused[server.Name]; ok {
+			logger.WithField("server", server.Name).
+				Debugln("server is draining")
+			continue
+		}
+		if _, ok := busy[server.Name]; ok {
+			// the server is drained after the timeout even
+			// if stages are still assigned to the server. The
+			// timeout is measured from the time the server
+			// started draining, which is not reset when the
+			// server flags are updated.
+			started := server.Draining
+			if started == 0 {
+				started = server.Updated
+			}
+			elapsed := time.Since(time.Unix(started, 0))
+			if elapsed < c.drainTimeout {
+				logger.WithField("server", server.Name).
+					Debugln("server is draining")
+				continue
+			}
+			logger.WithField("server", server.Name).
+				WithField("timeout", c.drainTimeout).
+				Warnln("server drain timeout exceeded")
+		}
+		logger.WithField("server", server.Name).
+			Debugln("server is drained")
+		drained = append(drained, server)
+	}
+	return drained, nil
+}
+
+// helper function restarts the agent with zero capacity,
+// which prevents the agent from accepting new stages. The
+// agent is stopped gracefully, so that running stages can
+// complete before the agent is restarted.
+func (c *collector) pause(ctx context.Context, server *autoscaler.Server) error {
+	client, closer, err := c.client(server)
+	if closer != nil {
+		defer closer.Close()
+	}
+	if err != nil {
+		return err
+	}
+
+	info, err := client.ContainerInspect(ctx, "agent")
+	if err != nil {
+		return err
+	}
+
+	config := info.Config
+	config.Env = replaceEnv(config.Env, "DRONE_RUNNER_CAPACITY", "0")
+	if config.Labels == nil {
+		config.Labels = map[string]string{}
+	}
+	config.Labels["io.drone.agent.capacity"] = "0"
+	config.Labels["io.drone.agent.draining"] = "true"
+
+	// 1 minute offset between docker stop timeout and
+	// the context timeout.
+	ctxStop, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	timeoutSeconds := int((c.timeout - time.Minute).Seconds())
+	err = client.ContainerStop(ctxStop, "agent", container.StopOptions{Timeout: &timeoutSeconds})
+	if err != nil {
+		return err
+	}
+	err = client.ContainerRemove(ctx, "agent", container.RemoveOptions{})
+	if err != nil {
+		return err
+	}
+	res, err := client.ContainerCreate(ctx, config, info.HostConfig, nil, nil, "agent")
+	if err != nil {
+		return err
+	}
+	return client.ContainerStart(ctx, res.ID, container.StartOptions{})
+}
+
+// helper function returns the names of servers with running
+// or pending stages.
+func (c *collector) listBusy() (map[string]struct{}, error) {
+	busy := map[string]struct{}{}
+	stages, err := c.drone.Queue()
+	if err != nil {
+		return busy, err
+	}
+	for _, stage := range stages {
+		if stage.Machine == "" {
+			continue
+		}
+		if stage.Status == drone.StatusRunning || stage.Status == drone.StatusPending {
+			busy[stage.Machine] = struct{}{}
+		}
+	}
+	return busy, nil
+}
+
+// helper function replaces the named environment variable.
+func replaceEnv(envs []string, name, value string) []string {
+	var out []string
+	for _, env := range envs {
+		if !strings.HasPrefix(env, name+"=") {
+			out = append(out, env)
+		}
+	}
+	return append(out, name+"="+value)
+}
@@ -0,0 +1,178 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package engine
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/mocks"
+	"github.com/drone/drone-go/drone"
+
+	"github.com/docker/docker/api/types/container"
+	docker "github.com/docker/docker/client"
+	"github.com/golang/mock/gomock"
+)
+
+func TestDrain(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	shutdown := &autoscaler.Server{ID: "i-1", Name: "agent-1", Address: "1.2.3.4", State: autoscaler.StateShutdown}
+	draining := &autoscaler.Server{ID: "i-2", Name: "agent-2", Address: "1.2.3.5", State: autoscaler.StateDraining}
+
+	queue := mocks.NewMockClient(controller)
+	queue.EXPECT().Queue().Return([]*drone.Stage{}, nil)
+
+	var created *container.Config
+	client := mocks.NewMockAPIClient(controller)
+	client.EXPECT().ContainerInspect(gomock.Any(), "agent").Return(container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{HostConfig: &container.HostConfig{}},
+		Config: &container.Config{
+			Env: []string{"DRONE_RUNNER_CAPACITY=2", "DRONE_RUNNER_NAME=agent-1"},
+		},
+	}, nil)
+	client.EXPECT().ContainerStop(gomock.Any(), "agent", gomock.Any()).Return(nil)
+	client.EXPECT().ContainerRemove(gomock.Any(), "agent", gomock.Any()).Return(nil)
+	client.EXPECT().ContainerCreate(gomock.Any(), gomock.Any(), gomock.Any(), nil, nil, "agent").
+		DoAndReturn(func(_ context.Context, config *container.Config, _, _, _, _ interface{}) (container.CreateResponse, error) {
+			created = config
+			return container.CreateResponse{ID: "agent"}, nil
+		})
+	client.EXPECT().ContainerStart(gomock.Any(), "agent", gomock.Any()).Return(nil)
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().Transition(gomock.Any(), "agent-1", autoscaler.StateShutdown, autoscaler.StateDraining).Return(nil)
+	store.EXPECT().ListState(gomock.Any(), autoscaler.StateDraining).Return([]*autoscaler.Server{shutdown, draining}, nil)
+
+	c := collector{
+		servers: store,
+		drone:   queue,
+		client: func(*autoscaler.Server) (docker.APIClient, io.Closer, error) {
+			return client, nil, nil
+		},
+	}
+	drained, err := c.drain(context.TODO(), []*autoscaler.Server{shutdown})
+	c.wg.Wait()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if got, want := shutdown.State, autoscaler.StateDraining; got != want {
+		t.Errorf("Want server state %s, got %s", want, got)
+	}
+	// the server paused in this cycle must not be drained
+	// until a subsequent cycle.
+	if got, want := drained, []*autoscaler.Server{draining}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Want drained servers returned")
+	}
+	if created == nil {
+		t.Errorf("Want agent re-created")
+		return
+	}
+	if got, want := created.Env, []string{"DRONE_RUNNER_NAME=agent-1", "DRONE_RUNNER_CAPACITY=0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Want agent environment %v, got %v", want, got)
+	}
+	if got, want := created.Labels["io.drone.agent.capacity"], "0"; got != want {
+		t.Errorf("Want agent capacity label %s, got %s", want, got)
+	}
+}
+
+// This test verifies that a busy server is paused, so that it
+// no longer accepts stages, but is not drained until the
+// running stage is complete.
+func TestDrain_Busy(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	shutdown := &autoscaler.Server{ID: "i-1", Name: "agent-1", Address: "1.2.3.4", State: autoscaler.StateShutdown}
+
+	queue := mocks.NewMockClient(controller)
+	queue.EXPECT().Queue().Return([]*drone.Stage{
+		{Machine: "agent-1", Status: drone.StatusRunning},
+	}, nil)
+
+	client := mocks.NewMockAPIClient(controller)
+	client.EXPECT().ContainerInspect(gomock.Any(), "agent").Return(container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{HostConfig: &container.HostConfig{}},
+		Config:            &container.Config{},
+	}, nil)
+	client.EXPECT().ContainerStop(gomock.Any(), "agent", gomock.Any()).Return(nil)
+	client.EXPECT().ContainerRemove(gomock.Any(), "agent", gomock.Any()).Return(nil)
+	client.EXPECT().ContainerCreate(gomock.Any(), gomock.Any(), gomock.Any(), nil, nil, "agent").Return(container.CreateResponse{ID: "agent"}, nil)
+	client.EXPECT().ContainerStart(gomock.Any(), "agent", gomock.Any()).Return(nil)
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().Transition(gomock.Any(), "agent-1", autoscaler.StateShutdown, autoscaler.StateDraining).Return(nil)
+	store.EXPECT().ListState(gomock.Any(), autoscaler.StateDraining).Return([]*autoscaler.Server{shutdown}, nil)
+
+	c := collector{
+		servers: store,
+		drone:   queue,
+		client: func(*autoscaler.Server) (docker.APIClient, io.Closer, error) {
+			return client, nil, nil
+		},
+		drainTimeout: time.Hour,
+	}
+	drained, err := c.drain(context.TODO(), []*autoscaler.Server{shutdown})
+	c.wg.Wait()
+	if err != nil {
+		t.Error(err)
+	}
+	if len(drained) != 0 {
+		t.Errorf("Want busy server not drained")
+	}
+	if got, want := shutdown.State, autoscaler.StateDraining; got != want {
+		t.Errorf("Want server state %s, got %s", want, got)
+	}
+}
+
+// This test verifies that draining servers are not stopped
+// while stages are assigned to the server, unless the drain
+// timeout is exceeded.
+func TestDrain_Draining(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	// the expired server was updated recently, which does
+	// not reset the drain timeout.
+	recent := &autoscaler.Server{Name: "agent-1", State: autoscaler.StateDraining, Draining: time.Now().Unix(), Updated: time.Now().Unix()}
+	expired := &autoscaler.Server{Name: "agent-2", State: autoscaler.StateDraining, Draining: time.Now().Add(-2 * time.Hour).Unix(), Updated: time.Now().Unix()}
+
+	queue := mocks.NewMockClient(controller)
+	queue.EXPECT().Queue().Return([]*drone.Stage{
+		{Machine: "agent-1", Status: drone.StatusRunning},
+		{Machine: "agent-2", Status: drone.StatusRunning},
+	}, nil)
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().ListState(gomock.Any(), autoscaler.StateDraining).Return([]*autoscaler.Server{recent, expired}, nil)
+
+	c := collector{
+		servers:      store,
+		drone:        queue,
+		drainTimeout: time.Hour,
+	}
+	drained, err := c.drain(context.TODO(), nil)
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := drained, []*autoscaler.Server{expired}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Want server drained after timeout")
+	}
+}
+
+func TestReplaceEnv(t *testing.T) {
+	got := replaceEnv([]string{"A=1", "B=2", "AB=3"}, "A", "4")
+	want := []string{"B=2", "AB=3", "A=4"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Want environment %v, got %v", want, got)
+	}
+}
@@ -23,33 +23,90 @@ const purge = time.Hour * 24
 type engine struct {
 	mu sync.Mutex
 
-	allocator *allocator
-	collector *collector
-	installer *installer
-	pinger    *pinger
-	planner   *planner
-	reaper    *reaper
-	metrics   metrics.Collector
+	scalers []*scaler
+	servers autoscaler.ServerStore
+	events  autoscaler.EventStore
+	retain  time.Duration // event retention
+
+	paused bool
+}
+
+// scaler manages the servers in a single pool.
+type scaler struct {
+	name string
+
+	allocator  *allocator
+	collector  *collector
+	installer  *installer
+	pinger     *pinger
+	planner    *planner
+	reaper     *reaper
+	watcher    *watcher
+	reconciler *reconciler
+	rollout    *rollout
 
 	interval time.Duration
-	paused   bool
 }
 
-// New returns a new autoscale Engine.
+// New returns a new autoscale Engine. The engine manages
+// each pool with its own planner, allocator, installer and
+// collector.
 func New(
 	client drone.Client,
+	servers autoscaler.ServerStore,
+	samples autoscaler.SampleStore,
+	events autoscaler.EventStore,
+	metrics metrics.Collector,
+	pools ...Pool,
+) autoscaler.Engine {
+	e := &engine{
+		paused:  false,
+		servers: servers,
+		events:  events,
+	}
+	// the event retention is a global setting, and is
+	// the same for every pool.
+	if len(pools) != 0 {
+		e.retain = pools[0].Config.Events.Retain
+	}
+	for i, pool := range pools {
+		// servers created before pools were supported are
+		// not assigned to a pool, and are managed by the
+		// first pool.
+		store := &poolStore{
+			ServerStore: servers,
+			name:        pool.Name,
+			legacy:      i == 0,
+		}
+		scaler := newScaler(client, pool.Name, pool.Config, store, samples, pool.Provider, metrics)
+		// the reconciler compares the provider servers to the
+		// servers in every pool, since pools may share the same
+		// provider account.
+		scaler.reconciler.servers = servers
+		e.scalers = append(e.scalers, scaler)
+	}
+	return e
+}
+
+// helper function returns a new scaler for the named pool.
+func newScaler(
+	client drone.Client,
+	name string,
 	config config.Config,
 	servers autoscaler.ServerStore,
+	samples autoscaler.SampleStore,
 	provider autoscaler.Provider,
 	metrics metrics.Collector,
-) autoscaler.Engine {
-	return &engine{
-		paused:   false,
+) *scaler {
+	hash := agentHash(config)
+	s := &scaler{
+		name:     name,
 		interval: config.Interval,
 		allocator: &allocator{
-			servers:  servers,
-			provider: provider,
-			metrics:  metrics,
+			servers:   servers,
+			provider:  provider,
+			metrics:   metrics,
+			batchSize: config.Pool.BatchSize,
 		},
 		collector: &collector{
 			timeout:  config.Timeout.Stop,
@@ -86,12 +143,16 @@ func New(
 			watchtowerSignalEnabled: config.Watchtower.SignalEnabled,
 			watchtowerTimeout:       config.Watchtower.Timeout,
 			watchtowerInterval:      config.Watchtower.Interval,
+			hash:                    hash,
 		},
 		pinger: &pinger{
-			servers:  servers,
-			client:   newDockerClient,
-			enabled:  config.Pinger.Enabled,
-			interval: config.Pinger.Interval,
+			servers:     servers,
+			client:      newDockerClient,
+			enabled:     config.Pinger.Enabled,
+			interval:    config.Pinger.Interval,
+			action:      config.Pinger.Action,
+			maxRestarts: config.Pinger.MaxRestarts,
+			healthPort:  runnerHealthPort(config.Agent.Ports),
 		},
 		planner: &planner{
 			client:     client,
@@ -102,11 +163,21 @@ func New(
 			kernel:     config.Agent.Kernel,
 			buffer:     config.CapacityBuffer,
 			ttu:        config.Pool.MinAge,
+			ttl:        config.Pool.MaxAge,
 			min:        config.Pool.Min,
 			max:        config.Pool.Max,
 			cap:        config.Agent.Concurrency,
 			labels:     config.Agent.Labels,
+			labelMatch: config.Agent.LabelsMatch,
 			namePrefix: config.Agent.NamePrefix,
+			policy:     newPolicy(config),
+			schedules:  newSchedules(config.Schedule.Entries),
+			hash:       hash,
+			backoff: &backoff{
+				base:   config.Recovery.Backoff,
+				max:    config.Recovery.BackoffMax,
+				budget: config.Recovery.Budget,
+			},
 		},
 		reaper: &reaper{
 			servers:  servers,
@@ -114,7 +185,75 @@ func New(
 			interval: config.Reaper.Interval,
 			enabled:  config.Reaper.Enabled,
 		},
+		watcher: &watcher{
+			servers:  servers,
+			metrics:  metrics,
+			interval: config.Interrupt.Interval,
+		},
+		reconciler: &reconciler{
+			servers:  servers,
+			provider: provider,
+			metrics:  metrics,
+			pool:     name,
+			action:   config.Reconcile.Action,
+			interval: config.Reconcile.Interval,
+		},
+	}
+	s.rollout = &rollout{
+		servers:     servers,
+		planner:     s.planner,
+		hash:        hash,
+		surge:       config.Rollout.Surge,
+		unavailable: config.Rollout.Unavailable,
+		interval:    config.Rollout.Interval,
+		enabled:     config.Rollout.Enabled,
+	}
+	if config.Forecast.Enabled {
+		s.planner.forecast = &forecaster{
+			pool:     name,
+			method:   config.Forecast.Method,
+			alpha:    config.Forecast.Alpha,
+			window:   config.Forecast.Window,
+			interval: config.Interval,
+			retain:   config.Forecast.Retain,
+			samples:  samples,
+			metrics:  metrics,
+		}
+	}
+	if config.Interrupt.Enabled {
+		if interrupter, ok := autoscaler.AsInterrupter(provider); ok {
+			s.watcher.interrupter = interrupter
+		}
+	}
+	if config.Drain.Enabled {
+		s.collector.drone = client
+		s.collector.drainTimeout = config.Drain.Timeout
+	}
+	if config.Reconcile.Enabled {
+		if lister, ok := autoscaler.AsLister(provider); ok {
+			s.reconciler.lister = lister
+		} else {
+			logger.Default.
+				WithField("pool", name).
+				Warnln("provider does not support reconciliation")
+		}
 	}
+	if batch, ok := autoscaler.AsBatchCreator(provider); ok {
+		s.allocator.batch = batch
+	}
+	if config.Pool.Hibernate {
+		hibernator, ok := autoscaler.AsHibernator(provider)
+		if ok {
+			s.allocator.hibernator = hibernator
+			s.planner.hibernator = hibernator
+			s.installer.hibernate = true
+		} else {
+			logger.Default.
+				WithField("pool", name).
+				Warnln("provider does not support hibernation")
+		}
+	}
+	return s
 }
 
 // Pause paueses the scaler.
@@ -138,104 +277,175 @@ func (e *engine) Resume() {
 	e.mu.Unlock()
 }
 
+// Pools returns the runtime status of each pool.
+func (e *engine) Pools() []*autoscaler.PoolStatus {
+	var pools []*autoscaler.PoolStatus
+	for _, s := range e.scalers {
+		limits := s.planner.limits(time.Now())
+		status := &autoscaler.PoolStatus{
+			Name:     s.name,
+			Min:      limits.min,
+			Max:      limits.max,
+			Buffer:   limits.buffer,
+			Schedule: limits.schedule,
+		}
+		if s.rollout.enabled {
+			status.Rollout = s.rollout.Status()
+		}
+		pools = append(pools, status)
+	}
+	return pools
+}
+
 func (e *engine) Start(ctx context.Context) {
+	ctx = autoscaler.WithActor(ctx, "engine")
 	e.reset(ctx)
 
 	var wg sync.WaitGroup
-	wg.Add(7)
+	for _, s := range e.scalers {
+		wg.Add(1)
+		go func(s *scaler) {
+			e.start(ctx, s)
+			wg.Done()
+		}(s)
+	}
+	wg.Add(1)
 	go func() {
-		e.allocate(ctx)
+		e.purge(ctx)
 		wg.Done()
 	}()
+	wg.Wait()
+}
+
+// starts the processes that manage the servers in a
+// single pool.
+func (e *engine) start(ctx context.Context, s *scaler) {
+	if s.name != "" {
+		ctx = logger.WithContext(ctx,
+			logger.FromContext(ctx).WithField("pool", s.name),
+		)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(9)
 	go func() {
-		e.install(ctx)
+		e.allocate(autoscaler.WithActor(ctx, "allocator"), s)
 		wg.Done()
 	}()
 	go func() {
-		e.collect(ctx)
+		e.install(autoscaler.WithActor(ctx, "installer"), s)
 		wg.Done()
 	}()
 	go func() {
-		e.plan(ctx)
+		e.collect(autoscaler.WithActor(ctx, "collector"), s)
 		wg.Done()
 	}()
 	go func() {
-		e.purge(ctx)
+		e.plan(autoscaler.WithActor(ctx, "planner"), s)
+		wg.Done()
+	}()
+	go func() {
+		e.reap(autoscaler.WithActor(ctx, "reaper"), s)
+		wg.Done()
+	}()
+	go func() {
+		e.ping(autoscaler.WithActor(ctx, "pinger"), s)
 		wg.Done()
 	}()
 	go func() {
-		e.reap(ctx)
+		e.watch(autoscaler.WithActor(ctx, "watcher"), s)
 		wg.Done()
 	}()
 	go func() {
-		e.ping(ctx)
+		e.reconcile(autoscaler.WithActor(ctx, "reconciler"), s)
+		wg.Done()
+	}()
+	go func() {
+		e.roll(autoscaler.WithActor(ctx, "rollout"), s)
 		wg.Done()
 	}()
 	wg.Wait()
 }
 
 // runs the allocation process.
-func (e *engine) allocate(ctx context.Context) {
+func (e *engine) allocate(ctx context.Context, s *scaler) {
 	const interval = time.Second * 10
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-time.After(interval):
-			e.allocator.Allocate(ctx)
+			s.allocator.Allocate(ctx)
 		}
 	}
 }
 
 // runs the installation process.
-func (e *engine) install(ctx context.Context) {
+func (e *engine) install(ctx context.Context, s *scaler) {
 	const interval = time.Second * 10
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-time.After(interval):
-			e.installer.Install(ctx)
+			s.installer.Install(ctx)
 		}
 	}
 }
 
 // runs the collection process.
-func (e *engine) collect(ctx context.Context) {
+func (e *engine) collect(ctx context.Context, s *scaler) {
 	const interval = time.Second * 10
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-time.After(interval):
-			e.collector.Collect(ctx)
+			s.collector.Collect(ctx)
 		}
 	}
 }
 
 // runs the planning process.
-func (e *engine) plan(ctx context.Context) {
+func (e *engine) plan(ctx context.Context, s *scaler) {
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-time.After(e.interval):
+		case <-time.After(s.interval):
 			if !e.Paused() {
-				e.planner.Plan(ctx)
+				s.planner.Plan(ctx)
 			}
 		}
 	}
 }
 
 // runs the ping process.
-func (e *engine) ping(ctx context.Context) {
+func (e *engine) ping(ctx context.Context, s *scaler) {
 	// by default, run the pinger every 10m.
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-time.After(e.pinger.interval):
-			e.pinger.Ping(ctx)
+		case <-time.After(s.pinger.interval):
+			s.pinger.Ping(ctx)
+		}
+	}
+}
+
+// runs the interruption watcher process.
+func (e *engine) watch(ctx context.Context, s *scaler) {
+	// the watcher is a no-op if the provider cannot
+	// detect server interruptions.
+	if s.watcher.interrupter == nil {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.watcher.interval):
+			s.watcher.Watch(ctx)
 		}
 	}
 }
@@ -253,21 +463,63 @@ func (e *engine) purge(ctx context.Context) {
 		case <-time.After(interval):
 			logger.WithField("ttl", retain.String()).
 				Debugln("clear stopped servers from database")
-			e.planner.servers.Purge(ctx, time.Now().Add(retain).Unix())
+			e.servers.Purge(ctx, time.Now().Add(retain).Unix())
+
+			// server events are retained after the server
+			// is purged, according to the event retention.
+			if e.events != nil && e.retain != 0 {
+				logger.WithField("ttl", e.retain.String()).
+					Debugln("clear server events from database")
+				e.events.Purge(ctx, time.Now().Add(-e.retain).Unix())
+			}
 		}
 	}
 }
 
 // runs the reaper process.
-func (e *engine) reap(ctx context.Context) {
+func (e *engine) reap(ctx context.Context, s *scaler) {
 	// by default, the reaper is run hourly since in general this
 	// should happen infrequently.
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-time.After(e.reaper.interval):
-			e.reaper.Reap(ctx)
+		case <-time.After(s.reaper.interval):
+			s.reaper.Reap(ctx)
+		}
+	}
+}
+
+// runs the reconciler process.
+func (e *engine) reconcile(ctx context.Context, s *scaler) {
+	// the reconciler is a no-op if disabled, or if the
+	// provider cannot list servers.
+	if s.reconciler.lister == nil {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.reconciler.interval):
+			s.reconciler.Reconcile(ctx)
+		}
+	}
+}
+
+// runs the rollout process.
+func (e *engine) roll(ctx context.Context, s *scaler) {
+	if !s.rollout.enabled {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.rollout.interval):
+			if !e.Paused() {
+				s.rollout.Rollout(ctx)
+			}
 		}
 	}
 }
@@ -276,7 +528,7 @@ func (e *engine) reset(ctx context.Context) {
 	// handle the situation where the autoscaler is stopped or
 	// restarted during instance setup or teardown. If this happens
 	// reset the instance state to resume.
-	servers, _ := e.allocator.servers.List(ctx)
+	servers, _ := e.servers.List(ctx)
 	for _, s := range servers {
 		switch s.State {
 		case autoscaler.StateStaging:
@@ -286,8 +538,8 @@ func (e *engine) reset(ctx context.Context) {
 				WithField("from-state", "staging").
 				WithField("to-state", "created")
 			log.Infoln("reset instance state")
-			s.State = autoscaler.StateCreated
-			if err := e.allocator.servers.Update(ctx, s); err != nil {
+			err := transition(ctx, e.servers, s, autoscaler.StateCreated)
+			if err != nil && err != autoscaler.ErrStateConflict {
 				log.WithError(err).
 					Error("failed to reset instance state")
 			}
@@ -298,8 +550,8 @@ func (e *engine) reset(ctx context.Context) {
 				WithField("from-state", "stopping").
 				WithField("to-state", "shutdown")
 			log.Infoln("reset instance state")
-			s.State = autoscaler.StateShutdown
-			if err := e.allocator.servers.Update(ctx, s); err != nil {
+			err := transition(ctx, e.servers, s, autoscaler.StateShutdown)
+			if err != nil && err != autoscaler.ErrStateConflict {
 				log.WithError(err).
 					Errorln("failed to reset instance state")
 			}
@@ -0,0 +1,135 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package engine
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/metrics"
+)
+
+// forecast methods.
+const (
+	// forecastSmoothing forecasts demand using double
+	// exponential smoothing of the recent samples.
+	forecastSmoothing = "smoothing"
+
+	// forecastWeekly forecasts demand using the peak demand
+	// at the same time in the previous week.
+	forecastWeekly = "weekly"
+)
+
+// defines the history used to forecast demand with
+// exponential smoothing.
+const smoothingHistory = time.Hour * 24
+
+// defines the time between weekly samples.
+const week = time.Hour * 24 * 7
+
+// a forecaster records the pending and running stage
+// counts, and forecasts the demand for the next provisioning
+// window based on the recorded history.
+type forecaster struct {
+	pool     string
+	method   string
+	alpha    float64       // smoothing factor
+	window   time.Duration // provisioning window
+	interval time.Duration // sampling interval
+	retain   time.Duration // sample retention
+
+	samples autoscaler.SampleStore
+	metrics metrics.Collector
+}
+
+// Forecast records the pending and running stage counts and
+// returns the forecast number of pending and running stages
+// for the next provisioning window.
+func (f *forecaster) Forecast(ctx context.Context, pending, running int) (int, error) {
+	now := time.Now()
+	err := f.samples.Create(ctx, &autoscaler.Sample{
+		Pool:    f.pool,
+		Pending: pending,
+		Running: running,
+		Created: now.Unix(),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	err = f.samples.Purge(ctx, now.Add(-f.retain).Unix())
+	if err != nil {
+		return 0, err
+	}
+
+	var demand float64
+	switch f.method {
+	case forecastWeekly:
+		demand, err = f.weekly(ctx, now)
+	default:
+		demand, err = f.smoothing(ctx, now)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	f.metrics.SetForecastDemand(f.pool, demand)
+	return int(math.Ceil(demand)), nil
+}
+
+// helper function forecasts demand using double exponential
+// smoothing, which accounts for the level and the trend of
+// the recent demand.
+func (f *forecaster) smoothing(ctx context.Context, now time.Time) (float64, error) {
+	samples, err := f.samples.List(ctx, f.pool,
+		now.Add(-smoothingHistory).Unix(),
+		now.Add(time.Second).Unix(),
+	)
+	if err != nil || len(samples) == 0 {
+		return 0, err
+	}
+
+	alpha := f.alpha
+	if alpha <= 0 || alpha > 1 {
+		alpha = 0.5
+	}
+
+	level := float64(samples[0].Pending + samples[0].Running)
+	trend := 0.0
+	for _, sample := range samples[1:] {
+		demand := float64(sample.Pending + sample.Running)
+		prev := level
+		level = alpha*demand + (1-alpha)*(level+trend)
+		trend = alpha*(level-prev) + (1-alpha)*trend
+	}
+
+	// the number of sampling intervals in the
+	// provisioning window.
+	steps := 1.0
+	if f.interval > 0 {
+		steps = math.Max(float64(f.window)/float64(f.interval), 1)
+	}
+	return math.Max(level+trend*steps, 0), nil
+}
+
+// helper function forecasts demand using the peak demand
+// during the provisioning window, one week ago.
+func (f *forecaster) weekly(ctx context.Context, now time.Time) (float64, error) {
+	from := now.Add(-week)
+	samples, err := f.samples.List(ctx, f.pool,
+		from.Unix(),
+		from.Add(f.window).Unix(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	var peak int
+	for _, sample := range samples {
+		peak = max(peak, sample.Pending+sample.Running)
+	}
+	return float64(peak), nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/mocks"
+	"github.com/drone/drone-go/drone"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestForecast_Smoothing(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	// demand increases by two every interval.
+	samples := []*autoscaler.Sample{
+		{Pending: 0, Running: 0},
+		{Pending: 1, Running: 1},
+		{Pending: 2, Running: 2},
+		{Pending: 3, Running: 3},
+	}
+
+	store := mocks.NewMockSampleStore(controller)
+	store.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	store.EXPECT().Purge(gomock.Any(), gomock.Any()).Return(nil)
+	store.EXPECT().List(gomock.Any(), "arm64", gomock.Any(), gomock.Any()).Return(samples, nil)
+
+	collector := mocks.NewMockCollector(controller)
+	collector.EXPECT().SetForecastDemand("arm64", float64(10))
+
+	f := &forecaster{
+		pool:     "arm64",
+		method:   forecastSmoothing,
+		alpha:    1,
+		window:   time.Minute * 10,
+		interval: time.Minute * 5,
+		samples:  store,
+		metrics:  collector,
+	}
+
+	// with a smoothing factor of 1 the level is the latest
+	// demand, and the trend is extrapolated for the two
+	// intervals in the provisioning window.
+	demand, err := f.Forecast(context.TODO(), 3, 3)
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := demand, 10; got != want {
+		t.Errorf("Want forecast demand %d, got %d", want, got)
+	}
+}
+
+func TestForecast_Weekly(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	samples := []*autoscaler.Sample{
+		{Pending: 2, Running: 1},
+		{Pending: 6, Running: 2},
+		{Pending: 1, Running: 4},
+	}
+
+	store := mocks.NewMockSampleStore(controller)
+	store.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	store.EXPECT().Purge(gomock.Any(), gomock.Any()).Return(nil)
+	store.EXPECT().List(gomock.Any(), "", gomock.Any(), gomock.Any()).Return(samples, nil)
+
+	collector := mocks.NewMockCollector(controller)
+	collector.EXPECT().SetForecastDemand("", float64(8))
+
+	f := &forecaster{
+		method:  forecastWeekly,
+		window:  time.Minute * 10,
+		samples: store,
+		metrics: collector,
+	}
+
+	demand, err := f.Forecast(context.TODO(), 0, 0)
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := demand, 8; got != want {
+		t.Errorf("Want forecast demand %d, got %d", want, got)
+	}
+}
+
+// This test verifies that servers are provisioned ahead
+// of the forecast demand when the queue is empty.
+func TestPlan_Forecast(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	servers := []*autoscaler.Server{
+		{Name: "server1", Capacity: 2, State: autoscaler.StateRunning},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().List(gomock.Any()).Return(servers, nil)
+	store.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	client := mocks.NewMockClient(controller)
+	client.EXPECT().Queue().Return([]*drone.Stage{}, nil)
+
+	samples := mocks.NewMockSampleStore(controller)
+	samples.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	samples.EXPECT().Purge(gomock.Any(), gomock.Any()).Return(nil)
+	samples.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]*autoscaler.Sample{
+		{Pending: 4, Running: 2},
+	}, nil)
+
+	collector := mocks.NewMockCollector(controller)
+	collector.EXPECT().SetForecastDemand(gomock.Any(), gomock.Any())
+
+	p := planner{
+		cap:     2,
+		min:     1,
+		max:     4,
+		client:  client,
+		servers: store,
+		forecast: &forecaster{
+			method:  forecastWeekly,
+			samples: samples,
+			metrics: collector,
+		},
+	}
+
+	err := p.Plan(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -42,6 +42,8 @@ type installer struct {
 	keepaliveTimeout time.Duration
 	runner           config.Runner
 	labels           map[string]string
+	hibernate        bool
+	hash             string // agent configuration hash
 
 	checkInterval time.Duration
 	checkDeadline time.Duration
@@ -74,8 +76,10 @@ func (i *installer) Install(ctx context.Context) error {
 	}
 
 	for _, server := range servers {
-		server.State = autoscaler.StateStaging
-		err = i.servers.Update(ctx, server)
+		err = transition(ctx, i.servers, server, autoscaler.StateStaging)
+		if err == autoscaler.ErrStateConflict {
+			continue
+		}
 		if err != nil {
 			logger.WithError(err).
 				WithField("server", server.Name).
@@ -147,6 +151,40 @@ poller:
 	// track elapsed time to establish a connection
 	i.metrics.TrackServerInitTime(start)
 
+	// a hibernated server retains the agent container when
+	// the server is stopped. The existing agent container is
+	// started instead of installing a new agent container,
+	// unless the agent configuration changed while the server
+	// was stopped, in which case the container is replaced.
+	if i.hibernate {
+		if _, err := client.ContainerInspect(ctx, "agent"); err == nil {
+			if instance.Hash == i.hash {
+				logger.Debugln("start the existing agent container")
+
+				err = client.ContainerStart(ctx, "agent", container.StartOptions{})
+				if err != nil {
+					i.metrics.IncrServerSetupError()
+					logger.WithError(err).
+						Errorln("cannot start the existing agent container")
+					return i.errorUpdate(ctx, instance, err)
+				}
+
+				instance.State = autoscaler.StateRunning
+				return update(ctx, i.servers, instance, autoscaler.StateStaging)
+			}
+
+			logger.Debugln("remove the outdated agent container")
+
+			err = client.ContainerRemove(ctx, "agent", container.RemoveOptions{Force: true})
+			if err != nil {
+				i.metrics.IncrServerSetupError()
+				logger.WithError(err).
+					Errorln("cannot remove the outdated agent container")
+				return i.errorUpdate(ctx, instance, err)
+			}
+		}
+	}
+
 	logger.WithField("image", i.image).
 		Debugln("pull docker image")
 
@@ -300,8 +338,15 @@ poller:
 	// track elapsed time to install software.
 	i.metrics.TrackServerSetupTime(start)
 
+	// the server record is only updated if the server is
+	// staging, to prevent overwriting a shutdown requested
+	// while the server was installing.
 	instance.State = autoscaler.StateRunning
-	err = i.servers.Update(ctx, instance)
+	instance.Hash = i.hash
+	err = update(ctx, i.servers, instance, autoscaler.StateStaging)
+	if err == autoscaler.ErrStateConflict {
+		return err
+	}
 	if err != nil {
 		i.metrics.IncrServerSetupError()
 		logger.WithError(err).
@@ -395,8 +440,8 @@ func (i *installer) errorUpdate(ctx context.Context, server *autoscaler.Server,
 	if err != nil {
 		server.State = autoscaler.StateError
 		server.Error = err.Error()
-		xerr := i.servers.Update(ctx, server)
-		if xerr != nil {
+		xerr := update(ctx, i.servers, server, autoscaler.StateStaging)
+		if xerr != nil && xerr != autoscaler.ErrStateConflict {
 			logger.FromContext(ctx).
 				WithError(xerr).
 				WithField("server", server.Name).
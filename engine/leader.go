@@ -0,0 +1,181 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package engine
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/logger"
+)
+
+// NewLeader returns an autoscale Engine that starts the engine
+// only while the replica holds the leader lease. This prevents
+// multiple replicas that share the same database from running
+// the engine concurrently.
+func NewLeader(engine autoscaler.Engine, leases autoscaler.LeaseStore, id string, ttl time.Duration) autoscaler.Engine {
+	return &leader{
+		Engine: engine,
+		leases: leases,
+		id:     id,
+		ttl:    ttl,
+	}
+}
+
+type leader struct {
+	autoscaler.Engine
+
+	leases autoscaler.LeaseStore
+	id     string
+	ttl    time.Duration
+}
+
+func (l *leader) Start(ctx context.Context) {
+	logger := logger.FromContext(ctx).
+		WithField("replica", l.id)
+
+	var (
+		wg      sync.WaitGroup
+		cancel  context.CancelFunc
+		expires time.Time
+	)
+
+	// helper function stops the engine, and waits for the
+	// engine processes to exit.
+	stop := func() {
+		if cancel != nil {
+			cancel()
+			wg.Wait()
+			cancel = nil
+		}
+	}
+
+	// the lease is renewed at a third of the lease duration,
+	// to ensure the lease is renewed before it expires. The
+	// engine is stopped shortly before the lease expires if
+	// the lease cannot be renewed.
+	interval := l.ttl / 3
+	margin := l.ttl / 10
+	for {
+		// the lease expiry is measured from the start of the
+		// request, since the lease may be written at any time
+		// before the request returns. The request is bounded
+		// so that a stalled request cannot extend the time the
+		// engine runs without holding the lease.
+		start := time.Now()
+		timeout := interval
+		if cancel != nil && expires.Sub(start) < timeout {
+			timeout = expires.Sub(start)
+		}
+		acquireCtx, acquireCancel := context.WithTimeout(ctx, timeout)
+		held, err := l.leases.Acquire(acquireCtx, l.id, l.ttl)
+		acquireCancel()
+
+		switch {
+		case ctx.Err() != nil:
+			// ignore errors caused by the program terminating.
+		case err != nil:
+			logger.WithError(err).
+				Errorln("cannot acquire the leader lease")
+
+			// if the lease cannot be renewed before it expires
+			// another replica may acquire the lease, and the
+			// engine is stopped.
+			if cancel != nil && !time.Now().Before(expires) {
+				logger.Warnln("leader lease expired, stop the engine")
+				stop()
+			}
+		case held:
+			expires = start.Add(l.ttl - margin)
+			l.sync(ctx)
+			if cancel == nil {
+				logger.Infoln("leader lease acquired, start the engine")
+
+				engineCtx, engineCancel := context.WithCancel(ctx)
+				cancel = engineCancel
+				wg.Add(1)
+				go func() {
+					l.Engine.Start(engineCtx)
+					wg.Done()
+				}()
+			}
+		default:
+			if cancel != nil {
+				logger.Warnln("leader lease lost, stop the engine")
+				stop()
+			}
+		}
+
+		wait := interval
+		if cancel != nil && time.Until(expires) < wait {
+			wait = time.Until(expires)
+		}
+
+		select {
+		case <-ctx.Done():
+			stop()
+			// the lease is released so that another replica
+			// can acquire the lease without waiting for the
+			// lease to expire.
+			l.leases.Release(context.Background(), l.id)
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Pause pauses the engine. The paused flag is persisted with
+// the lease, so that the engine is paused when the request is
+// served by a replica that is not the leader.
+func (l *leader) Pause() {
+	l.setPaused(true)
+}
+
+// Paused returns true if the engine is paused.
+func (l *leader) Paused() bool {
+	lease, err := l.leases.Find(context.Background())
+	if err != nil {
+		return l.Engine.Paused()
+	}
+	return lease.Paused
+}
+
+// Resume resumes the engine if paused.
+func (l *leader) Resume() {
+	l.setPaused(false)
+}
+
+func (l *leader) setPaused(paused bool) {
+	err := l.leases.Pause(context.Background(), paused)
+	if err != nil {
+		logger.Default.WithError(err).
+			Errorln("cannot update the paused flag")
+	}
+	if paused {
+		l.Engine.Pause()
+	} else {
+		l.Engine.Resume()
+	}
+}
+
+// helper function pauses or resumes the engine to match the
+// paused flag persisted with the lease, since the engine may
+// be paused or resumed by another replica.
+func (l *leader) sync(ctx context.Context) {
+	lease, err := l.leases.Find(ctx)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).
+			Warnln("cannot find the leader lease")
+		return
+	}
+	switch {
+	case lease.Paused && !l.Engine.Paused():
+		l.Engine.Pause()
+	case !lease.Paused && l.Engine.Paused():
+		l.Engine.Resume()
+	}
+}
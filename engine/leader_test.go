@@ -0,0 +1,162 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/mocks"
+
+	"github.com/golang/mock/gomock"
+)
+
+// This test verifies that the engine is started when the
+// leader lease is acquired, and stopped when the lease is
+// lost.
+func TestLeader(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	started := make(chan struct{})
+	stopped := make(chan struct{})
+
+	engine := mocks.NewMockEngine(controller)
+	engine.EXPECT().Start(gomock.Any()).Do(func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		close(stopped)
+	})
+
+	leases := mocks.NewMockLeaseStore(controller)
+	gomock.InOrder(
+		leases.EXPECT().Acquire(gomock.Any(), "replica-1", time.Millisecond*30).Return(true, nil),
+		leases.EXPECT().Acquire(gomock.Any(), "replica-1", time.Millisecond*30).Return(false, nil).AnyTimes(),
+	)
+	leases.EXPECT().Find(gomock.Any()).Return(&autoscaler.Lease{}, nil).AnyTimes()
+	leases.EXPECT().Release(gomock.Any(), "replica-1").Return(nil)
+
+	engine.EXPECT().Paused().Return(false).AnyTimes()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		NewLeader(engine, leases, "replica-1", time.Millisecond*30).Start(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Errorf("Want engine started when the lease is acquired")
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Errorf("Want engine stopped when the lease is lost")
+	}
+
+	cancel()
+	<-done
+}
+
+// This test verifies that the engine is not started if the
+// leader lease cannot be acquired.
+func TestLeader_Error(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	leases := mocks.NewMockLeaseStore(controller)
+	leases.EXPECT().Acquire(gomock.Any(), "replica-1", gomock.Any()).Return(false, errors.New("mock error")).MinTimes(1)
+	leases.EXPECT().Release(gomock.Any(), "replica-1").Return(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	NewLeader(mocks.NewMockEngine(controller), leases, "replica-1", time.Millisecond*30).Start(ctx)
+}
+
+// This test verifies that the engine is stopped before the
+// lease expires if the lease renewal stalls.
+func TestLeader_Stalled(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	started := make(chan struct{})
+	stopped := make(chan struct{})
+
+	engine := mocks.NewMockEngine(controller)
+	engine.EXPECT().Paused().Return(false).AnyTimes()
+	engine.EXPECT().Start(gomock.Any()).Do(func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		close(stopped)
+	})
+
+	stall := func(ctx context.Context, _ string, _ time.Duration) (bool, error) {
+		<-ctx.Done()
+		return false, ctx.Err()
+	}
+
+	leases := mocks.NewMockLeaseStore(controller)
+	gomock.InOrder(
+		leases.EXPECT().Acquire(gomock.Any(), "replica-1", time.Millisecond*300).Return(true, nil),
+		leases.EXPECT().Acquire(gomock.Any(), "replica-1", time.Millisecond*300).DoAndReturn(stall).AnyTimes(),
+	)
+	leases.EXPECT().Find(gomock.Any()).Return(&autoscaler.Lease{}, nil).AnyTimes()
+	leases.EXPECT().Release(gomock.Any(), "replica-1").Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	start := time.Now()
+	go func() {
+		NewLeader(engine, leases, "replica-1", time.Millisecond*300).Start(ctx)
+		close(done)
+	}()
+
+	<-started
+	select {
+	case <-stopped:
+		if elapsed := time.Since(start); elapsed >= time.Millisecond*300 {
+			t.Errorf("Want engine stopped before the lease expires, stopped after %v", elapsed)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Want engine stopped when the lease renewal stalls")
+	}
+
+	cancel()
+	<-done
+}
+
+// This test verifies that the paused flag is persisted, and
+// that the engine is paused when the flag is set by another
+// replica.
+func TestLeader_Pause(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	engine := mocks.NewMockEngine(controller)
+	engine.EXPECT().Pause()
+
+	leases := mocks.NewMockLeaseStore(controller)
+	leases.EXPECT().Pause(gomock.Any(), true).Return(nil)
+	leases.EXPECT().Find(gomock.Any()).Return(&autoscaler.Lease{Paused: true}, nil)
+
+	l := NewLeader(engine, leases, "replica-1", time.Minute)
+	l.Pause()
+	if !l.Paused() {
+		t.Errorf("Want paused flag read from the lease")
+	}
+
+	// the engine is paused when the lease is renewed, if
+	// paused by another replica.
+	engine.EXPECT().Paused().Return(false)
+	engine.EXPECT().Pause()
+	leases.EXPECT().Find(gomock.Any()).Return(&autoscaler.Lease{Paused: true}, nil)
+	l.(*leader).sync(context.Background())
+}
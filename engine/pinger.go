@@ -6,20 +6,54 @@ package engine
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"net/http"
 	"sync"
 	"time"
 
 	"github.com/drone/autoscaler"
 	"github.com/drone/autoscaler/logger"
+
+	docker "github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+)
+
+// pinger actions.
+const (
+	// pingerError places unhealthy servers in the error state.
+	pingerError = "error"
+
+	// pingerReplace shuts down unhealthy servers, which are
+	// replaced by the planner.
+	pingerReplace = "replace"
 )
 
 type pinger struct {
 	wg sync.WaitGroup
+	mu sync.Mutex
 
 	servers  autoscaler.ServerStore
 	client   clientFunc
 	interval time.Duration
 	enabled  bool
+
+	// action defines how unhealthy agents are handled.
+	action string
+
+	// maxRestarts defines the number of agent container
+	// restarts between pings after which the agent is
+	// considered unhealthy. Disabled if zero.
+	maxRestarts int
+
+	// healthPort defines the published port of the runner
+	// health endpoint. The health endpoint is not checked
+	// if empty.
+	healthPort string
+
+	// restarts tracks the agent container restart count at
+	// the last successful health check, by server name.
+	restarts map[string]int
 }
 
 func (p *pinger) Ping(ctx context.Context) error {
@@ -34,6 +68,18 @@ func (p *pinger) Ping(ctx context.Context) error {
 		return err
 	}
 
+	// discard the restart counts of servers that are no
+	// longer running.
+	p.mu.Lock()
+	restarts := map[string]int{}
+	for _, server := range servers {
+		if count, ok := p.restarts[server.Name]; ok {
+			restarts[server.Name] = count
+		}
+	}
+	p.restarts = restarts
+	p.mu.Unlock()
+
 	for _, server := range servers {
 		p.wg.Add(1)
 		go func(server *autoscaler.Server) {
@@ -83,40 +129,148 @@ func (p *pinger) ping(ctx context.Context, server *autoscaler.Server) error {
 		if err == nil {
 			logger.WithField("state", "healthy").
 				Debugln("server ping successful")
-			return nil
+			return p.checkAgent(ctx, client, server)
 		} else {
 			logger.WithError(err).
 				Warnln("server ping unsuccessful")
 		}
 	}
 
-	server, err = p.servers.Find(ctx, server.Name)
+	logger.WithField("state", "unhealthy").
+		Debugln("failed to reach server")
+
+	return p.unhealthy(ctx, server, pingerError, "Failed to ping the server")
+}
+
+// helper function checks the health of the agent container,
+// and handles unhealthy agents according to the configured
+// action.
+func (p *pinger) checkAgent(ctx context.Context, client docker.APIClient, server *autoscaler.Server) error {
+	logger := logger.FromContext(ctx).
+		WithField("ip", server.Address).
+		WithField("name", server.Name)
+
+	info, err := client.ContainerInspect(ctx, "agent")
+	if ctx.Err() != nil {
+		return nil
+	}
 	if err != nil {
-		// if the server no longer exists in the database
-		// it is possible it was mutated by another goroutine.
-		return err
+		logger.WithError(err).
+			Warnln("cannot inspect the agent container")
+		return p.unhealthy(ctx, server, p.action, "Failed to inspect the agent container")
 	}
 
-	if server.State != autoscaler.StateRunning {
-		// if the server was mutated by another goroutine
-		// we should exit without making any changes.
-		return nil
+	if info.State == nil || !info.State.Running || info.State.Restarting {
+		logger.WithField("state", "unhealthy").
+			Warnln("agent container is not running")
+		return p.unhealthy(ctx, server, p.action, "The agent container is not running")
 	}
 
-	logger.WithField("state", "unhealthy").
-		Debugln("failed to reach server")
+	p.mu.Lock()
+	last, ok := p.restarts[server.Name]
+	p.mu.Unlock()
+
+	// the restart count is reset when the agent container
+	// is re-created, in which case the difference is negative
+	// and the check passes.
+	restarts := info.RestartCount - last
+	if ok && p.maxRestarts > 0 && restarts >= p.maxRestarts {
+		logger.WithField("state", "unhealthy").
+			WithField("restarts", restarts).
+			Warnln("agent container is restarting")
+		return p.unhealthy(ctx, server, p.action,
+			fmt.Sprintf("The agent container restarted %d times", restarts))
+	}
+
+	if p.healthPort != "" {
+		if err := p.checkHealth(ctx, server); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			logger.WithError(err).
+				WithField("state", "unhealthy").
+				Warnln("agent health check unsuccessful")
+			return p.unhealthy(ctx, server, p.action, "The agent health check failed")
+		}
+	}
+
+	p.mu.Lock()
+	if p.restarts == nil {
+		p.restarts = map[string]int{}
+	}
+	p.restarts[server.Name] = info.RestartCount
+	p.mu.Unlock()
+
+	logger.WithField("state", "healthy").
+		Debugln("agent health check successful")
+	return nil
+}
+
+// helper function queries the runner health endpoint.
+func (p *pinger) checkHealth(ctx context.Context, server *autoscaler.Server) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	endpoint := fmt.Sprintf("http://%s/healthz", net.JoinHostPort(server.Address, p.healthPort))
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode > 299 {
+		return fmt.Errorf("health endpoint returned status %d", res.StatusCode)
+	}
+	return nil
+}
+
+// helper function places the server in the error state, or
+// shuts down the server if the action is replace.
+func (p *pinger) unhealthy(ctx context.Context, server *autoscaler.Server, action, reason string) error {
+	logger := logger.FromContext(ctx).
+		WithField("ip", server.Address).
+		WithField("name", server.Name)
 
-	server.Error = "Failed to ping the server"
-	server.Stopped = time.Now().Unix()
-	server.State = autoscaler.StateError
-	err = p.servers.Update(ctx, server)
+	// the server is only updated if it is still running,
+	// since the server may have been mutated by another
+	// goroutine.
+	server.Error = reason
+	switch action {
+	case pingerReplace:
+		server.State = autoscaler.StateShutdown
+	default:
+		server.Stopped = time.Now().Unix()
+		server.State = autoscaler.StateError
+	}
+	err := update(ctx, p.servers, server, autoscaler.StateRunning)
+	if err == autoscaler.ErrStateConflict {
+		return nil
+	}
 	if err != nil {
 		logger.WithError(err).
 			WithField("server", server.Name).
-			WithField("state", "error").
+			WithField("state", string(server.State)).
 			Errorln("failed to update server state")
 		return err
 	}
 
 	return nil
 }
+
+// helper function returns the published host port of the
+// runner health and dashboard endpoint, if published.
+func runnerHealthPort(ports []string) string {
+	_, bindings, err := nat.ParsePortSpecs(ports)
+	if err != nil {
+		return ""
+	}
+	for _, binding := range bindings[nat.Port("3000/tcp")] {
+		if binding.HostPort != "" {
+			return binding.HostPort
+		}
+	}
+	return ""
+}
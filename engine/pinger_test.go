@@ -3,3 +3,220 @@
 // that can be found in the LICENSE file.
 
 package engine
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/mocks"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	docker "github.com/docker/docker/client"
+	"github.com/golang/mock/gomock"
+)
+
+// This test verifies that the server is placed in an error
+// state when the agent container is not running.
+func TestPing_AgentNotRunning(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	server := &autoscaler.Server{Name: "agent-1", State: autoscaler.StateRunning}
+
+	client := mocks.NewMockAPIClient(controller)
+	client.EXPECT().Ping(gomock.Any()).Return(types.Ping{}, nil)
+	client.EXPECT().ContainerInspect(gomock.Any(), "agent").Return(agentInfo(false, 0), nil)
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().CompareAndUpdate(gomock.Any(), server, autoscaler.StateRunning).Return(nil)
+
+	p := pinger{
+		servers: store,
+		client:  mockClientFunc(client),
+		action:  pingerError,
+	}
+	p.ping(context.TODO(), server)
+
+	if got, want := server.State, autoscaler.StateError; got != want {
+		t.Errorf("Want server state %s, got %s", want, got)
+	}
+	if server.Error == "" {
+		t.Errorf("Want server error message")
+	}
+}
+
+// This test verifies that the server is not updated when
+// the server state was changed concurrently.
+func TestPing_StateConflict(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	server := &autoscaler.Server{Name: "agent-1", State: autoscaler.StateRunning}
+
+	client := mocks.NewMockAPIClient(controller)
+	client.EXPECT().Ping(gomock.Any()).Return(types.Ping{}, nil)
+	client.EXPECT().ContainerInspect(gomock.Any(), "agent").Return(agentInfo(false, 0), nil)
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().CompareAndUpdate(gomock.Any(), server, autoscaler.StateRunning).Return(autoscaler.ErrStateConflict)
+
+	p := pinger{
+		servers: store,
+		client:  mockClientFunc(client),
+		action:  pingerError,
+	}
+	if err := p.ping(context.TODO(), server); err != nil {
+		t.Errorf("Want state conflict ignored, got %s", err)
+	}
+}
+
+// This test verifies that the server is shutdown when the
+// agent container restarts more than the maximum number of
+// times between pings, and the action is replace.
+func TestPing_AgentRestarts(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	server := &autoscaler.Server{Name: "agent-1", State: autoscaler.StateRunning}
+
+	client := mocks.NewMockAPIClient(controller)
+	client.EXPECT().Ping(gomock.Any()).Return(types.Ping{}, nil)
+	client.EXPECT().ContainerInspect(gomock.Any(), "agent").Return(agentInfo(true, 5), nil)
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().CompareAndUpdate(gomock.Any(), server, autoscaler.StateRunning).Return(nil)
+
+	p := pinger{
+		servers:     store,
+		client:      mockClientFunc(client),
+		action:      pingerReplace,
+		maxRestarts: 3,
+		restarts:    map[string]int{"agent-1": 1},
+	}
+	p.ping(context.TODO(), server)
+
+	if got, want := server.State, autoscaler.StateShutdown; got != want {
+		t.Errorf("Want server state %s, got %s", want, got)
+	}
+}
+
+// This test verifies that the server is placed in an error
+// state when the runner health endpoint returns an error.
+func TestPing_AgentUnhealthy(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+	}))
+	defer ts.Close()
+	host, port := splitHostPort(t, ts.URL)
+
+	server := &autoscaler.Server{Name: "agent-1", Address: host, State: autoscaler.StateRunning}
+
+	client := mocks.NewMockAPIClient(controller)
+	client.EXPECT().Ping(gomock.Any()).Return(types.Ping{}, nil)
+	client.EXPECT().ContainerInspect(gomock.Any(), "agent").Return(agentInfo(true, 0), nil)
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().CompareAndUpdate(gomock.Any(), server, autoscaler.StateRunning).Return(nil)
+
+	p := pinger{
+		servers:    store,
+		client:     mockClientFunc(client),
+		action:     pingerError,
+		healthPort: port,
+	}
+	p.ping(context.TODO(), server)
+
+	if got, want := server.State, autoscaler.StateError; got != want {
+		t.Errorf("Want server state %s, got %s", want, got)
+	}
+}
+
+// This test verifies that a healthy server is not updated,
+// and the agent container restart count is recorded.
+func TestPing_AgentHealthy(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			w.WriteHeader(404)
+		}
+	}))
+	defer ts.Close()
+	host, port := splitHostPort(t, ts.URL)
+
+	server := &autoscaler.Server{Name: "agent-1", Address: host, State: autoscaler.StateRunning}
+
+	client := mocks.NewMockAPIClient(controller)
+	client.EXPECT().Ping(gomock.Any()).Return(types.Ping{}, nil)
+	client.EXPECT().ContainerInspect(gomock.Any(), "agent").Return(agentInfo(true, 2), nil)
+
+	p := pinger{
+		servers:     mocks.NewMockServerStore(controller),
+		client:      mockClientFunc(client),
+		action:      pingerError,
+		maxRestarts: 3,
+		healthPort:  port,
+	}
+	err := p.ping(context.TODO(), server)
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := p.restarts["agent-1"], 2; got != want {
+		t.Errorf("Want restart count %d, got %d", want, got)
+	}
+}
+
+func TestRunnerHealthPort(t *testing.T) {
+	tests := []struct {
+		ports []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"8080:80"}, ""},
+		{[]string{"8080:80", "3001:3000"}, "3001"},
+		{[]string{"3000"}, ""},
+	}
+	for _, test := range tests {
+		if got := runnerHealthPort(test.ports); got != test.want {
+			t.Errorf("Want health port %q for %v, got %q", test.want, test.ports, got)
+		}
+	}
+}
+
+func agentInfo(running bool, restarts int) container.InspectResponse {
+	return container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{
+			State:        &container.State{Running: running},
+			RestartCount: restarts,
+		},
+	}
+}
+
+func mockClientFunc(client docker.APIClient) clientFunc {
+	return func(*autoscaler.Server) (docker.APIClient, io.Closer, error) {
+		return client, nil, nil
+	}
+}
+
+func splitHostPort(t *testing.T, rawurl string) (string, string) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return host, port
+}
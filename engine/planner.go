@@ -16,6 +16,21 @@ import (
 	"github.com/dchest/uniuri"
 )
 
+// label matching modes.
+const (
+	// labelMatchExact matches stages with labels equal to
+	// the agent labels.
+	labelMatchExact = "exact"
+
+	// labelMatchSubset matches stages with labels that are
+	// a subset of the agent labels.
+	labelMatchSubset = "subset"
+
+	// labelMatchKey matches stages with label keys that are
+	// a subset of the agent label keys, ignoring values.
+	labelMatchKey = "key"
+)
+
 // a planner is responsible for capacity planning. It will assess
 // current build volume and plan the creation or termination of
 // server resources accordingly.
@@ -30,18 +45,44 @@ type planner struct {
 	cap        int           // capacity per-server
 	buffer     int           // buffer capacity to have warm and ready
 	ttu        time.Duration // minimum server age
+	ttl        time.Duration // maximum server age
 	labels     map[string]string
+	labelMatch string // label matching mode
+	schedules  []*schedule
+	hash       string // agent configuration hash
 
 	client  drone.Client
 	servers autoscaler.ServerStore
+	policy  ScalingPolicy
+
+	// forecast is used to provision servers ahead of the
+	// forecast demand. If nil, forecasting is disabled.
+	forecast *forecaster
+
+	// hibernator is used to stop idle servers instead of
+	// destroying them. If nil, hibernation is disabled.
+	hibernator autoscaler.Hibernator
+
+	// backoff is used to defer allocation after servers
+	// fail. If nil, allocation is never deferred.
+	backoff *backoff
 }
 
 func (p *planner) Plan(ctx context.Context) error {
 	// generate a unique identifier for the current
 	// execution cycle for tracing and grouping logs.
 	cycle := uniuri.New()
+	ctx = autoscaler.WithCycle(ctx, cycle)
 
 	log := logger.FromContext(ctx).WithField("id", cycle)
+
+	// the pool size and capacity buffer may be overridden
+	// by the active schedule.
+	limits := p.limits(time.Now())
+	if limits.schedule != "" {
+		log = log.WithField("schedule", limits.schedule)
+	}
+
 	log.Debugln("calculate unfinished jobs")
 
 	pending, running, err := p.count(ctx)
@@ -51,9 +92,21 @@ func (p *planner) Plan(ctx context.Context) error {
 		return err
 	}
 
+	if p.forecast != nil {
+		demand, err := p.forecast.Forecast(ctx, pending, running)
+		if err != nil {
+			log.WithError(err).
+				Warnln("cannot forecast demand")
+		} else if demand-running > pending {
+			log.WithField("forecast-demand", demand).
+				Debugln("provision for forecast demand")
+			pending = demand - running
+		}
+	}
+
 	log.Debugln("calculate server capacity")
 
-	capacity, servers, err := p.capacity(ctx)
+	capacity, servers, cordoned, errored, err := p.capacity(ctx)
 	if err != nil {
 		log.WithError(err).
 			Errorln("cannot calculate server capacity")
@@ -61,9 +114,9 @@ func (p *planner) Plan(ctx context.Context) error {
 	}
 
 	log.
-		WithField("min-pool", p.min).
-		WithField("max-pool", p.max).
-		WithField("server-buffer", p.buffer).
+		WithField("min-pool", limits.min).
+		WithField("max-pool", limits.max).
+		WithField("server-buffer", limits.buffer).
 		WithField("server-capacity", capacity).
 		WithField("server-count", servers).
 		WithField("pending-builds", pending).
@@ -76,8 +129,34 @@ func (p *planner) Plan(ctx context.Context) error {
 
 	ctx = logger.WithContext(ctx, log)
 
-	free := max(capacity-running-p.buffer, 0)
-	diff := serverDiff(pending, free, p.cap)
+	if p.backoff != nil {
+		p.backoff.Update(errored, time.Now())
+	}
+
+	// cordoned servers do not count toward capacity, which
+	// means they are replaced, and can be shutdown once idle.
+	if len(cordoned) != 0 {
+		p.retire(ctx, cordoned, limits.min)
+	}
+
+	// servers older than the maximum age are cordoned, and
+	// a replacement server is created. The replacement server
+	// counts toward capacity, to prevent allocating servers
+	// beyond the maximum server count.
+	if p.ttl != 0 {
+		recycled, _ := p.recycle(ctx, servers, limits.min, limits.max)
+		servers += recycled
+		capacity += recycled * p.cap
+	}
+
+	diff := p.scalingPolicy().Scale(ScalingState{
+		Pending:  pending,
+		Running:  running,
+		Servers:  servers,
+		Capacity: capacity,
+		Buffer:   limits.buffer,
+		Cap:      p.cap,
+	})
 
 	// if the server differential to handle the build volume
 	// is positive, we can reduce server capacity.
@@ -85,17 +164,23 @@ func (p *planner) Plan(ctx context.Context) error {
 		return p.mark(ctx,
 			// we should adjust the desired capacity to ensure
 			// we maintain the minimum required server count.
-			serverFloor(servers, abs(diff), p.min),
+			serverFloor(servers, abs(diff), limits.min),
+			limits.min,
 		)
 	}
 
 	// if the server differential to handle the build volume
 	// is positive, we need to allocate more server capacity.
 	if diff > 0 {
+		if reason, ok := p.backoff.Deferred(time.Now()); ok {
+			log.WithField("reason", reason).
+				Warnln("defer server allocation after server errors")
+			return nil
+		}
 		return p.alloc(ctx,
 			// we should adjust the desired capacity to ensure
 			// it does not exceed the max server count.
-			serverCeil(servers, diff, p.max),
+			serverCeil(servers, diff, limits.max),
 		)
 	}
 
@@ -104,11 +189,44 @@ func (p *planner) Plan(ctx context.Context) error {
 	return nil
 }
 
+// helper function returns the pool size and capacity buffer
+// at the given time. The first active schedule overrides the
+// configured values.
+func (p *planner) limits(t time.Time) limits {
+	l := limits{min: p.min, max: p.max, buffer: p.buffer}
+	for _, s := range p.schedules {
+		if s.active(t) {
+			return s.apply(l)
+		}
+	}
+	return l
+}
+
+// helper function returns the scaling policy.
+func (p *planner) scalingPolicy() ScalingPolicy {
+	if p.policy == nil {
+		return new(defaultPolicy)
+	}
+	return p.policy
+}
+
 // helper function allocates n new server instances.
 func (p *planner) alloc(ctx context.Context, n int) error {
 	logger := logger.FromContext(ctx)
 	logger.Debugf("allocate %d servers", n)
 
+	// resume hibernated servers before creating new
+	// servers, since starting a server is significantly
+	// faster than creating a server.
+	if p.hibernator != nil {
+		resumed, err := p.resume(ctx, n)
+		if err != nil {
+			logger.WithError(err).
+				Errorln("cannot resume hibernated servers")
+		}
+		n = n - resumed
+	}
+
 	namePrefix := p.namePrefix
 	if namePrefix == "" {
 		namePrefix = "agent-"
@@ -131,8 +249,9 @@ func (p *planner) alloc(ctx context.Context, n int) error {
 	return nil
 }
 
-// helper function marks instances for termination.
-func (p *planner) mark(ctx context.Context, n int) error {
+// helper function marks instances for termination, while
+// ensuring the minimum server count is maintained.
+func (p *planner) mark(ctx context.Context, n, min int) error {
 	logger := logger.FromContext(ctx)
 	logger.Debugf("terminate %d servers", n)
 
@@ -146,16 +265,23 @@ func (p *planner) mark(ctx context.Context, n int) error {
 			Errorln("cannot fetch server list")
 		return err
 	}
+	servers = uncordoned(servers)
 	sort.Sort(sort.Reverse(byCreated(servers)))
 
+	// servers running an outdated agent configuration are
+	// terminated before servers that are up-to-date.
+	sort.SliceStable(servers, func(i, j int) bool {
+		return isOutdated(servers[i], p.hash) && !isOutdated(servers[j], p.hash)
+	})
+
 	// Abort marking servers for termination if the total
 	// number of running servers, minus the total number
 	// of servers to terminate, falls below the minimum
 	// number of servers (including the buffer).
-	if len(servers)-n < p.min {
+	if len(servers)-n < min {
 		logger.WithField("servers-to-terminate", n).
 			WithField("servers-running", len(servers)).
-			WithField("min-pool", p.min).
+			WithField("min-pool", min).
 			Debugf("abort terminating instances to ensure minimum capacity met")
 		return nil
 	}
@@ -169,6 +295,13 @@ func (p *planner) mark(ctx context.Context, n int) error {
 
 	var idle []*autoscaler.Server
 	for _, server := range servers {
+		// skip protected servers
+		if server.Protected {
+			logger.WithField("server", server.Name).
+				Debugln("server is protected")
+			continue
+		}
+
 		// skip busy servers
 		if _, ok := busy[server.Name]; ok {
 			logger.WithField("server", server.Name).
@@ -202,9 +335,16 @@ func (p *planner) mark(ctx context.Context, n int) error {
 	}
 
 	for _, server := range idle {
-		server.State = autoscaler.StateShutdown
-		err := p.servers.Update(ctx, server)
-		if err != nil {
+		// stop the server instead of destroying the server,
+		// and fallback to destroying the server on error.
+		if p.hibernator != nil {
+			if err := p.hibernate(ctx, server); err == nil {
+				continue
+			}
+		}
+
+		err := transition(ctx, p.servers, server, autoscaler.StateShutdown)
+		if err != nil && err != autoscaler.ErrStateConflict {
 			logger.WithError(err).
 				WithField("server", server.Name).
 				WithField("state", "shutdown").
@@ -215,6 +355,235 @@ func (p *planner) mark(ctx context.Context, n int) error {
 	return nil
 }
 
+// helper function shuts down the cordoned servers that are
+// idle, while ensuring the number of running servers that are
+// not cordoned meets the minimum server count. Busy servers
+// are shutdown in a subsequent cycle.
+func (p *planner) retire(ctx context.Context, servers []*autoscaler.Server, min int) error {
+	logger := logger.FromContext(ctx)
+
+	running, err := p.servers.ListState(ctx, autoscaler.StateRunning)
+	if err != nil {
+		logger.WithError(err).
+			Errorln("cannot fetch server list")
+		return err
+	}
+	if len(uncordoned(running)) < min {
+		logger.WithField("servers-running", len(uncordoned(running))).
+			WithField("min-pool", min).
+			Debugln("defer shutdown of cordoned servers to ensure minimum capacity met")
+		return nil
+	}
+
+	busy, err := p.listBusy(ctx)
+	if err != nil {
+		logger.WithError(err).
+			Errorln("cannot ascertain busy server list")
+		return err
+	}
+
+	for _, server := range servers {
+		if _, ok := busy[server.Name]; ok {
+			logger.WithField("server", server.Name).
+				Debugln("cordoned server is busy")
+			continue
+		}
+
+		logger.WithField("server", server.Name).
+			Debugln("shutdown cordoned server")
+
+		err := transition(ctx, p.servers, server, autoscaler.StateShutdown)
+		if err != nil && err != autoscaler.ErrStateConflict {
+			logger.WithError(err).
+				WithField("server", server.Name).
+				WithField("state", "shutdown").
+				Errorln("cannot update server state")
+		}
+	}
+	return nil
+}
+
+// helper function cordons the oldest idle server that exceeds
+// the maximum age, creates a replacement server, and returns
+// the number of replacement servers created. The cordoned
+// server is shutdown once the replacement server is running,
+// if required to maintain the minimum server count. Servers
+// are not recycled while a replacement server is provisioned.
+// At the maximum server count, the server is cordoned without
+// a replacement if the remaining servers meet the minimum
+// server count, otherwise the replacement is created above the
+// maximum server count, so that fixed-size pools are recycled.
+func (p *planner) recycle(ctx context.Context, count, min, max int) (int, error) {
+	logger := logger.FromContext(ctx)
+
+	all, err := p.servers.List(ctx)
+	if err != nil {
+		logger.WithError(err).
+			Errorln("cannot fetch server list")
+		return 0, err
+	}
+
+	var servers []*autoscaler.Server
+	for _, server := range all {
+		switch server.State {
+		case autoscaler.StatePending,
+			autoscaler.StateCreating,
+			autoscaler.StateCreated,
+			autoscaler.StateStaging:
+			logger.WithField("server", server.Name).
+				Debugln("defer server recycling while server is provisioned")
+			return 0, nil
+		case autoscaler.StateRunning:
+			servers = append(servers, server)
+		}
+	}
+	servers = uncordoned(servers)
+	sort.Sort(byCreated(servers))
+
+	var expired []*autoscaler.Server
+	for _, server := range servers {
+		if server.Protected {
+			continue
+		}
+		// skip servers less than maxage
+		if time.Now().Before(time.Unix(server.Created, 0).Add(p.ttl)) {
+			continue
+		}
+		expired = append(expired, server)
+	}
+	if len(expired) == 0 {
+		return 0, nil
+	}
+
+	if _, ok := p.backoff.Deferred(time.Now()); ok {
+		logger.Debugln("defer server recycling after server errors")
+		return 0, nil
+	}
+
+	busy, err := p.listBusy(ctx)
+	if err != nil {
+		logger.WithError(err).
+			Errorln("cannot ascertain busy server list")
+		return 0, err
+	}
+
+	for _, server := range expired {
+		if _, ok := busy[server.Name]; ok {
+			logger.WithField("server", server.Name).
+				Debugln("expired server is busy")
+			continue
+		}
+
+		logger.
+			WithField("server", server.Name).
+			WithField("age", timeDiff(time.Now(), time.Unix(server.Created, 0))).
+			WithField("max-age", p.ttl).
+			Debugln("server max-age reached, replace server")
+
+		// at the maximum server count, the server is cordoned
+		// without a replacement if the minimum server count is
+		// met without the server.
+		replaced := 1
+		if count >= max && count-1 >= min {
+			replaced = 0
+		}
+
+		// the replacement server is created before the
+		// expired server is cordoned, to ensure the server
+		// is not cordoned without a replacement.
+		if replaced != 0 {
+			if err := p.alloc(ctx, 1); err != nil {
+				return 0, err
+			}
+		}
+
+		// only the cordoned flag is updated, since the
+		// server state may be changed concurrently.
+		server.Cordoned = true
+		err := p.servers.UpdateFlags(ctx, server)
+		if err != nil {
+			logger.WithError(err).
+				WithField("server", server.Name).
+				Errorln("cannot cordon server")
+			return replaced, err
+		}
+
+		// servers are recycled one at a time.
+		return replaced, nil
+	}
+	return 0, nil
+}
+
+// helper function stops the server and updates the server
+// state to hibernated.
+func (p *planner) hibernate(ctx context.Context, server *autoscaler.Server) error {
+	logger := logger.FromContext(ctx).
+		WithField("server", server.Name)
+
+	err := p.hibernator.Stop(ctx, &autoscaler.Instance{
+		ID:       server.ID,
+		Provider: server.Provider,
+		Name:     server.Name,
+		Address:  server.Address,
+		Region:   server.Region,
+		Image:    server.Image,
+		Size:     server.Size,
+		Metadata: server.Labels,
+	})
+	if err != nil {
+		logger.WithError(err).
+			Errorln("cannot stop server")
+		return err
+	}
+
+	server.State = autoscaler.StateHibernated
+	err = update(ctx, p.servers, server, autoscaler.StateRunning)
+	if err == autoscaler.ErrStateConflict {
+		return err
+	}
+	if err != nil {
+		logger.WithError(err).
+			WithField("state", "hibernated").
+			Errorln("cannot update server state")
+		return err
+	}
+
+	logger.Debugln("server hibernated")
+	return nil
+}
+
+// helper function resumes up to n hibernated servers, and
+// returns the number of servers resumed. The allocator
+// starts pending servers that have already been provisioned.
+func (p *planner) resume(ctx context.Context, n int) (int, error) {
+	logger := logger.FromContext(ctx)
+
+	servers, err := p.servers.ListState(ctx, autoscaler.StateHibernated)
+	if err != nil {
+		return 0, err
+	}
+	sort.Sort(sort.Reverse(byCreated(servers)))
+
+	var resumed int
+	for _, server := range servers {
+		if resumed == n {
+			break
+		}
+		err := transition(ctx, p.servers, server, autoscaler.StatePending)
+		if err == autoscaler.ErrStateConflict {
+			continue
+		}
+		if err != nil {
+			return resumed, err
+		}
+		resumed++
+
+		logger.WithField("server", server.Name).
+			Debugln("resume hibernated server")
+	}
+	return resumed, nil
+}
+
 // helper function returns the number of pending and
 // running builds in the remote Drone installation.
 func (p *planner) count(ctx context.Context) (pending, running int, err error) {
@@ -236,15 +605,26 @@ func (p *planner) count(ctx context.Context) (pending, running int, err error) {
 	return
 }
 
-// helper function returns our current capacity.
-func (p *planner) capacity(ctx context.Context) (capacity, count int, err error) {
+// helper function returns our current capacity, the running
+// servers that are cordoned and can be retired, and the servers
+// in the error state. Cordoned and errored servers do not count
+// toward capacity.
+func (p *planner) capacity(ctx context.Context) (capacity, count int, cordoned, errored []*autoscaler.Server, err error) {
 	servers, err := p.servers.List(ctx)
 	if err != nil {
-		return capacity, count, err
+		return capacity, count, cordoned, errored, err
 	}
 	for _, server := range servers {
+		if server.Cordoned {
+			if server.State == autoscaler.StateRunning && !server.Protected {
+				cordoned = append(cordoned, server)
+			}
+			continue
+		}
 		switch server.State {
-		case autoscaler.StateStopped:
+		case autoscaler.StateError:
+			errored = append(errored, server)
+		case autoscaler.StateStopped, autoscaler.StateHibernated:
 			// ignore state
 		default:
 			count++
@@ -278,7 +658,14 @@ func (p *planner) match(stage *drone.Stage) bool {
 	labelMatch := true
 
 	if len(p.labels) > 0 || len(stage.Labels) > 0 {
-		labelMatch = checkLabels(p.labels, stage.Labels)
+		switch p.labelMatch {
+		case labelMatchSubset:
+			labelMatch = checkLabelsSubset(p.labels, stage.Labels)
+		case labelMatchKey:
+			labelMatch = checkLabelKeys(p.labels, stage.Labels)
+		default:
+			labelMatch = checkLabels(p.labels, stage.Labels)
+		}
 	}
 
 	return stage.OS == p.os &&
@@ -300,6 +687,40 @@ func checkLabels(a, b map[string]string) bool {
 	return true
 }
 
+// helper function returns true if the stage labels are a
+// subset of the agent labels.
+func checkLabelsSubset(agent, stage map[string]string) bool {
+	for k, v := range stage {
+		if w, ok := agent[k]; !ok || v != w {
+			return false
+		}
+	}
+	return true
+}
+
+// helper function returns true if the stage label keys are
+// a subset of the agent label keys.
+func checkLabelKeys(agent, stage map[string]string) bool {
+	for k := range stage {
+		if _, ok := agent[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// helper function returns the servers that are not cordoned.
+// Cordoned servers are retired separately.
+func uncordoned(servers []*autoscaler.Server) []*autoscaler.Server {
+	var filtered []*autoscaler.Server
+	for _, server := range servers {
+		if !server.Cordoned {
+			filtered = append(filtered, server)
+		}
+	}
+	return filtered
+}
+
 func timeDiff(t time.Time, start time.Time) time.Duration {
 	var d time.Duration
 	if t.After(start) {
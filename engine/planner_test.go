@@ -6,6 +6,7 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -417,8 +418,8 @@ func TestPlan_ShutdownIdle(t *testing.T) {
 	store := mocks.NewMockServerStore(controller)
 	store.EXPECT().List(gomock.Any()).Return(servers, nil)
 	store.EXPECT().ListState(gomock.Any(), autoscaler.StateRunning).Return(servers, nil)
-	store.EXPECT().Update(gomock.Any(), servers[2]).Return(nil)
-	store.EXPECT().Update(gomock.Any(), servers[1]).Return(nil)
+	store.EXPECT().Transition(gomock.Any(), servers[2].Name, autoscaler.StateRunning, autoscaler.StateShutdown).Return(nil)
+	store.EXPECT().Transition(gomock.Any(), servers[1].Name, autoscaler.StateRunning, autoscaler.StateShutdown).Return(nil)
 
 	client := mocks.NewMockClient(controller)
 	client.EXPECT().Queue().Return(builds, nil)
@@ -559,7 +560,7 @@ func TestCapacity(t *testing.T) {
 		servers: store,
 	}
 
-	capacity, count, err := p.capacity(context.TODO())
+	capacity, count, _, _, err := p.capacity(context.TODO())
 	if err != nil {
 		t.Error(err)
 		return
@@ -732,3 +733,659 @@ func TestMatch(t *testing.T) {
 		}
 	}
 }
+
+func TestMatch_Labels(t *testing.T) {
+	agent := map[string]string{
+		"gpu":    "true",
+		"region": "eu",
+	}
+	tests := []struct {
+		mode   string
+		labels map[string]string
+		match  bool
+	}{
+		{mode: labelMatchExact, labels: map[string]string{"gpu": "true", "region": "eu"}, match: true},
+		{mode: labelMatchExact, labels: map[string]string{"gpu": "true"}, match: false},
+		{mode: labelMatchSubset, labels: map[string]string{"gpu": "true"}, match: true},
+		{mode: labelMatchSubset, labels: map[string]string{"gpu": "false"}, match: false},
+		{mode: labelMatchSubset, labels: map[string]string{"gpu": "true", "mem": "high"}, match: false},
+		{mode: labelMatchSubset, labels: nil, match: true},
+		{mode: labelMatchKey, labels: map[string]string{"gpu": "false"}, match: true},
+		{mode: labelMatchKey, labels: map[string]string{"mem": "high"}, match: false},
+		{mode: "", labels: map[string]string{"gpu": "true"}, match: false},
+	}
+	for i, test := range tests {
+		p := &planner{
+			os:         "linux",
+			arch:       "amd64",
+			labels:     agent,
+			labelMatch: test.mode,
+		}
+		stage := &drone.Stage{
+			OS:     "linux",
+			Arch:   "amd64",
+			Labels: test.labels,
+		}
+		if got, want := p.match(stage), test.match; got != want {
+			t.Errorf("Want match %v for mode %q at index %d", want, test.mode, i)
+		}
+	}
+}
+
+func TestListBusy_LabelSubset(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockStages := []*drone.Stage{
+		{OS: "linux", Arch: "amd64", Status: drone.StatusRunning, Machine: "agent-1", Labels: map[string]string{"gpu": "true"}},
+		{OS: "linux", Arch: "amd64", Status: drone.StatusRunning, Machine: "agent-2", Labels: map[string]string{"gpu": "false"}},
+	}
+
+	client := mocks.NewMockClient(controller)
+	client.EXPECT().Queue().Return(mockStages, nil)
+
+	p := planner{
+		os:         "linux",
+		arch:       "amd64",
+		labels:     map[string]string{"gpu": "true", "region": "eu"},
+		labelMatch: labelMatchSubset,
+		client:     client,
+	}
+	busy, err := p.listBusy(context.Background())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if _, ok := busy["agent-1"]; !ok {
+		t.Errorf("Want agent-1 busy")
+	}
+	if _, ok := busy["agent-2"]; ok {
+		t.Errorf("Want agent-2 not counted")
+	}
+}
+
+// This test verifies that idle servers are stopped and
+// hibernated instead of destroyed when hibernation is
+// enabled.
+func TestPlan_HibernateIdle(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	// x3 capacity
+	servers := []*autoscaler.Server{
+		{Name: "server1", Capacity: 2, Created: 1, State: autoscaler.StateRunning},
+		{Name: "server2", Capacity: 2, Created: 2, State: autoscaler.StateRunning},
+		{Name: "server3", Capacity: 2, Created: 3, State: autoscaler.StateRunning},
+	}
+
+	// x0 running builds
+	// x0 pending builds
+	builds := []*drone.Stage{}
+
+	// the planner sorts the server list in place.
+	server2, server3 := servers[1], servers[2]
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().List(gomock.Any()).Return(servers, nil)
+	store.EXPECT().ListState(gomock.Any(), autoscaler.StateRunning).Return(servers, nil)
+	store.EXPECT().CompareAndUpdate(gomock.Any(), server3, autoscaler.StateRunning).Return(nil)
+	store.EXPECT().CompareAndUpdate(gomock.Any(), server2, autoscaler.StateRunning).Return(nil)
+
+	client := mocks.NewMockClient(controller)
+	client.EXPECT().Queue().Return(builds, nil)
+	client.EXPECT().Queue().Return(builds, nil)
+
+	hibernator := mocks.NewMockHibernator(controller)
+	hibernator.EXPECT().Stop(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	p := planner{
+		cap:        2,
+		min:        1,
+		max:        4,
+		client:     client,
+		servers:    store,
+		hibernator: hibernator,
+	}
+
+	err := p.Plan(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := server2.State, autoscaler.StateHibernated; got != want {
+		t.Errorf("Want server state %s, got %s", want, got)
+	}
+	if got, want := server3.State, autoscaler.StateHibernated; got != want {
+		t.Errorf("Want server state %s, got %s", want, got)
+	}
+}
+
+// This test verifies that idle servers are destroyed if
+// the server cannot be stopped.
+func TestPlan_HibernateIdleError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	servers := []*autoscaler.Server{
+		{Name: "server1", Capacity: 2, Created: 1, State: autoscaler.StateRunning},
+		{Name: "server2", Capacity: 2, Created: 2, State: autoscaler.StateRunning},
+	}
+
+	server2 := servers[1]
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().List(gomock.Any()).Return(servers, nil)
+	store.EXPECT().ListState(gomock.Any(), autoscaler.StateRunning).Return(servers, nil)
+	store.EXPECT().Transition(gomock.Any(), server2.Name, autoscaler.StateRunning, autoscaler.StateShutdown).Return(nil)
+
+	client := mocks.NewMockClient(controller)
+	client.EXPECT().Queue().Return([]*drone.Stage{}, nil)
+	client.EXPECT().Queue().Return([]*drone.Stage{}, nil)
+
+	hibernator := mocks.NewMockHibernator(controller)
+	hibernator.EXPECT().Stop(gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
+
+	p := planner{
+		cap:        2,
+		min:        1,
+		max:        4,
+		client:     client,
+		servers:    store,
+		hibernator: hibernator,
+	}
+
+	err := p.Plan(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := server2.State, autoscaler.StateShutdown; got != want {
+		t.Errorf("Want server state %s, got %s", want, got)
+	}
+}
+
+// This test verifies that hibernated servers are resumed
+// before new servers are created, and that hibernated
+// servers are excluded from the server capacity.
+func TestPlan_ResumeHibernated(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	// x2 capacity, x1 hibernated
+	servers := []*autoscaler.Server{
+		{Name: "server1", Capacity: 1, State: autoscaler.StateRunning},
+		{Name: "server2", Capacity: 1, State: autoscaler.StateRunning},
+		{Name: "server3", Capacity: 1, State: autoscaler.StateHibernated, ID: "i-1234"},
+	}
+
+	// x2 running builds
+	// x2 pending builds
+	builds := []*drone.Stage{
+		{Status: drone.StatusRunning},
+		{Status: drone.StatusRunning},
+		{Status: drone.StatusPending},
+		{Status: drone.StatusPending},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().List(gomock.Any()).Return(servers, nil)
+	store.EXPECT().ListState(gomock.Any(), autoscaler.StateHibernated).Return(servers[2:], nil)
+	store.EXPECT().Transition(gomock.Any(), servers[2].Name, autoscaler.StateHibernated, autoscaler.StatePending).Return(nil)
+	store.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+	client := mocks.NewMockClient(controller)
+	client.EXPECT().Queue().Return(builds, nil)
+
+	p := planner{
+		cap:        1,
+		min:        2,
+		max:        4,
+		client:     client,
+		servers:    store,
+		hibernator: mocks.NewMockHibernator(controller),
+	}
+
+	err := p.Plan(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := servers[2].State, autoscaler.StatePending; got != want {
+		t.Errorf("Want server state %s, got %s", want, got)
+	}
+}
+
+// This test verifies that protected servers are not
+// marked for termination.
+func TestPlan_ShutdownIdleProtected(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	servers := []*autoscaler.Server{
+		{Name: "server1", Capacity: 2, Created: 1, State: autoscaler.StateRunning},
+		{Name: "server2", Capacity: 2, Created: 2, State: autoscaler.StateRunning},
+		{Name: "server3", Capacity: 2, Created: 3, State: autoscaler.StateRunning, Protected: true},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().List(gomock.Any()).Return(servers, nil)
+	store.EXPECT().ListState(gomock.Any(), autoscaler.StateRunning).Return(servers, nil)
+	store.EXPECT().Transition(gomock.Any(), servers[1].Name, autoscaler.StateRunning, autoscaler.StateShutdown).Return(nil)
+	store.EXPECT().Transition(gomock.Any(), servers[0].Name, autoscaler.StateRunning, autoscaler.StateShutdown).Return(nil)
+
+	client := mocks.NewMockClient(controller)
+	client.EXPECT().Queue().Return([]*drone.Stage{}, nil)
+	client.EXPECT().Queue().Return([]*drone.Stage{}, nil)
+
+	p := planner{
+		cap:     2,
+		min:     1,
+		max:     4,
+		client:  client,
+		servers: store,
+	}
+
+	err := p.Plan(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := servers[2].State, autoscaler.StateRunning; got != want {
+		t.Errorf("Want protected server state %s, got %s", want, got)
+	}
+}
+
+// This test verifies that cordoned servers are excluded
+// from the server capacity, and are replaced and shutdown
+// once idle.
+func TestPlan_Cordoned(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	servers := []*autoscaler.Server{
+		{Name: "server1", Capacity: 2, State: autoscaler.StateRunning},
+		{Name: "server2", Capacity: 2, State: autoscaler.StateRunning, Cordoned: true},
+		{Name: "server3", Capacity: 2, State: autoscaler.StateRunning, Cordoned: true},
+	}
+
+	// x2 running builds
+	// x2 pending builds
+	builds := []*drone.Stage{
+		{Status: drone.StatusRunning, Machine: "server1"},
+		{Status: drone.StatusRunning, Machine: "server3"},
+		{Status: drone.StatusPending},
+		{Status: drone.StatusPending},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().List(gomock.Any()).Return(servers, nil)
+	store.EXPECT().ListState(gomock.Any(), autoscaler.StateRunning).Return(servers, nil)
+	store.EXPECT().Transition(gomock.Any(), servers[1].Name, autoscaler.StateRunning, autoscaler.StateShutdown).Return(nil)
+	store.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+	client := mocks.NewMockClient(controller)
+	client.EXPECT().Queue().Return(builds, nil).Times(2)
+
+	p := planner{
+		cap:     2,
+		min:     1,
+		max:     4,
+		client:  client,
+		servers: store,
+	}
+
+	err := p.Plan(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := servers[1].State, autoscaler.StateShutdown; got != want {
+		t.Errorf("Want idle cordoned server state %s, got %s", want, got)
+	}
+	if got, want := servers[2].State, autoscaler.StateRunning; got != want {
+		t.Errorf("Want busy cordoned server state %s, got %s", want, got)
+	}
+}
+
+// This test verifies that cordoned servers are not shutdown
+// if the running servers would fall below the min pool size.
+func TestPlan_CordonedMinPool(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	servers := []*autoscaler.Server{
+		{Name: "server1", Capacity: 2, State: autoscaler.StateRunning, Cordoned: true},
+		{Name: "server2", Capacity: 2, State: autoscaler.StateStaging},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().List(gomock.Any()).Return(servers, nil)
+	store.EXPECT().ListState(gomock.Any(), autoscaler.StateRunning).Return(servers[:1], nil)
+
+	client := mocks.NewMockClient(controller)
+	client.EXPECT().Queue().Return([]*drone.Stage{}, nil)
+
+	p := planner{
+		cap:     2,
+		min:     1,
+		max:     4,
+		client:  client,
+		servers: store,
+	}
+
+	err := p.Plan(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := servers[0].State, autoscaler.StateRunning; got != want {
+		t.Errorf("Want cordoned server state %s, got %s", want, got)
+	}
+}
+
+// This test verifies that the oldest idle server that exceeds
+// the max age is cordoned, and a replacement is created.
+func TestPlan_Recycle(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	now := time.Now()
+	servers := []*autoscaler.Server{
+		{Name: "server1", Capacity: 2, State: autoscaler.StateRunning, Created: now.Add(-time.Hour * 26).Unix()},
+		{Name: "server2", Capacity: 2, State: autoscaler.StateRunning, Created: now.Add(-time.Hour * 25).Unix()},
+		{Name: "server3", Capacity: 2, State: autoscaler.StateRunning, Created: now.Add(-time.Hour * 25).Unix()},
+		{Name: "server4", Capacity: 2, State: autoscaler.StateRunning, Created: now.Add(-time.Hour).Unix()},
+	}
+
+	// x4 running builds
+	builds := []*drone.Stage{
+		{Status: drone.StatusRunning, Machine: "server1"},
+		{Status: drone.StatusRunning, Machine: "server3"},
+		{Status: drone.StatusRunning, Machine: "server4"},
+		{Status: drone.StatusRunning, Machine: "server4"},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().List(gomock.Any()).Return(servers, nil).Times(2)
+	store.EXPECT().ListState(gomock.Any(), autoscaler.StateRunning).Return(servers, nil)
+	store.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	store.EXPECT().UpdateFlags(gomock.Any(), servers[1]).Return(nil)
+
+	client := mocks.NewMockClient(controller)
+	client.EXPECT().Queue().Return(builds, nil).Times(2)
+
+	p := planner{
+		cap:     2,
+		min:     2,
+		max:     5,
+		ttl:     time.Hour * 24,
+		client:  client,
+		servers: store,
+	}
+
+	err := p.Plan(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+	if !servers[1].Cordoned {
+		t.Errorf("Want expired idle server cordoned")
+	}
+	if servers[0].Cordoned || servers[2].Cordoned {
+		t.Errorf("Want expired busy servers not cordoned")
+	}
+}
+
+// This test verifies that an expired server is cordoned
+// without a replacement at the maximum server count, if the
+// remaining servers meet the minimum server count.
+func TestPlan_RecycleMaxServers(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	now := time.Now()
+	servers := []*autoscaler.Server{
+		{Name: "server1", Capacity: 2, State: autoscaler.StateRunning, Created: now.Add(-time.Hour * 26).Unix()},
+		{Name: "server2", Capacity: 2, State: autoscaler.StateRunning, Created: now.Add(-time.Hour * 25).Unix()},
+	}
+
+	// x2 running builds, x2 pending builds
+	builds := []*drone.Stage{
+		{Status: drone.StatusRunning, Machine: "server1"},
+		{Status: drone.StatusRunning, Machine: "server1"},
+		{Status: drone.StatusPending},
+		{Status: drone.StatusPending},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().List(gomock.Any()).Return(servers, nil).Times(2)
+	store.EXPECT().UpdateFlags(gomock.Any(), servers[1]).Return(nil)
+
+	client := mocks.NewMockClient(controller)
+	client.EXPECT().Queue().Return(builds, nil).Times(2)
+
+	p := planner{
+		cap:     2,
+		min:     1,
+		max:     2,
+		ttl:     time.Hour * 24,
+		client:  client,
+		servers: store,
+	}
+
+	err := p.Plan(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+	if servers[0].Cordoned {
+		t.Errorf("Want expired busy server not cordoned")
+	}
+	if !servers[1].Cordoned {
+		t.Errorf("Want expired idle server cordoned at maximum server count")
+	}
+}
+
+// This test verifies that an expired server in a fixed-size
+// pool is recycled, and the replacement server is created
+// above the maximum server count.
+func TestPlan_RecycleFixedSize(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	now := time.Now()
+	servers := []*autoscaler.Server{
+		{Name: "server1", Capacity: 2, State: autoscaler.StateRunning, Created: now.Add(-time.Hour * 26).Unix()},
+		{Name: "server2", Capacity: 2, State: autoscaler.StateRunning, Created: now.Add(-time.Hour * 25).Unix()},
+	}
+
+	// x2 running builds, x4 pending builds
+	builds := []*drone.Stage{
+		{Status: drone.StatusRunning, Machine: "server1"},
+		{Status: drone.StatusRunning, Machine: "server1"},
+		{Status: drone.StatusPending},
+		{Status: drone.StatusPending},
+		{Status: drone.StatusPending},
+		{Status: drone.StatusPending},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().List(gomock.Any()).Return(servers, nil).Times(2)
+	store.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	store.EXPECT().UpdateFlags(gomock.Any(), servers[1]).Return(nil)
+
+	client := mocks.NewMockClient(controller)
+	client.EXPECT().Queue().Return(builds, nil).Times(2)
+
+	p := planner{
+		cap:     2,
+		min:     2,
+		max:     2,
+		ttl:     time.Hour * 24,
+		client:  client,
+		servers: store,
+	}
+
+	err := p.Plan(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+	if !servers[1].Cordoned {
+		t.Errorf("Want expired idle server cordoned in fixed-size pool")
+	}
+}
+
+// This test verifies that expired servers are not recycled
+// while a replacement server is provisioned.
+func TestPlan_RecycleInFlight(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	now := time.Now()
+	servers := []*autoscaler.Server{
+		{Name: "server1", Capacity: 2, State: autoscaler.StateRunning, Created: now.Add(-time.Hour * 26).Unix()},
+		{Name: "server2", Capacity: 2, State: autoscaler.StateRunning, Created: now.Add(-time.Hour * 25).Unix()},
+		{Name: "server3", Capacity: 2, State: autoscaler.StateCreating, Created: now.Unix()},
+	}
+
+	// x4 running builds
+	builds := []*drone.Stage{
+		{Status: drone.StatusRunning, Machine: "server1"},
+		{Status: drone.StatusRunning, Machine: "server1"},
+		{Status: drone.StatusRunning, Machine: "server2"},
+		{Status: drone.StatusRunning, Machine: "server2"},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().List(gomock.Any()).Return(servers, nil).Times(2)
+
+	client := mocks.NewMockClient(controller)
+	client.EXPECT().Queue().Return(builds, nil)
+
+	p := planner{
+		cap:     2,
+		min:     3,
+		max:     4,
+		ttl:     time.Hour * 24,
+		client:  client,
+		servers: store,
+	}
+
+	err := p.Plan(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+	if servers[0].Cordoned || servers[1].Cordoned {
+		t.Errorf("Want expired servers not cordoned while a server is provisioned")
+	}
+}
+
+// This test verifies that servers running an outdated agent
+// configuration are terminated first.
+func TestPlan_ShutdownOutdated(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	servers := []*autoscaler.Server{
+		{Name: "server1", Capacity: 2, Created: 1, State: autoscaler.StateRunning, Hash: "a1b2c3"},
+		{Name: "server2", Capacity: 2, Created: 2, State: autoscaler.StateRunning, Hash: "d4e5f6"},
+		{Name: "server3", Capacity: 2, Created: 3, State: autoscaler.StateRunning, Hash: "d4e5f6"},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().List(gomock.Any()).Return(servers, nil)
+	store.EXPECT().ListState(gomock.Any(), autoscaler.StateRunning).Return(servers, nil)
+	store.EXPECT().Transition(gomock.Any(), servers[0].Name, autoscaler.StateRunning, autoscaler.StateShutdown).Return(nil)
+	store.EXPECT().Transition(gomock.Any(), servers[2].Name, autoscaler.StateRunning, autoscaler.StateShutdown).Return(nil)
+
+	client := mocks.NewMockClient(controller)
+	client.EXPECT().Queue().Return([]*drone.Stage{}, nil)
+	client.EXPECT().Queue().Return([]*drone.Stage{}, nil)
+
+	p := planner{
+		cap:     2,
+		min:     1,
+		max:     4,
+		hash:    "d4e5f6",
+		client:  client,
+		servers: store,
+	}
+
+	err := p.Plan(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := servers[1].State, autoscaler.StateRunning; got != want {
+		t.Errorf("Want up-to-date server state %s, got %s", want, got)
+	}
+}
+
+// This test verifies that errored servers are excluded from
+// the server capacity, and are replaced.
+func TestPlan_ReplaceErrored(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	servers := []*autoscaler.Server{
+		{Name: "server1", Capacity: 2, State: autoscaler.StateError, Error: "cannot pull image", Updated: time.Now().Add(-time.Hour * 2).Unix()},
+		{Name: "server2", Capacity: 2, State: autoscaler.StateError, Error: "cannot pull image", Updated: time.Now().Add(-time.Hour * 2).Unix()},
+	}
+
+	// x4 pending builds
+	builds := []*drone.Stage{
+		{Status: drone.StatusPending},
+		{Status: drone.StatusPending},
+		{Status: drone.StatusPending},
+		{Status: drone.StatusPending},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().List(gomock.Any()).Return(servers, nil)
+	store.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	client := mocks.NewMockClient(controller)
+	client.EXPECT().Queue().Return(builds, nil)
+
+	p := planner{
+		cap:     2,
+		min:     2,
+		max:     4,
+		client:  client,
+		servers: store,
+		backoff: &backoff{base: time.Minute, max: time.Hour, budget: 5},
+	}
+
+	err := p.Plan(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// This test verifies that errored servers are not replaced
+// while allocation is deferred after recent server errors.
+func TestPlan_ReplaceErroredBackoff(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	servers := []*autoscaler.Server{
+		{Name: "server1", Capacity: 2, State: autoscaler.StateRunning},
+		{Name: "server2", Capacity: 2, State: autoscaler.StateError, Error: "cannot pull image", Updated: time.Now().Unix()},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().List(gomock.Any()).Return(servers, nil)
+
+	client := mocks.NewMockClient(controller)
+	client.EXPECT().Queue().Return([]*drone.Stage{
+		{Status: drone.StatusPending},
+		{Status: drone.StatusPending},
+		{Status: drone.StatusPending},
+		{Status: drone.StatusPending},
+	}, nil)
+
+	p := planner{
+		cap:     2,
+		min:     2,
+		max:     4,
+		client:  client,
+		servers: store,
+		backoff: &backoff{base: time.Minute, max: time.Hour, budget: 5},
+	}
+
+	err := p.Plan(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+}
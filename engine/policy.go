@@ -0,0 +1,140 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package engine
+
+import (
+	"math"
+
+	"github.com/drone/autoscaler/config"
+	"github.com/drone/autoscaler/logger"
+)
+
+// scaling policy names.
+const (
+	policyDefault     = "default"
+	policyStep        = "step"
+	policyTarget      = "target"
+	policyConsecutive = "consecutive"
+)
+
+// ScalingState provides the queue state and the current
+// capacity used to calculate the desired server delta.
+type ScalingState struct {
+	Pending  int // pending stages
+	Running  int // running stages
+	Servers  int // server count
+	Capacity int // total server capacity
+	Buffer   int // buffer capacity to have warm and ready
+	Cap      int // capacity per-server
+}
+
+// ScalingPolicy calculates the desired server delta. A
+// positive delta allocates new servers, and a negative
+// delta terminates servers. The planner clamps the delta
+// to ensure the min and max server count is maintained.
+type ScalingPolicy interface {
+	Scale(state ScalingState) int
+}
+
+// helper function returns the scaling policy for the
+// configuration. The policy name is validated when the
+// configuration is loaded. If the named policy does not
+// exist the default policy is returned.
+func newPolicy(config config.Config) ScalingPolicy {
+	switch config.Policy.Name {
+	case policyDefault, "":
+		return new(defaultPolicy)
+	case policyStep:
+		return &stepPolicy{step: config.Policy.Step}
+	case policyTarget:
+		return &targetPolicy{target: config.Policy.Target}
+	case policyConsecutive:
+		return &consecutivePolicy{
+			policy: new(defaultPolicy),
+			cycles: config.Policy.Cycles,
+		}
+	default:
+		logger.Default.
+			WithField("policy", config.Policy.Name).
+			Warnln("unknown scaling policy, using default")
+		return new(defaultPolicy)
+	}
+}
+
+// defaultPolicy allocates enough servers to handle the
+// pending stages, and terminates servers when there is
+// free capacity.
+type defaultPolicy struct{}
+
+func (*defaultPolicy) Scale(state ScalingState) int {
+	free := max(state.Capacity-state.Running-state.Buffer, 0)
+	return serverDiff(state.Pending, free, state.Cap)
+}
+
+// stepPolicy allocates and terminates servers in multiples
+// of the step size. Server allocation is rounded up to the
+// next step, and termination is rounded down to the previous
+// step, favoring queue latency over cost.
+type stepPolicy struct {
+	step int
+}
+
+func (p *stepPolicy) Scale(state ScalingState) int {
+	diff := new(defaultPolicy).Scale(state)
+	step := max(p.step, 1)
+	switch {
+	case diff > 0:
+		return int(math.Ceil(float64(diff)/float64(step))) * step
+	case diff < 0:
+		return -(abs(diff) / step * step)
+	default:
+		return 0
+	}
+}
+
+// targetPolicy allocates and terminates servers to keep the
+// utilization of the server capacity at the target percentage.
+type targetPolicy struct {
+	target int // target utilization percentage
+}
+
+func (p *targetPolicy) Scale(state ScalingState) int {
+	target := p.target
+	if target <= 0 || target > 100 {
+		target = 100
+	}
+	demand := state.Pending + state.Running + state.Buffer
+	desired := int(
+		math.Ceil(
+			float64(demand) * 100 /
+				float64(max(state.Cap, 1)*target),
+		),
+	)
+	return desired - state.Servers
+}
+
+// consecutivePolicy wraps a scaling policy and only allocates
+// servers after the wrapped policy requests new servers for
+// the configured number of consecutive cycles, favoring cost
+// over queue latency. Servers are terminated immediately.
+type consecutivePolicy struct {
+	policy ScalingPolicy
+	cycles int
+	count  int
+}
+
+func (p *consecutivePolicy) Scale(state ScalingState) int {
+	diff := p.policy.Scale(state)
+	if diff <= 0 {
+		p.count = 0
+		return diff
+	}
+	p.count++
+	if p.count < p.cycles {
+		return 0
+	}
+	p.count = 0
+	return diff
+}
@@ -0,0 +1,129 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/drone/autoscaler/config"
+)
+
+func TestNewPolicy(t *testing.T) {
+	tests := []struct {
+		name string
+		want ScalingPolicy
+	}{
+		{"", new(defaultPolicy)},
+		{"default", new(defaultPolicy)},
+		{"step", new(stepPolicy)},
+		{"target", new(targetPolicy)},
+		{"consecutive", new(consecutivePolicy)},
+		{"unknown", new(defaultPolicy)},
+	}
+	for _, test := range tests {
+		conf := config.Config{}
+		conf.Policy.Name = test.name
+		got := newPolicy(conf)
+		if gotT, wantT := typeName(got), typeName(test.want); gotT != wantT {
+			t.Errorf("Want policy %s for %q, got %s", wantT, test.name, gotT)
+		}
+	}
+}
+
+func TestDefaultPolicy(t *testing.T) {
+	tests := []struct {
+		state ScalingState
+		want  int
+	}{
+		// no pending builds and no free capacity.
+		{ScalingState{Pending: 0, Running: 4, Servers: 2, Capacity: 4, Cap: 2}, 0},
+		// pending builds exceed the free capacity.
+		{ScalingState{Pending: 5, Running: 2, Servers: 2, Capacity: 4, Cap: 2}, 2},
+		// free capacity exceeds the pending builds.
+		{ScalingState{Pending: 0, Running: 0, Servers: 2, Capacity: 4, Cap: 2}, -2},
+		// buffer capacity is reserved.
+		{ScalingState{Pending: 0, Running: 0, Servers: 2, Capacity: 4, Buffer: 2, Cap: 2}, -1},
+	}
+	for i, test := range tests {
+		if got, want := new(defaultPolicy).Scale(test.state), test.want; got != want {
+			t.Errorf("Want delta %d at index %d, got %d", want, i, got)
+		}
+	}
+}
+
+func TestStepPolicy(t *testing.T) {
+	tests := []struct {
+		state ScalingState
+		want  int
+	}{
+		{ScalingState{Pending: 5, Running: 2, Servers: 2, Capacity: 4, Cap: 2}, 3},
+		{ScalingState{Pending: 0, Running: 0, Servers: 5, Capacity: 10, Cap: 2}, -3},
+		{ScalingState{Pending: 0, Running: 0, Servers: 2, Capacity: 4, Cap: 2}, 0},
+	}
+	p := &stepPolicy{step: 3}
+	for i, test := range tests {
+		if got, want := p.Scale(test.state), test.want; got != want {
+			t.Errorf("Want delta %d at index %d, got %d", want, i, got)
+		}
+	}
+}
+
+func TestTargetPolicy(t *testing.T) {
+	tests := []struct {
+		target int
+		state  ScalingState
+		want   int
+	}{
+		{50, ScalingState{Pending: 2, Running: 2, Servers: 2, Capacity: 4, Cap: 2}, 2},
+		{100, ScalingState{Pending: 2, Running: 2, Servers: 2, Capacity: 4, Cap: 2}, 0},
+		{50, ScalingState{Pending: 0, Running: 1, Servers: 4, Capacity: 8, Cap: 2}, -3},
+		{0, ScalingState{Pending: 0, Running: 0, Servers: 2, Capacity: 4, Cap: 2}, -2},
+		// zero capacity per-server is treated as one.
+		{100, ScalingState{Pending: 2, Running: 0, Servers: 0, Capacity: 0, Cap: 0}, 2},
+	}
+	for i, test := range tests {
+		p := &targetPolicy{target: test.target}
+		if got, want := p.Scale(test.state), test.want; got != want {
+			t.Errorf("Want delta %d at index %d, got %d", want, i, got)
+		}
+	}
+}
+
+func TestConsecutivePolicy(t *testing.T) {
+	up := ScalingState{Pending: 5, Running: 2, Servers: 2, Capacity: 4, Cap: 2}
+	down := ScalingState{Pending: 0, Running: 0, Servers: 2, Capacity: 4, Cap: 2}
+
+	p := &consecutivePolicy{policy: new(defaultPolicy), cycles: 3}
+	for i, want := range []int{0, 0, 2} {
+		if got := p.Scale(up); got != want {
+			t.Errorf("Want delta %d at cycle %d, got %d", want, i, got)
+		}
+	}
+
+	// an interrupted sequence resets the cycle count, and
+	// servers are terminated immediately.
+	p.Scale(up)
+	if got, want := p.Scale(down), -2; got != want {
+		t.Errorf("Want delta %d, got %d", want, got)
+	}
+	if got, want := p.Scale(up), 0; got != want {
+		t.Errorf("Want delta %d, got %d", want, got)
+	}
+}
+
+func typeName(policy ScalingPolicy) string {
+	switch policy.(type) {
+	case *defaultPolicy:
+		return "default"
+	case *stepPolicy:
+		return "step"
+	case *targetPolicy:
+		return "target"
+	case *consecutivePolicy:
+		return "consecutive"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package engine
+
+import (
+	"context"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/config"
+)
+
+// Pool defines a named pool of servers with its own
+// configuration and hosting provider.
+type Pool struct {
+	Name     string
+	Config   config.Config
+	Provider autoscaler.Provider
+}
+
+// poolStore wraps the server store and limits the servers
+// to the named pool. Servers created with the store are
+// assigned to the named pool.
+type poolStore struct {
+	autoscaler.ServerStore
+
+	name string
+
+	// legacy is true if the pool claims servers that were
+	// created before pools were supported, and therefore
+	// are not assigned to any pool.
+	legacy bool
+}
+
+func (s *poolStore) List(ctx context.Context) ([]*autoscaler.Server, error) {
+	servers, err := s.ServerStore.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.filter(servers), nil
+}
+
+func (s *poolStore) ListState(ctx context.Context, state autoscaler.ServerState) ([]*autoscaler.Server, error) {
+	servers, err := s.ServerStore.ListState(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	return s.filter(servers), nil
+}
+
+func (s *poolStore) Create(ctx context.Context, server *autoscaler.Server) error {
+	server.Pool = s.name
+	// the pool name is added to the server labels, so that
+	// servers can be filtered by pool.
+	if s.name != "" {
+		if server.Labels == nil {
+			server.Labels = autoscaler.Labels{}
+		}
+		server.Labels["pool"] = s.name
+	}
+	return s.ServerStore.Create(ctx, server)
+}
+
+// helper function returns the servers that belong to the
+// named pool.
+func (s *poolStore) filter(servers []*autoscaler.Server) []*autoscaler.Server {
+	var filtered []*autoscaler.Server
+	for _, server := range servers {
+		if server.Pool == s.name || (s.legacy && server.Pool == "") {
+			filtered = append(filtered, server)
+		}
+	}
+	return filtered
+}
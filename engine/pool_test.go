@@ -0,0 +1,90 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/mocks"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestPoolStore_List(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockctx := context.Background()
+	mockServers := []*autoscaler.Server{
+		{Name: "legacy", Pool: ""},
+		{Name: "amd64", Pool: "amd64"},
+		{Name: "arm64", Pool: "arm64"},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().List(mockctx).Return(mockServers, nil).Times(2)
+
+	s := &poolStore{ServerStore: store, name: "amd64", legacy: true}
+	servers, _ := s.List(mockctx)
+	if got, want := len(servers), 2; got != want {
+		t.Errorf("Want %d servers, got %d", want, got)
+	}
+
+	s = &poolStore{ServerStore: store, name: "arm64"}
+	servers, _ = s.List(mockctx)
+	if got, want := len(servers), 1; got != want {
+		t.Errorf("Want %d servers, got %d", want, got)
+		return
+	}
+	if got, want := servers[0].Name, "arm64"; got != want {
+		t.Errorf("Want server %s, got %s", want, got)
+	}
+}
+
+func TestPoolStore_ListState(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockctx := context.Background()
+	mockServers := []*autoscaler.Server{
+		{Name: "amd64", Pool: "amd64"},
+		{Name: "arm64", Pool: "arm64"},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().ListState(mockctx, autoscaler.StatePending).Return(mockServers, nil)
+
+	s := &poolStore{ServerStore: store, name: "arm64"}
+	servers, _ := s.ListState(mockctx, autoscaler.StatePending)
+	if got, want := len(servers), 1; got != want {
+		t.Errorf("Want %d servers, got %d", want, got)
+		return
+	}
+	if got, want := servers[0].Name, "arm64"; got != want {
+		t.Errorf("Want server %s, got %s", want, got)
+	}
+}
+
+func TestPoolStore_Create(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockctx := context.Background()
+	mockServer := &autoscaler.Server{Name: "arm64"}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().Create(mockctx, mockServer).Return(nil)
+
+	s := &poolStore{ServerStore: store, name: "arm64"}
+	s.Create(mockctx, mockServer)
+	if got, want := mockServer.Pool, "arm64"; got != want {
+		t.Errorf("Want server pool %s, got %s", want, got)
+	}
+	if got, want := mockServer.Labels["pool"], "arm64"; got != want {
+		t.Errorf("Want server pool label %s, got %s", want, got)
+	}
+}
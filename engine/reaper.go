@@ -83,6 +83,7 @@ func (r *reaper) reap(ctx context.Context, server *autoscaler.Server) error {
 			Region:   server.Region,
 			Image:    server.Image,
 			Size:     server.Size,
+			Metadata: server.Labels,
 		}
 
 		err := r.provider.Destroy(ctx, in)
@@ -111,7 +112,10 @@ func (r *reaper) reap(ctx context.Context, server *autoscaler.Server) error {
 
 	server.Stopped = time.Now().Unix()
 	server.State = autoscaler.StateStopped
-	err := r.servers.Update(ctx, server)
+	err := update(ctx, r.servers, server, autoscaler.StateError)
+	if err == autoscaler.ErrStateConflict {
+		return err
+	}
 	if err != nil {
 		logger.WithError(err).
 			WithField("server", server.Name).
@@ -0,0 +1,186 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/logger"
+	"github.com/drone/autoscaler/metrics"
+)
+
+// reconcile actions.
+const (
+	reconcileDestroy = "destroy"
+	reconcileAdopt   = "adopt"
+)
+
+// defines the time after which a server that is being
+// created, but does not have an instance identifier, is no
+// longer considered in-flight. This matches the timeout used
+// by the allocator when creating servers.
+const reconcileGrace = time.Hour
+
+// a reconciler compares the servers that belong to the
+// autoscaler in the provider to the servers in the store,
+// and destroys or adopts servers that are not in the store.
+// Orphaned servers are left behind when the autoscaler exits
+// before the server is saved, or when the server record is
+// deleted without destroying the server.
+type reconciler struct {
+	servers  autoscaler.ServerStore
+	lister   autoscaler.Lister
+	provider autoscaler.Provider
+	metrics  metrics.Collector
+	pool     string
+	action   string
+	interval time.Duration
+}
+
+func (r *reconciler) Reconcile(ctx context.Context) error {
+	if r.lister == nil {
+		return nil
+	}
+
+	logger := logger.FromContext(ctx)
+
+	instances, err := r.lister.List(ctx)
+	if err != nil {
+		logger.WithError(err).
+			Errorln("cannot list provider servers")
+		return err
+	}
+
+	// the servers are listed after the instances, so that
+	// instances created in the meantime are in the store.
+	servers, err := r.servers.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	ids := map[string]*autoscaler.Server{}
+	names := map[string]*autoscaler.Server{}
+	for _, server := range servers {
+		if server.ID != "" && server.State != autoscaler.StateStopped {
+			ids[server.ID] = server
+		}
+		names[server.Name] = server
+	}
+
+	now := time.Now()
+	for _, instance := range instances {
+		if _, ok := ids[instance.ID]; ok {
+			continue
+		}
+		server := names[instance.Name]
+		if server != nil && r.creating(server, now) {
+			continue
+		}
+
+		r.metrics.IncrServerOrphan(r.action)
+
+		logger.WithField("id", instance.ID).
+			WithField("server", instance.Name).
+			WithField("action", r.action).
+			Warnln("orphaned server detected")
+
+		switch r.action {
+		case reconcileAdopt:
+			r.adopt(ctx, instance, server)
+		default:
+			r.destroy(ctx, instance)
+		}
+	}
+	return nil
+}
+
+// helper function returns true if the server is being
+// created, and the instance identifier is not yet saved.
+func (r *reconciler) creating(server *autoscaler.Server, now time.Time) bool {
+	switch server.State {
+	case autoscaler.StatePending, autoscaler.StateCreating:
+		return server.ID == "" && now.Sub(time.Unix(server.Created, 0)) < reconcileGrace
+	default:
+		return false
+	}
+}
+
+// helper function destroys the orphaned server.
+func (r *reconciler) destroy(ctx context.Context, instance *autoscaler.Instance) error {
+	logger := logger.FromContext(ctx).
+		WithField("id", instance.ID).
+		WithField("server", instance.Name)
+
+	err := r.provider.Destroy(ctx, instance)
+	if err == autoscaler.ErrInstanceNotFound {
+		logger.Infoln("orphaned server no longer exists. nothing to destroy")
+		return nil
+	}
+	if err != nil {
+		logger.WithError(err).
+			Errorln("cannot destroy orphaned server")
+		return err
+	}
+	logger.Infoln("destroyed orphaned server")
+	return nil
+}
+
+// adoptedReason is the error recorded for an adopted orphaned
+// server. The server did not fail to allocate or install, and
+// is therefore excluded from the allocation backoff.
+const adoptedReason = "adopted orphaned server"
+
+// helper function saves the orphaned server to the store.
+// The server certificates are not known, which means the
+// autoscaler cannot connect to the server, and the server is
+// therefore saved in the error state. This makes the server
+// visible in the dashboard and api, and it is destroyed by
+// the reaper or the operator.
+func (r *reconciler) adopt(ctx context.Context, instance *autoscaler.Instance, server *autoscaler.Server) error {
+	logger := logger.FromContext(ctx).
+		WithField("id", instance.ID).
+		WithField("server", instance.Name)
+
+	var from autoscaler.ServerState
+	create := server == nil
+	if create {
+		server = &autoscaler.Server{
+			Name:    instance.Name,
+			Pool:    r.pool,
+			Created: time.Now().Unix(),
+		}
+	} else {
+		from = server.State
+	}
+	server.ID = instance.ID
+	server.Provider = instance.Provider
+	server.Address = instance.Address
+	server.Region = instance.Region
+	server.Image = instance.Image
+	server.Size = instance.Size
+	server.State = autoscaler.StateError
+	server.Error = adoptedReason
+	server.Stopped = 0
+	copyMetadata(server, instance)
+
+	var err error
+	if create {
+		err = r.servers.Create(ctx, server)
+	} else {
+		err = update(ctx, r.servers, server, from)
+	}
+	if err == autoscaler.ErrStateConflict {
+		return err
+	}
+	if err != nil {
+		logger.WithError(err).
+			Errorln("cannot adopt orphaned server")
+		return err
+	}
+	logger.Infoln("adopted orphaned server")
+	return nil
+}
@@ -0,0 +1,144 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/mocks"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestReconcile_Destroy(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	instances := []*autoscaler.Instance{
+		{ID: "i-1", Name: "agent-1"}, // managed
+		{ID: "i-2", Name: "agent-2"}, // in-flight
+		{ID: "i-3", Name: "agent-3"}, // orphaned
+		{ID: "i-4", Name: "agent-4"}, // stopped, but still exists
+	}
+	servers := []*autoscaler.Server{
+		{ID: "i-1", Name: "agent-1", State: autoscaler.StateRunning},
+		{ID: "", Name: "agent-2", State: autoscaler.StateCreating, Created: time.Now().Unix()},
+		{ID: "i-4", Name: "agent-4", State: autoscaler.StateStopped},
+	}
+
+	lister := mocks.NewMockLister(controller)
+	lister.EXPECT().List(gomock.Any()).Return(instances, nil)
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().List(gomock.Any()).Return(servers, nil)
+
+	provider := mocks.NewMockProvider(controller)
+	provider.EXPECT().Destroy(gomock.Any(), instances[2]).Return(nil)
+	provider.EXPECT().Destroy(gomock.Any(), instances[3]).Return(autoscaler.ErrInstanceNotFound)
+
+	metrics := mocks.NewMockCollector(controller)
+	metrics.EXPECT().IncrServerOrphan(reconcileDestroy).Times(2)
+
+	r := reconciler{
+		servers:  store,
+		lister:   lister,
+		provider: provider,
+		metrics:  metrics,
+		action:   reconcileDestroy,
+	}
+	err := r.Reconcile(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReconcile_Adopt(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	instances := []*autoscaler.Instance{
+		{ID: "i-1", Name: "agent-1", Provider: autoscaler.ProviderAmazon, Address: "1.2.3.4"},
+		{ID: "i-2", Name: "agent-2", Provider: autoscaler.ProviderAmazon, Address: "1.2.3.5"},
+	}
+	stale := &autoscaler.Server{
+		Name:    "agent-2",
+		State:   autoscaler.StateCreating,
+		Created: time.Now().Add(-reconcileGrace * 2).Unix(),
+	}
+
+	lister := mocks.NewMockLister(controller)
+	lister.EXPECT().List(gomock.Any()).Return(instances, nil)
+
+	var created *autoscaler.Server
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().List(gomock.Any()).Return([]*autoscaler.Server{stale}, nil)
+	store.EXPECT().Create(gomock.Any(), gomock.Any()).Do(func(_ context.Context, server *autoscaler.Server) {
+		created = server
+	})
+	store.EXPECT().CompareAndUpdate(gomock.Any(), stale, autoscaler.StateCreating).Return(nil)
+
+	metrics := mocks.NewMockCollector(controller)
+	metrics.EXPECT().IncrServerOrphan(reconcileAdopt).Times(2)
+
+	r := reconciler{
+		servers:  store,
+		lister:   lister,
+		provider: mocks.NewMockProvider(controller),
+		metrics:  metrics,
+		pool:     "amd64",
+		action:   reconcileAdopt,
+	}
+	err := r.Reconcile(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+
+	if created == nil {
+		t.Errorf("Want orphaned server created")
+		return
+	}
+	if got, want := created.ID, "i-1"; got != want {
+		t.Errorf("Want server id %s, got %s", want, got)
+	}
+	if got, want := created.Pool, "amd64"; got != want {
+		t.Errorf("Want server pool %s, got %s", want, got)
+	}
+	if got, want := created.State, autoscaler.StateError; got != want {
+		t.Errorf("Want server state %s, got %s", want, got)
+	}
+	if got, want := stale.ID, "i-2"; got != want {
+		t.Errorf("Want server id %s, got %s", want, got)
+	}
+	if got, want := stale.Address, "1.2.3.5"; got != want {
+		t.Errorf("Want server address %s, got %s", want, got)
+	}
+	if got, want := stale.State, autoscaler.StateError; got != want {
+		t.Errorf("Want server state %s, got %s", want, got)
+	}
+}
+
+func TestReconcile_Error(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	lister := mocks.NewMockLister(controller)
+	lister.EXPECT().List(gomock.Any()).Return(nil, errors.New("mock error"))
+
+	r := reconciler{
+		servers:  mocks.NewMockServerStore(controller),
+		lister:   lister,
+		provider: mocks.NewMockProvider(controller),
+		metrics:  mocks.NewMockCollector(controller),
+		action:   reconcileDestroy,
+	}
+	err := r.Reconcile(context.TODO())
+	if err == nil {
+		t.Errorf("Want error listing provider servers")
+	}
+}
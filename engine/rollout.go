@@ -0,0 +1,237 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package engine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/config"
+	"github.com/drone/autoscaler/logger"
+
+	"github.com/dchest/uniuri"
+)
+
+// a rollout replaces servers that are running an outdated
+// agent configuration. Idle outdated servers are shutdown
+// one by one, and a replacement server is provisioned ahead
+// of the shutdown when the surge limit allows.
+type rollout struct {
+	mu sync.Mutex
+
+	servers     autoscaler.ServerStore
+	planner     *planner
+	hash        string
+	surge       int // max replacement servers in-flight
+	unavailable int // max outdated servers shutting down
+	interval    time.Duration
+	enabled     bool
+
+	status autoscaler.RolloutStatus
+}
+
+func (r *rollout) Rollout(ctx context.Context) error {
+	logger := logger.FromContext(ctx)
+
+	servers, err := r.servers.List(ctx)
+	if err != nil {
+		logger.WithError(err).
+			Errorln("cannot fetch server list")
+		return err
+	}
+
+	var outdated []*autoscaler.Server
+	var updated, running, inflight, retiring int
+	for _, server := range servers {
+		// cordoned servers are already being replaced.
+		if server.Cordoned {
+			continue
+		}
+		switch server.State {
+		case autoscaler.StateRunning:
+			running++
+			if isOutdated(server, r.hash) {
+				outdated = append(outdated, server)
+			} else {
+				updated++
+			}
+		case autoscaler.StatePending,
+			autoscaler.StateCreating,
+			autoscaler.StateCreated,
+			autoscaler.StateStaging:
+			inflight++
+		case autoscaler.StateShutdown,
+			autoscaler.StateDraining,
+			autoscaler.StateStopping:
+			retiring++
+		}
+	}
+
+	r.mu.Lock()
+	r.status = autoscaler.RolloutStatus{
+		Hash:     r.hash,
+		Updated:  updated,
+		Outdated: len(outdated),
+	}
+	r.mu.Unlock()
+
+	if len(outdated) == 0 {
+		return nil
+	}
+
+	// the rollout is deferred while server allocation is
+	// deferred, since replacement servers are likely to fail.
+	if reason, ok := r.planner.backoff.Deferred(time.Now()); ok {
+		logger.WithField("reason", reason).
+			Debugln("defer rollout after server errors")
+		return nil
+	}
+
+	logger.WithField("servers-updated", updated).
+		WithField("servers-outdated", len(outdated)).
+		WithField("servers-inflight", inflight).
+		WithField("servers-retiring", retiring).
+		Debugln("rollout agent configuration")
+
+	busy, err := r.planner.listBusy(ctx)
+	if err != nil {
+		logger.WithError(err).
+			Errorln("cannot ascertain busy server list")
+		return err
+	}
+
+	min := r.planner.limits(time.Now()).min
+
+	// the oldest servers are replaced first.
+	sort.Sort(byCreated(outdated))
+
+	for _, server := range outdated {
+		if retiring >= r.unavailable {
+			logger.Debugln("rollout unavailable limit reached")
+			break
+		}
+		if server.Protected {
+			continue
+		}
+		if _, ok := busy[server.Name]; ok {
+			continue
+		}
+
+		// if the server cannot be shutdown without falling
+		// below the minimum pool size, the replacement server
+		// must be running before the server is shutdown.
+		if running-1 < min {
+			if inflight < r.surge {
+				r.replace(ctx)
+			}
+			break
+		}
+
+		if inflight < r.surge {
+			if err := r.replace(ctx); err == nil {
+				inflight++
+			}
+		}
+
+		logger.WithField("server", server.Name).
+			WithField("hash", server.Hash).
+			Debugln("shutdown outdated server")
+
+		err := transition(ctx, r.servers, server, autoscaler.StateShutdown)
+		if err == autoscaler.ErrStateConflict {
+			continue
+		}
+		if err != nil {
+			logger.WithError(err).
+				WithField("server", server.Name).
+				WithField("state", "shutdown").
+				Errorln("cannot update server state")
+			return err
+		}
+		retiring++
+		running--
+	}
+	return nil
+}
+
+// Status returns the rollout progress.
+func (r *rollout) Status() *autoscaler.RolloutStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	status := r.status
+	return &status
+}
+
+// helper function creates a replacement server.
+func (r *rollout) replace(ctx context.Context) error {
+	server := &autoscaler.Server{
+		Name:     r.planner.namePrefix + uniuri.NewLen(8),
+		State:    autoscaler.StatePending,
+		Secret:   uniuri.New(),
+		Capacity: r.planner.cap,
+	}
+	err := r.servers.Create(ctx, server)
+	if err != nil {
+		logger.FromContext(ctx).
+			WithError(err).
+			Errorln("cannot create replacement server")
+		return err
+	}
+	logger.FromContext(ctx).
+		WithField("server", server.Name).
+		Debugln("create replacement server")
+	return nil
+}
+
+// helper function returns true if the server is running an
+// outdated agent configuration. Servers without a hash were
+// installed before the agent configuration was hashed, and
+// are considered current, to prevent replacing every server
+// after upgrading.
+func isOutdated(server *autoscaler.Server, hash string) bool {
+	return server.Hash != "" && server.Hash != hash
+}
+
+// helper function returns a hash of the agent configuration.
+// Servers with a different hash are replaced by the rollout.
+// The hash is a truncated sha256 digest, and does not expose
+// the agent token or environment variable values.
+func agentHash(c config.Config) string {
+	sorted := func(v []string) []string {
+		v = append([]string(nil), v...)
+		sort.Strings(v)
+		return v
+	}
+	data, _ := json.Marshal(struct {
+		Image       string
+		Token       string
+		Host        string
+		Proto       string
+		Concurrency int
+		Environ     []string
+		Volumes     []string
+		Ports       []string
+		Labels      map[string]string
+		Runner      config.Runner
+	}{
+		Image:       c.Agent.Image,
+		Token:       c.Agent.Token,
+		Host:        c.Server.Host,
+		Proto:       c.Server.Proto,
+		Concurrency: c.Agent.Concurrency,
+		Environ:     sorted(c.Agent.Environ),
+		Volumes:     sorted(c.Agent.Volumes),
+		Ports:       sorted(c.Agent.Ports),
+		Labels:      c.Agent.Labels,
+		Runner:      c.Runner,
+	})
+	return fmt.Sprintf("%x", sha256.Sum256(data))[:12]
+}
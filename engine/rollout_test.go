@@ -0,0 +1,218 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/config"
+	"github.com/drone/autoscaler/mocks"
+	"github.com/drone/drone-go/drone"
+
+	"github.com/golang/mock/gomock"
+)
+
+// This test verifies that idle outdated servers are shutdown
+// and replaced, and that busy servers are not shutdown.
+func TestRollout(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	servers := []*autoscaler.Server{
+		{Name: "server1", Created: 1, State: autoscaler.StateRunning, Hash: "a1b2c3"},
+		{Name: "server2", Created: 2, State: autoscaler.StateRunning, Hash: "a1b2c3"},
+		{Name: "server3", Created: 3, State: autoscaler.StateRunning, Hash: "d4e5f6"},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().List(gomock.Any()).Return(servers, nil)
+	store.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	store.EXPECT().Transition(gomock.Any(), servers[1].Name, autoscaler.StateRunning, autoscaler.StateShutdown).Return(nil)
+
+	client := mocks.NewMockClient(controller)
+	client.EXPECT().Queue().Return([]*drone.Stage{
+		{Machine: "server1", Status: drone.StatusRunning},
+	}, nil)
+
+	r := rollout{
+		servers:     store,
+		planner:     &planner{client: client, min: 1, cap: 2},
+		hash:        "d4e5f6",
+		surge:       1,
+		unavailable: 1,
+	}
+	err := r.Rollout(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := servers[0].State, autoscaler.StateRunning; got != want {
+		t.Errorf("Want busy server state %s, got %s", want, got)
+	}
+	if got, want := servers[1].State, autoscaler.StateShutdown; got != want {
+		t.Errorf("Want outdated server state %s, got %s", want, got)
+	}
+
+	status := r.Status()
+	if got, want := status.Updated, 1; got != want {
+		t.Errorf("Want %d updated servers, got %d", want, got)
+	}
+	if got, want := status.Outdated, 2; got != want {
+		t.Errorf("Want %d outdated servers, got %d", want, got)
+	}
+}
+
+// This test verifies that the replacement server is created
+// before the outdated server is shutdown, when the shutdown
+// would otherwise fall below the minimum pool size.
+func TestRollout_MinPool(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	servers := []*autoscaler.Server{
+		{Name: "server1", Created: 1, State: autoscaler.StateRunning, Hash: "a1b2c3"},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().List(gomock.Any()).Return(servers, nil)
+	store.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+	client := mocks.NewMockClient(controller)
+	client.EXPECT().Queue().Return([]*drone.Stage{}, nil)
+
+	r := rollout{
+		servers:     store,
+		planner:     &planner{client: client, min: 1, cap: 2},
+		hash:        "d4e5f6",
+		surge:       1,
+		unavailable: 1,
+	}
+	err := r.Rollout(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := servers[0].State, autoscaler.StateRunning; got != want {
+		t.Errorf("Want outdated server state %s, got %s", want, got)
+	}
+}
+
+// This test verifies that no servers are shutdown when the
+// number of servers shutting down reaches the limit.
+func TestRollout_Unavailable(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	servers := []*autoscaler.Server{
+		{Name: "server1", Created: 1, State: autoscaler.StateDraining, Hash: "a1b2c3"},
+		{Name: "server2", Created: 2, State: autoscaler.StateRunning, Hash: "a1b2c3"},
+		{Name: "server3", Created: 3, State: autoscaler.StateRunning, Hash: "d4e5f6"},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().List(gomock.Any()).Return(servers, nil)
+
+	client := mocks.NewMockClient(controller)
+	client.EXPECT().Queue().Return([]*drone.Stage{}, nil)
+
+	r := rollout{
+		servers:     store,
+		planner:     &planner{client: client},
+		hash:        "d4e5f6",
+		surge:       1,
+		unavailable: 1,
+	}
+	err := r.Rollout(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// This test verifies that the rollout is a no-op when all
+// servers are up-to-date.
+func TestRollout_Complete(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	servers := []*autoscaler.Server{
+		{Name: "server1", State: autoscaler.StateRunning, Hash: "d4e5f6"},
+		{Name: "server2", State: autoscaler.StateStopped, Hash: "a1b2c3"},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().List(gomock.Any()).Return(servers, nil)
+
+	r := rollout{
+		servers: store,
+		planner: &planner{client: mocks.NewMockClient(controller)},
+		hash:    "d4e5f6",
+	}
+	err := r.Rollout(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := r.Status().Outdated, 0; got != want {
+		t.Errorf("Want %d outdated servers, got %d", want, got)
+	}
+}
+
+// This test verifies that servers installed before the agent
+// configuration was hashed are not replaced.
+func TestRollout_NoHash(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	servers := []*autoscaler.Server{
+		{Name: "server1", State: autoscaler.StateRunning, Hash: "d4e5f6"},
+		{Name: "server2", State: autoscaler.StateRunning},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().List(gomock.Any()).Return(servers, nil)
+
+	r := rollout{
+		servers: store,
+		planner: &planner{client: mocks.NewMockClient(controller)},
+		hash:    "d4e5f6",
+	}
+	err := r.Rollout(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := r.Status().Updated, 2; got != want {
+		t.Errorf("Want %d updated servers, got %d", want, got)
+	}
+	if got, want := r.Status().Outdated, 0; got != want {
+		t.Errorf("Want %d outdated servers, got %d", want, got)
+	}
+}
+
+func TestAgentHash(t *testing.T) {
+	a := config.Config{}
+	a.Agent.Image = "drone/drone-runner-docker:1"
+	a.Agent.Environ = []string{"A=1", "B=2"}
+
+	b := a
+	b.Agent.Environ = []string{"B=2", "A=1"}
+	if agentHash(a) != agentHash(b) {
+		t.Errorf("Want hash independent of environment order")
+	}
+
+	b.Agent.Environ = []string{"A=3", "B=2"}
+	if agentHash(a) == agentHash(b) {
+		t.Errorf("Want hash changed when an environment value changes")
+	}
+
+	b = a
+	b.Agent.Token = "secret"
+	if agentHash(a) == agentHash(b) {
+		t.Errorf("Want hash changed when the token changes")
+	}
+
+	b.Agent.Image = "drone/drone-runner-docker:2"
+	if agentHash(a) == agentHash(b) {
+		t.Errorf("Want hash changed when the image changes")
+	}
+}
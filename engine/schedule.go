@@ -0,0 +1,89 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package engine
+
+import (
+	"time"
+
+	"github.com/drone/autoscaler/config"
+	"github.com/drone/autoscaler/logger"
+)
+
+// schedule overrides the pool size and capacity buffer
+// while the schedule is active.
+type schedule struct {
+	name   string
+	expr   *config.Cron
+	loc    *time.Location
+	min    *int
+	max    *int
+	buffer *int
+}
+
+// limits defines the pool size and capacity buffer, and the
+// name of the schedule that provides the limits, if any.
+type limits struct {
+	min      int
+	max      int
+	buffer   int
+	schedule string
+}
+
+// helper function returns the schedules for the schedule
+// configuration. Schedules are validated when the
+// configuration is loaded, and invalid schedules are logged
+// and ignored.
+func newSchedules(entries []config.Schedule) []*schedule {
+	var schedules []*schedule
+	for _, entry := range entries {
+		log := logger.Default.
+			WithField("schedule", entry.Name).
+			WithField("cron", entry.Cron)
+
+		expr, err := config.ParseCron(entry.Cron)
+		if err != nil {
+			log.WithError(err).
+				Errorln("invalid schedule expression")
+			continue
+		}
+		loc, err := time.LoadLocation(entry.Timezone)
+		if err != nil {
+			log.WithError(err).
+				WithField("timezone", entry.Timezone).
+				Errorln("invalid schedule timezone")
+			continue
+		}
+		schedules = append(schedules, &schedule{
+			name:   entry.Name,
+			expr:   expr,
+			loc:    loc,
+			min:    entry.Min,
+			max:    entry.Max,
+			buffer: entry.Buffer,
+		})
+	}
+	return schedules
+}
+
+// active returns true if the schedule is active at the
+// given time.
+func (s *schedule) active(t time.Time) bool {
+	return s.expr.Match(t.In(s.loc))
+}
+
+// apply returns the limits overridden by the schedule.
+func (s *schedule) apply(l limits) limits {
+	if s.min != nil {
+		l.min = *s.min
+	}
+	if s.max != nil {
+		l.max = *s.max
+	}
+	if s.buffer != nil {
+		l.buffer = *s.buffer
+	}
+	l.schedule = s.name
+	return l
+}
@@ -0,0 +1,75 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/drone/autoscaler/config"
+)
+
+func TestNewSchedules(t *testing.T) {
+	entries := []config.Schedule{
+		{Name: "valid", Cron: "* 8-17 * * 1-5", Timezone: "Europe/Berlin"},
+		{Name: "utc", Cron: "* * * * *"},
+		{Name: "invalid-cron", Cron: "* * *"},
+		{Name: "invalid-timezone", Cron: "* * * * *", Timezone: "Mars/Olympus"},
+	}
+	schedules := newSchedules(entries)
+	if got, want := len(schedules), 2; got != want {
+		t.Errorf("Want %d valid schedules, got %d", want, got)
+	}
+}
+
+func TestPlannerLimits(t *testing.T) {
+	four, ten, zero := 4, 10, 0
+	schedules := newSchedules([]config.Schedule{
+		{
+			Name:     "office-hours",
+			Cron:     "* 8-17 * * 1-5",
+			Timezone: "Europe/Berlin",
+			Min:      &four,
+			Max:      &ten,
+		},
+		{
+			Name:   "always",
+			Cron:   "* * * * *",
+			Buffer: &zero,
+		},
+	})
+
+	p := &planner{
+		min:       1,
+		max:       5,
+		buffer:    2,
+		schedules: schedules,
+	}
+
+	// 2020-06-01 07:00 UTC is 09:00 on a Monday in Berlin,
+	// and the first matching schedule is applied.
+	l := p.limits(time.Date(2020, 6, 1, 7, 0, 0, 0, time.UTC))
+	if got, want := l.schedule, "office-hours"; got != want {
+		t.Errorf("Want active schedule %q, got %q", want, got)
+	}
+	if l.min != 4 || l.max != 10 || l.buffer != 2 {
+		t.Errorf("Want limits overridden by schedule, got %+v", l)
+	}
+
+	// 2020-06-01 17:00 UTC is 19:00 in Berlin.
+	l = p.limits(time.Date(2020, 6, 1, 17, 0, 0, 0, time.UTC))
+	if got, want := l.schedule, "always"; got != want {
+		t.Errorf("Want active schedule %q, got %q", want, got)
+	}
+	if l.min != 1 || l.max != 5 || l.buffer != 0 {
+		t.Errorf("Want buffer overridden by schedule, got %+v", l)
+	}
+
+	p.schedules = nil
+	l = p.limits(time.Now())
+	if l.schedule != "" || l.min != 1 || l.max != 5 || l.buffer != 2 {
+		t.Errorf("Want configured limits, got %+v", l)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package engine
+
+import (
+	"context"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/logger"
+)
+
+// helper function transitions the server from its current
+// state to the target state, and updates the server state
+// on success. If the server state was changed concurrently
+// the conflict is logged and ErrStateConflict is returned.
+func transition(ctx context.Context, servers autoscaler.ServerStore, server *autoscaler.Server, to autoscaler.ServerState) error {
+	err := servers.Transition(ctx, server.Name, server.State, to)
+	if err == autoscaler.ErrStateConflict {
+		logger.FromContext(ctx).
+			WithField("server", server.Name).
+			WithField("from", string(server.State)).
+			WithField("to", string(to)).
+			Warnln("server state changed concurrently, skipping")
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	server.State = to
+	return nil
+}
+
+// helper function updates the server record, only if the
+// server is in the expected state. If the server state was
+// changed concurrently the conflict is logged and
+// ErrStateConflict is returned.
+func update(ctx context.Context, servers autoscaler.ServerStore, server *autoscaler.Server, from autoscaler.ServerState) error {
+	err := servers.CompareAndUpdate(ctx, server, from)
+	if err == autoscaler.ErrStateConflict {
+		logger.FromContext(ctx).
+			WithField("server", server.Name).
+			WithField("from", string(from)).
+			WithField("to", string(server.State)).
+			Warnln("server state changed concurrently, skipping")
+	}
+	return err
+}
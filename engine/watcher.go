@@ -0,0 +1,93 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/logger"
+	"github.com/drone/autoscaler/metrics"
+)
+
+// a watcher detects interruption notices for spot or
+// preemptible servers, and shuts down interrupted servers
+// early so that running builds have whatever grace period
+// is available before the server is reclaimed.
+type watcher struct {
+	servers     autoscaler.ServerStore
+	interrupter autoscaler.Interrupter
+	metrics     metrics.Collector
+	interval    time.Duration
+}
+
+func (w *watcher) Watch(ctx context.Context) error {
+	if w.interrupter == nil {
+		return nil
+	}
+
+	logger := logger.FromContext(ctx)
+
+	servers, err := w.servers.ListState(ctx, autoscaler.StateRunning)
+	if err != nil {
+		return err
+	}
+	if len(servers) == 0 {
+		return nil
+	}
+
+	var instances []*autoscaler.Instance
+	for _, server := range servers {
+		instances = append(instances, &autoscaler.Instance{
+			ID:       server.ID,
+			Provider: server.Provider,
+			Name:     server.Name,
+			Address:  server.Address,
+			Region:   server.Region,
+			Image:    server.Image,
+			Size:     server.Size,
+			Metadata: server.Labels,
+		})
+	}
+
+	interrupted, err := w.interrupter.Interrupted(ctx, instances)
+	if err != nil {
+		logger.WithError(err).
+			Errorln("cannot check server interruptions")
+		return err
+	}
+
+	ids := map[string]struct{}{}
+	for _, instance := range interrupted {
+		ids[instance.ID] = struct{}{}
+	}
+
+	for _, server := range servers {
+		if _, ok := ids[server.ID]; !ok {
+			continue
+		}
+
+		err := transition(ctx, w.servers, server, autoscaler.StateShutdown)
+		if err == autoscaler.ErrStateConflict {
+			continue
+		}
+		if err != nil {
+			logger.WithError(err).
+				WithField("server", server.Name).
+				WithField("state", "shutdown").
+				Errorln("cannot update server state")
+			continue
+		}
+
+		w.metrics.IncrServerInterrupt(server.Size, server.Region)
+
+		logger.WithField("server", server.Name).
+			WithField("size", server.Size).
+			WithField("zone", server.Region).
+			Warnln("server interrupted, shutting down")
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/mocks"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestWatch(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	servers := []*autoscaler.Server{
+		{ID: "i-1", Name: "agent-1", Size: "t3.medium", Region: "us-east-1a", State: autoscaler.StateRunning},
+		{ID: "i-2", Name: "agent-2", Size: "t3.medium", Region: "us-east-1b", State: autoscaler.StateRunning},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().ListState(gomock.Any(), autoscaler.StateRunning).Return(servers, nil)
+	store.EXPECT().Transition(gomock.Any(), "agent-2", autoscaler.StateRunning, autoscaler.StateShutdown).Return(nil)
+
+	interrupter := mocks.NewMockInterrupter(controller)
+	interrupter.EXPECT().Interrupted(gomock.Any(), gomock.Any()).Return([]*autoscaler.Instance{{ID: "i-2"}}, nil)
+
+	metrics := mocks.NewMockCollector(controller)
+	metrics.EXPECT().IncrServerInterrupt("t3.medium", "us-east-1b")
+
+	w := watcher{
+		servers:     store,
+		interrupter: interrupter,
+		metrics:     metrics,
+	}
+	err := w.Watch(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := servers[0].State, autoscaler.StateRunning; got != want {
+		t.Errorf("Want server state %s, got %s", want, got)
+	}
+	if got, want := servers[1].State, autoscaler.StateShutdown; got != want {
+		t.Errorf("Want server state %s, got %s", want, got)
+	}
+}
+
+func TestWatch_Error(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	servers := []*autoscaler.Server{
+		{ID: "i-1", Name: "agent-1", State: autoscaler.StateRunning},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().ListState(gomock.Any(), autoscaler.StateRunning).Return(servers, nil)
+
+	interrupter := mocks.NewMockInterrupter(controller)
+	interrupter.EXPECT().Interrupted(gomock.Any(), gomock.Any()).Return(nil, errors.New("mock error"))
+
+	w := watcher{
+		servers:     store,
+		interrupter: interrupter,
+		metrics:     mocks.NewMockCollector(controller),
+	}
+	err := w.Watch(context.TODO())
+	if err == nil {
+		t.Errorf("Want error checking interruptions")
+	}
+	if got, want := servers[0].State, autoscaler.StateRunning; got != want {
+		t.Errorf("Want server state %s, got %s", want, got)
+	}
+}
+
+// This test verifies that interrupted servers are skipped
+// when the server state was changed concurrently.
+func TestWatch_StateConflict(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	servers := []*autoscaler.Server{
+		{ID: "i-1", Name: "agent-1", Size: "t3.medium", Region: "us-east-1a", State: autoscaler.StateRunning},
+		{ID: "i-2", Name: "agent-2", Size: "t3.medium", Region: "us-east-1b", State: autoscaler.StateRunning},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().ListState(gomock.Any(), autoscaler.StateRunning).Return(servers, nil)
+	store.EXPECT().Transition(gomock.Any(), "agent-1", autoscaler.StateRunning, autoscaler.StateShutdown).Return(autoscaler.ErrStateConflict)
+	store.EXPECT().Transition(gomock.Any(), "agent-2", autoscaler.StateRunning, autoscaler.StateShutdown).Return(nil)
+
+	interrupter := mocks.NewMockInterrupter(controller)
+	interrupter.EXPECT().Interrupted(gomock.Any(), gomock.Any()).Return([]*autoscaler.Instance{{ID: "i-1"}, {ID: "i-2"}}, nil)
+
+	metrics := mocks.NewMockCollector(controller)
+	metrics.EXPECT().IncrServerInterrupt("t3.medium", "us-east-1b")
+
+	w := watcher{
+		servers:     store,
+		interrupter: interrupter,
+		metrics:     metrics,
+	}
+	err := w.Watch(context.TODO())
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := servers[0].State, autoscaler.StateRunning; got != want {
+		t.Errorf("Want server state %s, got %s", want, got)
+	}
+	if got, want := servers[1].State, autoscaler.StateShutdown; got != want {
+		t.Errorf("Want server state %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package autoscaler
+
+import "context"
+
+// An EventStore persists server state transition events.
+// Events are recorded by the ServerStore when the server
+// state changes.
+type EventStore interface {
+	// List returns the events for the named server, ordered
+	// by creation time.
+	List(ctx context.Context, server string) ([]*Event, error)
+
+	// Purge old event records from the store.
+	Purge(context.Context, int64) error
+}
+
+// Event stores a server state transition.
+type Event struct {
+	ID      int64       `db:"event_id"      json:"id"`
+	Server  string      `db:"event_server"  json:"server"`
+	From    ServerState `db:"event_from"    json:"from"`
+	To      ServerState `db:"event_to"      json:"to"`
+	Actor   string      `db:"event_actor"   json:"actor"`
+	Cycle   string      `db:"event_cycle"   json:"cycle,omitempty"`
+	Error   string      `db:"event_error"   json:"error,omitempty"`
+	Created int64       `db:"event_created" json:"created"`
+}
+
+type actorKey struct{}
+
+type cycleKey struct{}
+
+// WithActor returns a new context with the actor, which is
+// the engine component or username that changes the server
+// state.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor from the context.
+func ActorFrom(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// WithCycle returns a new context with the planner cycle
+// identifier.
+func WithCycle(ctx context.Context, cycle string) context.Context {
+	return context.WithValue(ctx, cycleKey{}, cycle)
+}
+
+// CycleFrom returns the planner cycle identifier from the
+// context.
+func CycleFrom(ctx context.Context) string {
+	cycle, _ := ctx.Value(cycleKey{}).(string)
+	return cycle
+}
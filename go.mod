@@ -49,6 +49,7 @@ require (
 	golang.org/x/sync v0.3.0
 	golang.org/x/time v0.1.0
 	google.golang.org/api v0.126.0
+	gopkg.in/yaml.v2 v2.4.0
 )
 
 require (
@@ -99,7 +100,6 @@ require (
 	google.golang.org/grpc v1.58.3 // indirect
 	google.golang.org/protobuf v1.31.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
 	gotest.tools/v3 v3.0.3 // indirect
 	launchpad.net/gocheck v0.0.0-20140225173054-000000000087 // indirect
 	sigs.k8s.io/yaml v1.2.0 // indirect
@@ -0,0 +1,36 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package autoscaler
+
+import (
+	"context"
+	"time"
+)
+
+// A LeaseStore persists the leader lease. The replica that
+// holds the lease is the leader, and is the only replica that
+// runs the engine.
+type LeaseStore interface {
+	// Acquire acquires or renews the lease for the holder,
+	// and returns true if the holder holds the lease.
+	Acquire(ctx context.Context, holder string, ttl time.Duration) (bool, error)
+
+	// Release releases the lease if held by the holder.
+	Release(ctx context.Context, holder string) error
+
+	// Pause sets the paused flag, which is shared by every
+	// replica.
+	Pause(ctx context.Context, paused bool) error
+
+	// Find returns the lease.
+	Find(context.Context) (*Lease, error)
+}
+
+// Lease stores the leader lease details.
+type Lease struct {
+	Holder  string `db:"lease_holder"  json:"holder"`
+	Expires int64  `db:"lease_expires" json:"expires"`
+	Paused  bool   `db:"lease_paused"  json:"paused"`
+}
@@ -40,6 +40,18 @@ type Collector interface {
 	// IncrServerSetupError keeps a count of errors encountered
 	// when installing software on servers.
 	IncrServerSetupError()
+
+	// IncrServerInterrupt keeps a count of spot or preemptible
+	// server interruptions by instance type and zone.
+	IncrServerInterrupt(size, zone string)
+
+	// IncrServerOrphan keeps a count of orphaned servers by
+	// the action taken by the reconciler.
+	IncrServerOrphan(action string)
+
+	// SetForecastDemand registers the forecast number of pending
+	// and running stages for the next provisioning window.
+	SetForecastDemand(pool string, demand float64)
 }
 
 // Prometheus is a Prometheus metrics collector.
@@ -50,6 +62,9 @@ type Prometheus struct {
 	countServerCreateErr  prometheus.Counter
 	countServerInitErr    prometheus.Counter
 	countServerSetupErr   prometheus.Counter
+	countServerInterrupt  *prometheus.CounterVec
+	countServerOrphan     *prometheus.CounterVec
+	forecastDemand        *prometheus.GaugeVec
 }
 
 // New returns a new Prometheus metrics provider.
@@ -82,15 +97,45 @@ func New() *Prometheus {
 		Name: "drone_server_install_errors_total",
 		Help: "Total number of errors installing software on a server.",
 	})
+	p.countServerInterrupt = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "drone_server_interruptions_total",
+		Help: "Total number of spot or preemptible server interruptions.",
+	}, []string{"size", "zone"})
+	p.countServerOrphan = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "drone_server_orphans_total",
+		Help: "Total number of orphaned servers detected by the reconciler.",
+	}, []string{"action"})
+	p.forecastDemand = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "drone_forecast_demand",
+		Help: "Forecast number of pending and running stages for the next provisioning window.",
+	}, []string{"pool"})
 	prometheus.MustRegister(p.trackServerCreateTime)
 	prometheus.MustRegister(p.trackServerInitTime)
 	prometheus.MustRegister(p.trackServerSetupTime)
 	prometheus.MustRegister(p.countServerCreateErr)
 	prometheus.MustRegister(p.countServerInitErr)
 	prometheus.MustRegister(p.countServerSetupErr)
+	prometheus.MustRegister(p.countServerInterrupt)
+	prometheus.MustRegister(p.countServerOrphan)
+	prometheus.MustRegister(p.forecastDemand)
 	return p
 }
 
+// helper function registers the counter and returns the
+// registered counter. If the counter is already registered,
+// for example when the provider for each pool is wrapped,
+// the existing counter is returned and shared.
+func registerCounter(counter prometheus.Counter) prometheus.Counter {
+	err := prometheus.Register(counter)
+	if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+		return are.ExistingCollector.(prometheus.Counter)
+	}
+	if err != nil {
+		panic(err)
+	}
+	return counter
+}
+
 // TrackServerCreateTime registers the elapsed time it takes
 // to provision a server instance.
 func (m *Prometheus) TrackServerCreateTime(start time.Time) {
@@ -135,6 +180,24 @@ func (m *Prometheus) IncrServerSetupError() {
 	m.countServerSetupErr.Inc()
 }
 
+// IncrServerInterrupt keeps a count of spot or preemptible
+// server interruptions by instance type and zone.
+func (m *Prometheus) IncrServerInterrupt(size, zone string) {
+	m.countServerInterrupt.WithLabelValues(size, zone).Inc()
+}
+
+// IncrServerOrphan keeps a count of orphaned servers by
+// the action taken by the reconciler.
+func (m *Prometheus) IncrServerOrphan(action string) {
+	m.countServerOrphan.WithLabelValues(action).Inc()
+}
+
+// SetForecastDemand registers the forecast number of pending
+// and running stages for the next provisioning window.
+func (m *Prometheus) SetForecastDemand(pool string, demand float64) {
+	m.forecastDemand.WithLabelValues(pool).Set(demand)
+}
+
 // NopCollector provides a no-op metrics collector.
 type NopCollector struct{}
 
@@ -163,3 +226,15 @@ func (*NopCollector) IncrServerInitError() {}
 // IncrServerSetupError keeps a count of errors encountered
 // when installing software on servers.
 func (*NopCollector) IncrServerSetupError() {}
+
+// IncrServerInterrupt keeps a count of spot or preemptible
+// server interruptions by instance type and zone.
+func (*NopCollector) IncrServerInterrupt(size, zone string) {}
+
+// IncrServerOrphan keeps a count of orphaned servers by
+// the action taken by the reconciler.
+func (*NopCollector) IncrServerOrphan(action string) {}
+
+// SetForecastDemand registers the forecast number of pending
+// and running stages for the next provisioning window.
+func (*NopCollector) SetForecastDemand(pool string, demand float64) {}
@@ -21,13 +21,20 @@ func ServerCreate(provider autoscaler.Provider) autoscaler.Provider {
 		Name: "drone_servers_created_err",
 		Help: "Total number of server creation errors.",
 	})
-	prometheus.MustRegister(counter)
-	prometheus.MustRegister(errors)
-	return &providerWrapCreate{
+	wrapper := &providerWrapCreate{
 		Provider: provider,
-		created:  counter,
-		errors:   errors,
+		created:  registerCounter(counter),
+		errors:   registerCounter(errors),
 	}
+	// the wrapper only implements batch creation if the
+	// wrapped provider implements batch creation.
+	if batch, ok := autoscaler.AsBatchCreator(provider); ok {
+		return &providerWrapBatchCreate{
+			providerWrapCreate: wrapper,
+			batch:              batch,
+		}
+	}
+	return wrapper
 }
 
 // instruments the Provider to count server create events.
@@ -46,3 +53,27 @@ func (p *providerWrapCreate) Create(ctx context.Context, opts autoscaler.Instanc
 	}
 	return instance, err
 }
+
+// Unwrap returns the wrapped Provider.
+func (p *providerWrapCreate) Unwrap() autoscaler.Provider {
+	return p.Provider
+}
+
+// instruments the Provider to count server batch create events.
+type providerWrapBatchCreate struct {
+	*providerWrapCreate
+	batch autoscaler.BatchCreator
+}
+
+func (p *providerWrapBatchCreate) BatchCreate(ctx context.Context, opts []autoscaler.InstanceCreateOpts) ([]*autoscaler.Instance, error) {
+	instances, err := p.batch.BatchCreate(ctx, opts)
+	var created int
+	for _, instance := range instances {
+		if instance != nil {
+			created++
+		}
+	}
+	p.created.Add(float64(created))
+	p.errors.Add(float64(len(opts) - created))
+	return instances, err
+}
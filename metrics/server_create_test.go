@@ -73,3 +73,117 @@ func TestServerCreate(t *testing.T) {
 		t.Errorf("Expect metric value %f, got %f", want, got)
 	}
 }
+
+func TestServerCreate_MultiplePools(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	snapshot := prometheus.DefaultRegisterer
+	defer func() {
+		prometheus.DefaultRegisterer = snapshot
+	}()
+
+	registry := prometheus.NewRegistry()
+	prometheus.DefaultRegisterer = registry
+
+	opts := autoscaler.InstanceCreateOpts{Name: "server1"}
+	instance := &autoscaler.Instance{}
+
+	provider := mocks.NewMockProvider(controller)
+	provider.EXPECT().Create(gomock.Any(), opts).Times(2).Return(instance, nil)
+
+	// wrapping multiple providers must not panic, and the
+	// providers must share the registered counter.
+	ServerCreate(provider).Create(noContext, opts)
+	ServerCreate(provider).Create(noContext, opts)
+
+	metrics, err := registry.Gather()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := metrics[0].Metric[0].Counter.GetValue(), float64(2); want != got {
+		t.Errorf("Expect metric value %f, got %f", want, got)
+	}
+}
+
+func TestServerCreate_Unwrap(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	snapshot := prometheus.DefaultRegisterer
+	defer func() {
+		prometheus.DefaultRegisterer = snapshot
+	}()
+	prometheus.DefaultRegisterer = prometheus.NewRegistry()
+
+	provider := &struct {
+		*mocks.MockProvider
+		*mocks.MockHibernator
+	}{
+		mocks.NewMockProvider(controller),
+		mocks.NewMockHibernator(controller),
+	}
+
+	// the hibernator must be discoverable through the
+	// metrics wrappers.
+	wrapped := ServerDelete(ServerCreate(provider))
+	if _, ok := autoscaler.AsHibernator(wrapped); !ok {
+		t.Errorf("Expect hibernator found in wrapped provider")
+	}
+	if _, ok := autoscaler.AsHibernator(mocks.NewMockProvider(controller)); ok {
+		t.Errorf("Expect hibernator not found in provider")
+	}
+}
+
+func TestServerCreate_Batch(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	snapshot := prometheus.DefaultRegisterer
+	defer func() {
+		prometheus.DefaultRegisterer = snapshot
+	}()
+
+	registry := prometheus.NewRegistry()
+	prometheus.DefaultRegisterer = registry
+
+	opts := []autoscaler.InstanceCreateOpts{{Name: "agent-1"}, {Name: "agent-2"}}
+	instances := []*autoscaler.Instance{{}, nil}
+
+	batch := mocks.NewMockBatchCreator(controller)
+	batch.EXPECT().BatchCreate(noContext, opts).Return(instances, errors.New("mock error"))
+
+	provider := &struct {
+		*mocks.MockProvider
+		*mocks.MockBatchCreator
+	}{
+		mocks.NewMockProvider(controller),
+		batch,
+	}
+
+	wrapped, ok := ServerCreate(provider).(autoscaler.BatchCreator)
+	if !ok {
+		t.Errorf("Expect wrapped provider implements batch create")
+		return
+	}
+	wrapped.BatchCreate(noContext, opts)
+
+	// the wrapped provider does not implement batch create
+	// if the provider does not implement batch create.
+	if _, ok := ServerCreate(mocks.NewMockProvider(controller)).(autoscaler.BatchCreator); ok {
+		t.Errorf("Expect wrapped provider does not implement batch create")
+	}
+
+	metrics, err := registry.Gather()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := metrics[0].Metric[0].Counter.GetValue(), float64(1); want != got {
+		t.Errorf("Expect metric value %f, got %f", want, got)
+	}
+	if got, want := metrics[1].Metric[0].Counter.GetValue(), float64(1); want != got {
+		t.Errorf("Expect error metric value %f, got %f", want, got)
+	}
+}
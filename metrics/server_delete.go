@@ -21,12 +21,10 @@ func ServerDelete(provider autoscaler.Provider) autoscaler.Provider {
 		Name: "drone_servers_deleted_err",
 		Help: "Total number of server deletion errors.",
 	})
-	prometheus.MustRegister(created)
-	prometheus.MustRegister(errors)
 	return &providerWrapDestroy{
 		Provider: provider,
-		created:  created,
-		errors:   errors,
+		created:  registerCounter(created),
+		errors:   registerCounter(errors),
 	}
 }
 
@@ -46,3 +44,8 @@ func (p *providerWrapDestroy) Destroy(ctx context.Context, instance *autoscaler.
 	}
 	return err
 }
+
+// Unwrap returns the wrapped Provider.
+func (p *providerWrapDestroy) Unwrap() autoscaler.Provider {
+	return p.Provider
+}
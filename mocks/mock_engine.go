@@ -8,6 +8,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	autoscaler "github.com/drone/autoscaler"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -60,6 +61,20 @@ func (mr *MockEngineMockRecorder) Paused() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Paused", reflect.TypeOf((*MockEngine)(nil).Paused))
 }
 
+// Pools mocks base method.
+func (m *MockEngine) Pools() []*autoscaler.PoolStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pools")
+	ret0, _ := ret[0].([]*autoscaler.PoolStatus)
+	return ret0
+}
+
+// Pools indicates an expected call of Pools.
+func (mr *MockEngineMockRecorder) Pools() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pools", reflect.TypeOf((*MockEngine)(nil).Pools))
+}
+
 // Resume mocks base method.
 func (m *MockEngine) Resume() {
 	m.ctrl.T.Helper()
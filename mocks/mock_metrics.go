@@ -58,6 +58,30 @@ func (mr *MockCollectorMockRecorder) IncrServerInitError() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrServerInitError", reflect.TypeOf((*MockCollector)(nil).IncrServerInitError))
 }
 
+// IncrServerInterrupt mocks base method.
+func (m *MockCollector) IncrServerInterrupt(arg0 string, arg1 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "IncrServerInterrupt", arg0, arg1)
+}
+
+// IncrServerInterrupt indicates an expected call of IncrServerInterrupt.
+func (mr *MockCollectorMockRecorder) IncrServerInterrupt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrServerInterrupt", reflect.TypeOf((*MockCollector)(nil).IncrServerInterrupt), arg0, arg1)
+}
+
+// IncrServerOrphan mocks base method.
+func (m *MockCollector) IncrServerOrphan(arg0 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "IncrServerOrphan", arg0)
+}
+
+// IncrServerOrphan indicates an expected call of IncrServerOrphan.
+func (mr *MockCollectorMockRecorder) IncrServerOrphan(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrServerOrphan", reflect.TypeOf((*MockCollector)(nil).IncrServerOrphan), arg0)
+}
+
 // IncrServerSetupError mocks base method.
 func (m *MockCollector) IncrServerSetupError() {
 	m.ctrl.T.Helper()
@@ -70,6 +94,18 @@ func (mr *MockCollectorMockRecorder) IncrServerSetupError() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrServerSetupError", reflect.TypeOf((*MockCollector)(nil).IncrServerSetupError))
 }
 
+// SetForecastDemand mocks base method.
+func (m *MockCollector) SetForecastDemand(arg0 string, arg1 float64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetForecastDemand", arg0, arg1)
+}
+
+// SetForecastDemand indicates an expected call of SetForecastDemand.
+func (mr *MockCollectorMockRecorder) SetForecastDemand(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetForecastDemand", reflect.TypeOf((*MockCollector)(nil).SetForecastDemand), arg0, arg1)
+}
+
 // TrackServerCreateTime mocks base method.
 func (m *MockCollector) TrackServerCreateTime(arg0 time.Time) {
 	m.ctrl.T.Helper()
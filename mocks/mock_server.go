@@ -35,6 +35,20 @@ func (m *MockServerStore) EXPECT() *MockServerStoreMockRecorder {
 	return m.recorder
 }
 
+// CompareAndUpdate mocks base method.
+func (m *MockServerStore) CompareAndUpdate(arg0 context.Context, arg1 *autoscaler.Server, arg2 autoscaler.ServerState) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompareAndUpdate", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompareAndUpdate indicates an expected call of CompareAndUpdate.
+func (mr *MockServerStoreMockRecorder) CompareAndUpdate(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompareAndUpdate", reflect.TypeOf((*MockServerStore)(nil).CompareAndUpdate), arg0, arg1, arg2)
+}
+
 // Create mocks base method.
 func (m *MockServerStore) Create(arg0 context.Context, arg1 *autoscaler.Server) error {
 	m.ctrl.T.Helper()
@@ -122,6 +136,20 @@ func (mr *MockServerStoreMockRecorder) Purge(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockServerStore)(nil).Purge), arg0, arg1)
 }
 
+// Transition mocks base method.
+func (m *MockServerStore) Transition(arg0 context.Context, arg1 string, arg2 autoscaler.ServerState, arg3 autoscaler.ServerState) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transition", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transition indicates an expected call of Transition.
+func (mr *MockServerStoreMockRecorder) Transition(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transition", reflect.TypeOf((*MockServerStore)(nil).Transition), arg0, arg1, arg2, arg3)
+}
+
 // Update mocks base method.
 func (m *MockServerStore) Update(arg0 context.Context, arg1 *autoscaler.Server) error {
 	m.ctrl.T.Helper()
@@ -135,3 +163,17 @@ func (mr *MockServerStoreMockRecorder) Update(arg0, arg1 interface{}) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockServerStore)(nil).Update), arg0, arg1)
 }
+
+// UpdateFlags mocks base method.
+func (m *MockServerStore) UpdateFlags(arg0 context.Context, arg1 *autoscaler.Server) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFlags", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateFlags indicates an expected call of UpdateFlags.
+func (mr *MockServerStoreMockRecorder) UpdateFlags(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFlags", reflect.TypeOf((*MockServerStore)(nil).UpdateFlags), arg0, arg1)
+}
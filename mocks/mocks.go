@@ -7,6 +7,13 @@ package mocks
 //go:generate mockgen -package=mocks -destination=mock_engine.go   github.com/drone/autoscaler Engine
 //go:generate mockgen -package=mocks -destination=mock_server.go   github.com/drone/autoscaler ServerStore
 //go:generate mockgen -package=mocks -destination=mock_provider.go github.com/drone/autoscaler Provider
+//go:generate mockgen -package=mocks -destination=mock_hibernator.go github.com/drone/autoscaler Hibernator
+//go:generate mockgen -package=mocks -destination=mock_batch.go    github.com/drone/autoscaler BatchCreator
+//go:generate mockgen -package=mocks -destination=mock_interrupter.go github.com/drone/autoscaler Interrupter
+//go:generate mockgen -package=mocks -destination=mock_lister.go    github.com/drone/autoscaler Lister
+//go:generate mockgen -package=mocks -destination=mock_event.go    github.com/drone/autoscaler EventStore
+//go:generate mockgen -package=mocks -destination=mock_lease.go    github.com/drone/autoscaler LeaseStore
+//go:generate mockgen -package=mocks -destination=mock_sample.go   github.com/drone/autoscaler SampleStore
 //go:generate mockgen -package=mocks -destination=mock_metrics.go  github.com/drone/autoscaler/metrics Collector
 //go:generate mockgen -package=mocks -destination=mock_drone.go    github.com/drone/drone-go/drone Client
 //go:generate mockgen -package=mocks -destination=mock_docker.go   github.com/docker/docker/client APIClient
@@ -45,6 +45,123 @@ type Provider interface {
 	Destroy(context.Context, *Instance) error
 }
 
+// A Hibernator is an optional interface implemented by a
+// Provider that is capable of stopping and starting servers.
+// Stopped servers can be started instead of created, which
+// is significantly faster.
+type Hibernator interface {
+	// Start starts a stopped server and returns the server
+	// with the updated network address.
+	Start(context.Context, *Instance) (*Instance, error)
+	// Stop stops a running server.
+	Stop(context.Context, *Instance) error
+}
+
+// A BatchCreator is an optional interface implemented by a
+// Provider that is capable of creating multiple servers with
+// a single request. Each server in a batch has its own
+// certificates, and a Provider that cannot send different
+// userdata to each server creates the servers individually.
+type BatchCreator interface {
+	// BatchCreate creates a server for each of the create
+	// options. The returned slice has the same length and
+	// order as the create options, and a nil entry indicates
+	// the server was not created. If the servers are created
+	// individually, the error is a BatchError.
+	BatchCreate(context.Context, []InstanceCreateOpts) ([]*Instance, error)
+}
+
+// BatchError is returned by a BatchCreator that creates the
+// servers individually, and one or more servers could not be
+// created. The errors have the same length and order as the
+// create options, and a nil entry indicates the server was
+// created without error.
+type BatchError []error
+
+func (e BatchError) Error() string {
+	for _, err := range e {
+		if err != nil {
+			return err.Error()
+		}
+	}
+	return ""
+}
+
+// An Interrupter is an optional interface implemented by a
+// Provider that is capable of detecting interruption notices
+// for spot or preemptible servers.
+type Interrupter interface {
+	// Interrupted returns the instances that received an
+	// interruption notice and will be reclaimed.
+	Interrupted(context.Context, []*Instance) ([]*Instance, error)
+}
+
+// A Lister is an optional interface implemented by a Provider
+// that is capable of listing the servers that belong to the
+// autoscaler, used to detect servers that are running but are
+// not registered in the store.
+type Lister interface {
+	// List returns the servers that belong to the autoscaler.
+	List(context.Context) ([]*Instance, error)
+}
+
+// Unwrap returns the Provider wrapped by p, if p implements
+// an Unwrap method. Otherwise Unwrap returns nil.
+func Unwrap(p Provider) Provider {
+	u, ok := p.(interface {
+		Unwrap() Provider
+	})
+	if !ok {
+		return nil
+	}
+	return u.Unwrap()
+}
+
+// AsHibernator finds the first Provider in the chain of
+// wrapped providers that implements the Hibernator interface.
+func AsHibernator(p Provider) (Hibernator, bool) {
+	for ; p != nil; p = Unwrap(p) {
+		if h, ok := p.(Hibernator); ok {
+			return h, true
+		}
+	}
+	return nil, false
+}
+
+// AsBatchCreator finds the first Provider in the chain of
+// wrapped providers that implements the BatchCreator
+// interface.
+func AsBatchCreator(p Provider) (BatchCreator, bool) {
+	for ; p != nil; p = Unwrap(p) {
+		if b, ok := p.(BatchCreator); ok {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+// AsInterrupter finds the first Provider in the chain of
+// wrapped providers that implements the Interrupter interface.
+func AsInterrupter(p Provider) (Interrupter, bool) {
+	for ; p != nil; p = Unwrap(p) {
+		if i, ok := p.(Interrupter); ok {
+			return i, true
+		}
+	}
+	return nil, false
+}
+
+// AsLister finds the first Provider in the chain of wrapped
+// providers that implements the Lister interface.
+func AsLister(p Provider) (Lister, bool) {
+	for ; p != nil; p = Unwrap(p) {
+		if l, ok := p.(Lister); ok {
+			return l, true
+		}
+	}
+	return nil, false
+}
+
 // An Instance represents a server instance
 // (e.g Digital Ocean Droplet).
 type Instance struct {
@@ -57,6 +174,10 @@ type Instance struct {
 	Size                string
 	ServiceAccountEmail string
 	Scopes              []string
+
+	// Metadata stores driver-specific data, which is
+	// copied to the server labels.
+	Metadata map[string]string
 }
 
 // InstanceCreateOpts define soptional instructions for
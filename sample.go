@@ -0,0 +1,29 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package autoscaler
+
+import "context"
+
+// A SampleStore persists queue samples.
+type SampleStore interface {
+	// List returns the pool samples created within the
+	// time range, ordered by creation time.
+	List(ctx context.Context, pool string, from, to int64) ([]*Sample, error)
+
+	// Create the sample record in the store.
+	Create(context.Context, *Sample) error
+
+	// Purge old sample records from the store.
+	Purge(context.Context, int64) error
+}
+
+// Sample stores the number of pending and running
+// stages for a pool at a point in time.
+type Sample struct {
+	Pool    string `db:"sample_pool"    json:"pool"`
+	Pending int    `db:"sample_pending" json:"pending"`
+	Running int    `db:"sample_running" json:"running"`
+	Created int64  `db:"sample_created" json:"created"`
+}
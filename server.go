@@ -7,7 +7,9 @@ package autoscaler
 import (
 	"context"
 	"database/sql/driver"
+	"encoding/json"
 	"errors"
+	"fmt"
 )
 
 // ServerState specifies the server state.
@@ -26,15 +28,24 @@ const (
 	StateStaging  = ServerState("staging") // starting
 	StateRunning  = ServerState("running")
 	StateShutdown = ServerState("shutdown")
+	StateDraining = ServerState("draining") // not accepting stages
 	StateStopping = ServerState("stopping")
 	StateStopped  = ServerState("stopped")
 	StateError    = ServerState("error")
+
+	// StateHibernated indicates the server is stopped, and
+	// can be started instead of creating a new server.
+	StateHibernated = ServerState("hibernated")
 )
 
 // ErrServerNotFound is returned when the requested server
 // does not exist in the store.
 var ErrServerNotFound = errors.New("Not Found")
 
+// ErrStateConflict is returned when a server state transition
+// fails because the server is not in the expected state.
+var ErrStateConflict = errors.New("State Conflict")
+
 // A ServerStore persists server information.
 type ServerStore interface {
 	// Find a server by unique name.
@@ -52,6 +63,20 @@ type ServerStore interface {
 	// Update the server record in the store.
 	Update(context.Context, *Server) error
 
+	// UpdateFlags updates the server protected and cordoned
+	// flags in the store, without updating other fields.
+	UpdateFlags(context.Context, *Server) error
+
+	// Transition the named server to the target state, only
+	// if the server is in the expected state. Returns
+	// ErrStateConflict if the server state does not match.
+	Transition(ctx context.Context, name string, from, to ServerState) error
+
+	// CompareAndUpdate updates the server record in the store,
+	// only if the server is in the expected state. Returns
+	// ErrStateConflict if the server state does not match.
+	CompareAndUpdate(ctx context.Context, server *Server, from ServerState) error
+
 	// Delete the server record from the store.
 	Delete(context.Context, *Server) error
 
@@ -63,6 +88,7 @@ type ServerStore interface {
 type Server struct {
 	ID       string       `db:"server_id"       json:"id"`
 	Provider ProviderType `db:"server_provider" json:"provider"`
+	Pool     string       `db:"server_pool"     json:"pool"`
 	State    ServerState  `db:"server_state"    json:"state"`
 	Name     string       `db:"server_name"     json:"name"`
 	Image    string       `db:"server_image"    json:"image"`
@@ -71,6 +97,7 @@ type Server struct {
 	Platform string       `db:"server_platform" json:"platform"`
 	Address  string       `db:"server_address"  json:"address"`
 	Capacity int          `db:"server_capacity" json:"capacity"`
+	Hash     string       `db:"server_hash"     json:"hash"`
 	Secret   string       `db:"server_secret"   json:"secret"`
 	Error    string       `db:"server_error"    json:"error"`
 	CAKey    []byte       `db:"server_ca_key"   json:"ca_key"`
@@ -81,4 +108,52 @@ type Server struct {
 	Updated  int64        `db:"server_updated"  json:"updated"`
 	Started  int64        `db:"server_started"  json:"started"`
 	Stopped  int64        `db:"server_stopped"  json:"stopped"`
+
+	// Draining stores the time the server started draining,
+	// which is used to enforce the drain timeout.
+	Draining int64 `db:"server_draining" json:"draining"`
+
+	// Protected servers are never marked for termination
+	// by the planner.
+	Protected bool `db:"server_protected" json:"protected"`
+
+	// Cordoned servers do not count toward capacity, and are
+	// drained and replaced.
+	Cordoned bool `db:"server_cordoned" json:"cordoned"`
+
+	// Labels stores free-form server metadata, such as
+	// driver-specific identifiers.
+	Labels Labels `db:"server_labels" json:"labels,omitempty"`
+}
+
+// Labels stores free-form key value pairs, which are
+// persisted as a json object.
+type Labels map[string]string
+
+// Value converts the value to a sql string.
+func (l Labels) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return "{}", nil
+	}
+	data, err := json.Marshal(l)
+	return string(data), err
+}
+
+// Scan converts the sql value to labels.
+func (l *Labels) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into labels", value)
+	}
+	*l = nil
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, l)
 }
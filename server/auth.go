@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/drone/autoscaler"
 	"github.com/drone/autoscaler/config"
 	"github.com/drone/autoscaler/logger"
 	"github.com/drone/drone-go/drone"
@@ -68,6 +69,10 @@ func CheckDrone(conf config.Config) func(http.Handler) http.Handler {
 			log = log.WithField("username", user.Login)
 			log.Debugln("user authorized")
 
+			// the username is recorded as the actor of
+			// server state transitions.
+			ctx = autoscaler.WithActor(ctx, user.Login)
+
 			next.ServeHTTP(w, r.WithContext(
 				logger.WithContext(ctx, log),
 			))
@@ -10,6 +10,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/drone/autoscaler"
 	"github.com/drone/autoscaler/config"
 	"github.com/drone/drone-go/drone"
 
@@ -38,8 +39,10 @@ func TestAuthorize(t *testing.T) {
 		Reply(200).
 		JSON(user)
 
+	var actor string
 	CheckDrone(c)(
 		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			actor = autoscaler.ActorFrom(r.Context())
 			w.WriteHeader(http.StatusTeapot)
 		}),
 	).ServeHTTP(w, r)
@@ -47,6 +50,9 @@ func TestAuthorize(t *testing.T) {
 	if got, want := w.Code, http.StatusTeapot; got != want {
 		t.Errorf("Want status code %d, got %d", want, got)
 	}
+	if got, want := actor, "octocat"; got != want {
+		t.Errorf("Want actor %s, got %s", want, got)
+	}
 }
 
 func TestAuthorizeMissingToken(t *testing.T) {
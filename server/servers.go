@@ -5,8 +5,10 @@
 package server
 
 import (
+	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/dchest/uniuri"
 	"github.com/drone/autoscaler"
@@ -17,10 +19,24 @@ import (
 )
 
 // HandleServerList returns an http.HandlerFunc that writes
-// the json-encoded server list to the the response body.
+// the json-encoded server list to the the response body. The
+// list is filtered by the label query parameters.
 func HandleServerList(servers autoscaler.ServerStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
+
+		// the server list is optionally filtered by one or
+		// more labels in key=value format.
+		labels := map[string]string{}
+		for _, label := range r.URL.Query()["label"] {
+			parts := strings.SplitN(label, "=", 2)
+			if len(parts) != 2 || parts[0] == "" {
+				writeBadRequest(w, errInvalidLabel)
+				return
+			}
+			labels[parts[0]] = parts[1]
+		}
+
 		list, err := servers.List(ctx)
 		if err != nil {
 			logger.FromContext(ctx).
@@ -29,10 +45,32 @@ func HandleServerList(servers autoscaler.ServerStore) http.HandlerFunc {
 			writeError(w, err)
 			return
 		}
+		if len(labels) != 0 {
+			list = filterLabels(list, labels)
+		}
 		writeJSON(w, list, 200)
 	}
 }
 
+// helper function returns the servers that match all of
+// the labels.
+func filterLabels(servers []*autoscaler.Server, labels map[string]string) []*autoscaler.Server {
+	filtered := []*autoscaler.Server{}
+	for _, server := range servers {
+		match := true
+		for key, value := range labels {
+			if v, ok := server.Labels[key]; !ok || v != value {
+				match = false
+				break
+			}
+		}
+		if match {
+			filtered = append(filtered, server)
+		}
+	}
+	return filtered
+}
+
 // HandleServerFind returns an http.HandlerFunc that finds
 // and writes the json-encoded server to the the response body.
 func HandleServerFind(servers autoscaler.ServerStore) http.HandlerFunc {
@@ -52,6 +90,86 @@ func HandleServerFind(servers autoscaler.ServerStore) http.HandlerFunc {
 	}
 }
 
+// HandleServerEvents returns an http.HandlerFunc that writes
+// a json-encoded list of state transition events for the
+// named server. Events are retained after the server record
+// is purged from the database.
+func HandleServerEvents(events autoscaler.EventStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		name := chi.URLParam(r, "name")
+		list, err := events.List(ctx, name)
+		if err != nil {
+			logger.FromContext(ctx).
+				WithError(err).
+				WithField("server", name).
+				Errorln("cannot list server events")
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, list, 200)
+	}
+}
+
+// serverPatch defines the server fields that can be updated
+// by the operator. Nil fields are not updated.
+type serverPatch struct {
+	Protected *bool `json:"protected"`
+	Cordoned  *bool `json:"cordoned"`
+}
+
+// HandleServerUpdate returns an http.HandlerFunc that updates
+// the protected and cordoned flags of the named server.
+func HandleServerUpdate(servers autoscaler.ServerStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		name := chi.URLParam(r, "name")
+
+		in := new(serverPatch)
+		err := json.NewDecoder(r.Body).Decode(in)
+		if err != nil {
+			writeBadRequest(w, err)
+			return
+		}
+
+		server, err := servers.Find(ctx, name)
+		if err != nil {
+			logger.FromContext(ctx).
+				WithError(err).
+				WithField("server", name).
+				Errorln("cannot get server")
+			writeNotFound(w, err)
+			return
+		}
+
+		if in.Protected != nil {
+			server.Protected = *in.Protected
+		}
+		if in.Cordoned != nil {
+			server.Cordoned = *in.Cordoned
+		}
+
+		logger.FromContext(ctx).
+			WithField("server", server.Name).
+			WithField("protected", server.Protected).
+			WithField("cordoned", server.Cordoned).
+			Infoln("update server")
+
+		// only the flags are updated, to prevent overwriting
+		// changes made by the engine since the server was read.
+		err = servers.UpdateFlags(ctx, server)
+		if err != nil {
+			logger.FromContext(ctx).
+				WithError(err).
+				WithField("server", server.Name).
+				Errorln("cannot update server")
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, server, 200)
+	}
+}
+
 // HandleServerDelete returns an http.HandlerFunc that destroys
 // and then deletes the named server.
 func HandleServerDelete(
@@ -101,8 +219,17 @@ func HandleServerDelete(
 			WithField("force", force).
 			Infoln("schedule server shutdown")
 
-		server.State = autoscaler.StateShutdown
-		err = servers.Update(ctx, server)
+		// the server is only shutdown if the server state
+		// was not changed concurrently by the engine.
+		err = servers.Transition(ctx, server.Name, server.State, autoscaler.StateShutdown)
+		if err == autoscaler.ErrStateConflict {
+			logger.FromContext(ctx).
+				WithField("server", server.Name).
+				WithField("state", string(server.State)).
+				Warnln("cannot shutdown server, state changed")
+			writeConflict(w, err)
+			return
+		}
 		if err != nil {
 			logger.FromContext(ctx).
 				WithError(err).
@@ -112,22 +239,37 @@ func HandleServerDelete(
 			writeError(w, err)
 			return
 		}
+		server.State = autoscaler.StateShutdown
 		writeJSON(w, server, 200)
 	}
 }
 
 // HandleServerCreate returns an http.HandlerFunc that creates
-// and a new server.
+// and a new server. The server is created in the pool named
+// by the pool query parameter, or the first pool if the
+// parameter is empty, using the pool name prefix and agent
+// concurrency.
 func HandleServerCreate(
 	servers autoscaler.ServerStore,
-	config config.Config,
+	pools []config.Pool,
 ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
+		pool, ok := findPool(pools, r.URL.Query().Get("pool"))
+		if !ok {
+			writeNotFound(w, errPoolNotFound)
+			return
+		}
 		server := &autoscaler.Server{
-			Name:     config.Agent.NamePrefix + uniuri.NewLen(8),
+			Name:     pool.Config.Agent.NamePrefix + uniuri.NewLen(8),
 			State:    autoscaler.StatePending,
-			Capacity: config.Agent.Concurrency,
+			Capacity: pool.Config.Agent.Concurrency,
+			Pool:     pool.Name,
+		}
+		// the pool name is added to the server labels, so that
+		// servers can be filtered by pool.
+		if pool.Name != "" {
+			server.Labels = autoscaler.Labels{"pool": pool.Name}
 		}
 		err := servers.Create(ctx, server)
 		if err != nil {
@@ -140,3 +282,14 @@ func HandleServerCreate(
 		writeJSON(w, server, 200)
 	}
 }
+
+// helper function returns the named pool, or the first pool
+// if the name is empty.
+func findPool(pools []config.Pool, name string) (config.Pool, bool) {
+	for _, pool := range pools {
+		if name == "" || pool.Name == name {
+			return pool, true
+		}
+	}
+	return config.Pool{}, false
+}
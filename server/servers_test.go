@@ -5,10 +5,12 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/drone/autoscaler"
@@ -48,6 +50,58 @@ func TestHandleServerList(t *testing.T) {
 	}
 }
 
+func TestHandleServerListLabel(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/api/servers?label=zone=us-central1-a&label=project=drone", nil)
+
+	servers := []*autoscaler.Server{
+		{Name: "server1", Capacity: 1, Labels: autoscaler.Labels{"zone": "us-central1-a", "project": "drone"}},
+		{Name: "server2", Capacity: 1, Labels: autoscaler.Labels{"zone": "us-central1-b", "project": "drone"}},
+		{Name: "server3", Capacity: 1},
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().List(gomock.Any()).Return(servers, nil)
+
+	HandleServerList(store).ServeHTTP(w, r)
+
+	if got, want := w.Code, 200; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := []*autoscaler.Server{}, servers[:1]
+	json.NewDecoder(w.Body).Decode(&got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("response body does match expected result")
+		pretty.Ldiff(t, got, want)
+	}
+}
+
+func TestHandleServerListLabelInvalid(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/api/servers?label=zone", nil)
+
+	store := mocks.NewMockServerStore(controller)
+
+	HandleServerList(store).ServeHTTP(w, r)
+
+	if got, want := w.Code, 400; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	errjson := &Error{}
+	json.NewDecoder(w.Body).Decode(errjson)
+	if got, want := errjson.Message, errInvalidLabel.Error(); got != want {
+		t.Errorf("Want error message %s, got %s", want, got)
+	}
+}
+
 func TestHandleServerListErr(t *testing.T) {
 	controller := gomock.NewController(t)
 	defer controller.Finish()
@@ -135,13 +189,72 @@ func TestHandleServerCreate(t *testing.T) {
 	store := mocks.NewMockServerStore(controller)
 	store.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
 
-	HandleServerCreate(store, config.Config{}).ServeHTTP(w, r)
+	HandleServerCreate(store, []config.Pool{{}}).ServeHTTP(w, r)
 
 	if got, want := w.Code, 200; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
 }
 
+// This test verifies that the server is created in the
+// requested pool, using the pool name prefix and concurrency.
+func TestHandleServerCreate_Pool(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/api/servers?pool=arm64", nil)
+
+	pools := []config.Pool{
+		{Name: "amd64", Config: config.Config{}},
+		{Name: "arm64", Config: config.Config{}},
+	}
+	pools[0].Config.Agent.NamePrefix = "agent-amd64-"
+	pools[0].Config.Agent.Concurrency = 2
+	pools[1].Config.Agent.NamePrefix = "agent-arm64-"
+	pools[1].Config.Agent.Concurrency = 4
+
+	var created *autoscaler.Server
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().Create(gomock.Any(), gomock.Any()).Do(func(_ context.Context, server *autoscaler.Server) {
+		created = server
+	}).Return(nil)
+
+	HandleServerCreate(store, pools).ServeHTTP(w, r)
+
+	if got, want := w.Code, 200; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if got, want := created.Pool, "arm64"; got != want {
+		t.Errorf("Want server pool %s, got %s", want, got)
+	}
+	if got, want := created.Labels["pool"], "arm64"; got != want {
+		t.Errorf("Want server pool label %s, got %s", want, got)
+	}
+	if !strings.HasPrefix(created.Name, "agent-arm64-") {
+		t.Errorf("Want server name prefix agent-arm64-, got %s", created.Name)
+	}
+	if got, want := created.Capacity, 4; got != want {
+		t.Errorf("Want server capacity %d, got %d", want, got)
+	}
+}
+
+func TestHandleServerCreate_PoolNotFound(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/api/servers?pool=gpu", nil)
+
+	store := mocks.NewMockServerStore(controller)
+
+	HandleServerCreate(store, []config.Pool{{Name: "amd64"}}).ServeHTTP(w, r)
+
+	if got, want := w.Code, 404; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
 func TestHandleServerCreateFailure(t *testing.T) {
 	controller := gomock.NewController(t)
 	defer controller.Finish()
@@ -153,7 +266,7 @@ func TestHandleServerCreateFailure(t *testing.T) {
 	store := mocks.NewMockServerStore(controller)
 	store.EXPECT().Create(gomock.Any(), gomock.Any()).Return(err)
 
-	h := HandleServerCreate(store, config.Config{})
+	h := HandleServerCreate(store, []config.Pool{{}})
 	h.ServeHTTP(w, r)
 
 	if got, want := w.Code, 500; want != got {
@@ -167,6 +280,131 @@ func TestHandleServerCreateFailure(t *testing.T) {
 	}
 }
 
+func TestHandleServerUpdate(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("PATCH", "/api/servers/i-5203422c", strings.NewReader(`{"protected":true}`))
+
+	server := &autoscaler.Server{
+		Name:     "i-5203422c",
+		State:    autoscaler.StateRunning,
+		Cordoned: true,
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().Find(gomock.Any(), server.Name).Return(server, nil)
+	store.EXPECT().UpdateFlags(gomock.Any(), server).Return(nil)
+
+	router := chi.NewRouter()
+	router.Patch("/api/servers/{name}", HandleServerUpdate(store))
+	router.ServeHTTP(w, r)
+
+	if got, want := w.Code, 200; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if !server.Protected {
+		t.Errorf("Want server protected")
+	}
+	if !server.Cordoned {
+		t.Errorf("Want server cordon unchanged")
+	}
+}
+
+func TestHandleServerUpdateBadRequest(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("PATCH", "/api/servers/i-5203422c", strings.NewReader(`{"cordoned":"yes"}`))
+
+	router := chi.NewRouter()
+	router.Patch("/api/servers/{name}", HandleServerUpdate(mocks.NewMockServerStore(controller)))
+	router.ServeHTTP(w, r)
+
+	if got, want := w.Code, 400; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestHandleServerUpdateNotFound(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("PATCH", "/api/servers/i-5203422c", strings.NewReader(`{"cordoned":true}`))
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().Find(gomock.Any(), "i-5203422c").Return(nil, errors.New("not found"))
+
+	router := chi.NewRouter()
+	router.Patch("/api/servers/{name}", HandleServerUpdate(store))
+	router.ServeHTTP(w, r)
+
+	if got, want := w.Code, 404; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestHandleServerEvents(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/api/servers/i-5203422c/events", nil)
+
+	mockEvents := []*autoscaler.Event{
+		{ID: 1, Server: "i-5203422c", To: autoscaler.StatePending, Actor: "planner", Cycle: "KoHzbWvpLtmAaJ4C", Created: 1573575719},
+		{ID: 2, Server: "i-5203422c", From: autoscaler.StatePending, To: autoscaler.StateCreating, Actor: "allocator", Created: 1573575729},
+	}
+
+	events := mocks.NewMockEventStore(controller)
+	events.EXPECT().List(gomock.Any(), "i-5203422c").Return(mockEvents, nil)
+
+	router := chi.NewRouter()
+	router.Get("/api/servers/{name}/events", HandleServerEvents(events))
+	router.ServeHTTP(w, r)
+
+	if got, want := w.Code, 200; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := []*autoscaler.Event{}, mockEvents
+	json.NewDecoder(w.Body).Decode(&got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("response body does match expected result")
+		pretty.Ldiff(t, got, want)
+	}
+}
+
+func TestHandleServerEventsFailure(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/api/servers/i-5203422c/events", nil)
+
+	err := errors.New("database connection refused")
+
+	events := mocks.NewMockEventStore(controller)
+	events.EXPECT().List(gomock.Any(), "i-5203422c").Return(nil, err)
+
+	router := chi.NewRouter()
+	router.Get("/api/servers/{name}/events", HandleServerEvents(events))
+	router.ServeHTTP(w, r)
+
+	if got, want := w.Code, 500; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	errjson := &Error{}
+	json.NewDecoder(w.Body).Decode(errjson)
+	if got, want := errjson.Message, err.Error(); got != want {
+		t.Errorf("Want error message %s, got %s", want, got)
+	}
+}
+
 func TestHandleServerDelete(t *testing.T) {
 	controller := gomock.NewController(t)
 	defer controller.Finish()
@@ -183,7 +421,7 @@ func TestHandleServerDelete(t *testing.T) {
 
 	store := mocks.NewMockServerStore(controller)
 	store.EXPECT().Find(gomock.Any(), server.Name).Return(server, nil)
-	store.EXPECT().Update(gomock.Any(), server).Return(nil)
+	store.EXPECT().Transition(gomock.Any(), server.Name, server.State, autoscaler.StateShutdown).Return(nil)
 
 	router := chi.NewRouter()
 	router.Delete("/api/servers/{name}", HandleServerDelete(store))
@@ -242,7 +480,7 @@ func TestHandleServerDeleteFailure(t *testing.T) {
 
 	store := mocks.NewMockServerStore(controller)
 	store.EXPECT().Find(gomock.Any(), server.Name).Return(server, nil)
-	store.EXPECT().Update(gomock.Any(), server).Return(err)
+	store.EXPECT().Transition(gomock.Any(), server.Name, server.State, autoscaler.StateShutdown).Return(err)
 
 	router := chi.NewRouter()
 	router.Delete("/api/servers/{name}", HandleServerDelete(store))
@@ -259,6 +497,36 @@ func TestHandleServerDeleteFailure(t *testing.T) {
 	}
 }
 
+// This test verifies that a conflict is returned if the
+// server state was changed concurrently by the engine.
+func TestHandleServerDeleteConflict(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "/api/servers/i-5203422c", nil)
+
+	server := &autoscaler.Server{
+		Name:  "i-5203422c",
+		State: autoscaler.StateStaging,
+	}
+
+	store := mocks.NewMockServerStore(controller)
+	store.EXPECT().Find(gomock.Any(), server.Name).Return(server, nil)
+	store.EXPECT().Transition(gomock.Any(), server.Name, autoscaler.StateStaging, autoscaler.StateShutdown).Return(autoscaler.ErrStateConflict)
+
+	router := chi.NewRouter()
+	router.Delete("/api/servers/{name}", HandleServerDelete(store))
+	router.ServeHTTP(w, r)
+
+	if got, want := w.Code, 409; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if got, want := server.State, autoscaler.StateStaging; got != want {
+		t.Errorf("Want server state Staging, got %s", got)
+	}
+}
+
 func TestHandleServerDeleteErrorState(t *testing.T) {
 	controller := gomock.NewController(t)
 	defer controller.Finish()
@@ -8,18 +8,33 @@ import (
 	"net/http"
 
 	"github.com/drone/autoscaler"
+	"github.com/drone/autoscaler/logger"
 )
 
 type varz struct {
-	Paused bool `json:"paused"`
+	Paused bool                     `json:"paused"`
+	Pools  []*autoscaler.PoolStatus `json:"pools"`
+	Leader *autoscaler.Lease        `json:"leader,omitempty"`
 }
 
 // HandleVarz creates an http.HandlerFunc that returns system
-// configuration and runtime information.
-func HandleVarz(engine autoscaler.Engine) http.HandlerFunc {
+// configuration and runtime information. The leader lease is
+// included if leader election is enabled.
+func HandleVarz(engine autoscaler.Engine, leases autoscaler.LeaseStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		data := varz{
 			Paused: engine.Paused(),
+			Pools:  engine.Pools(),
+		}
+		if leases != nil {
+			lease, err := leases.Find(r.Context())
+			if err != nil {
+				logger.FromContext(r.Context()).
+					WithError(err).
+					Warnln("cannot get leader lease")
+			} else {
+				data.Leader = lease
+			}
 		}
 		writeJSON(w, &data, 200)
 	}
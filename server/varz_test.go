@@ -10,6 +10,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/drone/autoscaler"
 	"github.com/drone/autoscaler/mocks"
 	"github.com/go-chi/chi"
 	"github.com/golang/mock/gomock"
@@ -20,8 +21,12 @@ func TestHandleVarz(t *testing.T) {
 	controller := gomock.NewController(t)
 	defer controller.Finish()
 
+	mockPools := []*autoscaler.PoolStatus{
+		{Name: "arm64", Min: 4, Max: 10, Buffer: 2, Schedule: "office-hours"},
+	}
 	mockVarz := &varz{
 		Paused: true,
+		Pools:  mockPools,
 	}
 
 	w := httptest.NewRecorder()
@@ -29,9 +34,10 @@ func TestHandleVarz(t *testing.T) {
 
 	engine := mocks.NewMockEngine(controller)
 	engine.EXPECT().Paused().Return(true)
+	engine.EXPECT().Pools().Return(mockPools)
 
 	router := chi.NewRouter()
-	router.Post("/varz", HandleVarz(engine))
+	router.Post("/varz", HandleVarz(engine, nil))
 	router.ServeHTTP(w, r)
 
 	if got, want := w.Code, 200; want != got {
@@ -45,3 +51,33 @@ func TestHandleVarz(t *testing.T) {
 		pretty.Ldiff(t, got, want)
 	}
 }
+
+func TestHandleVarzLeader(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockLease := &autoscaler.Lease{Holder: "replica-1", Expires: 1573575719}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/varz", nil)
+
+	engine := mocks.NewMockEngine(controller)
+	engine.EXPECT().Paused().Return(false)
+	engine.EXPECT().Pools().Return(nil)
+
+	leases := mocks.NewMockLeaseStore(controller)
+	leases.EXPECT().Find(gomock.Any()).Return(mockLease, nil)
+
+	HandleVarz(engine, leases).ServeHTTP(w, r)
+
+	if got, want := w.Code, 200; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got := &varz{}
+	json.NewDecoder(w.Body).Decode(got)
+	if !reflect.DeepEqual(got.Leader, mockLease) {
+		t.Errorf("response body does match expected result")
+		pretty.Ldiff(t, got.Leader, mockLease)
+	}
+}
@@ -14,8 +14,8 @@ import (
 )
 
 // HandleServers returns a http.HandlerFunc that displays a
-// list of activate servers.
-func HandleServers(servers autoscaler.ServerStore) http.HandlerFunc {
+// list of activate servers, and the rollout progress.
+func HandleServers(servers autoscaler.ServerStore, engine autoscaler.Engine) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		nocache(w)
 		items, _ := servers.List(r.Context())
@@ -27,7 +27,8 @@ func HandleServers(servers autoscaler.ServerStore) http.HandlerFunc {
 		}
 		render(w, "index.tmpl", struct {
 			Items []*autoscaler.Server
-		}{filtered})
+			Pools []*autoscaler.PoolStatus
+		}{filtered, engine.Pools()})
 	}
 }
 
@@ -180,7 +180,7 @@ var files = map[string]file{
 		data: file5,
 		FileInfo: &fileInfo{
 			name:    "style.css",
-			size:    11015,
+			size:    11430,
 			modTime: time.Unix(1574720127, 0),
 		},
 	},
@@ -1016,6 +1016,19 @@ header .logo {
     margin-bottom: var(--spacing-3);
 }
 
+/*
+ * rollout card component
+ */
+
+.rollout {
+    color: var(--card-text-color-secondary);
+}
+
+.rollout strong {
+    font-weight: 500;
+    color: var(--card-text-color-title);
+}
+
 /*
  * instance card component
  */
@@ -1090,6 +1103,18 @@ header .logo {
     line-height: var(--height-3);
 }
 
+.instance .labels {
+    grid-column: 2 / span 5;
+    grid-row: 3;
+}
+
+.instance .label {
+    display: inline-block;
+    margin-right: var(--spacing-1);
+    color: var(--card-text-color-secondary);
+    font-weight: 300;
+}
+
 /*
  * instance icon server
  */
@@ -1149,6 +1174,7 @@ header .logo {
     color: var(--badge-running-color);
 }
 
+.badge-draining,
 .badge-stopping,
 .badge-stopped,
 .badge-shutdown {
@@ -63,6 +63,14 @@ var index = `<!DOCTYPE html>
         <header>
             <h1>Servers</h1>
         </header>
+        {{ range $pool := .Pools }}
+        {{ with $pool.Rollout }}{{ if .Outdated }}
+        <div class="card rollout">
+            Rolling out agent configuration <strong>{{ .Hash }}</strong>{{ if $pool.Name }} to pool <strong>{{ $pool.Name }}</strong>{{ end }}:
+            {{ .Updated }} updated, {{ .Outdated }} outdated
+        </div>
+        {{ end }}{{ end }}
+        {{ end }}
         <article class="cards stages">
             {{ if not .Items }}
             <div class="card alert sleeping">
@@ -94,6 +102,11 @@ var index = `<!DOCTYPE html>
                     <div class="image">{{ .Image }}</div>
                     <div class="size">{{ .Size }}</div>
                     <div class="time" datetime="{{ timestamp .Created }}"></div>
+                    {{ if .Labels }}
+                    <div class="labels">
+                        {{ range $key, $value := .Labels }}<span class="label">{{ $key }}={{ $value }}</span>{{ end }}
+                    </div>
+                    {{ end }}
                 </div>
                 {{ end }}
             {{ end }}
@@ -33,6 +33,14 @@ var (
 
 	// errNotFound is returned when a resource is not found.
 	errNotFound = errors.New("Not Found")
+
+	// errInvalidLabel is returned when a label filter is not
+	// in key=value format.
+	errInvalidLabel = errors.New("Invalid label filter, expected key=value")
+
+	// errPoolNotFound is returned when the requested pool
+	// is not configured.
+	errPoolNotFound = errors.New("Pool not found")
 )
 
 // Error represents a json-encoded API error.
@@ -75,6 +83,12 @@ func writeBadRequest(w http.ResponseWriter, err error) {
 	writeErrorCode(w, err, 400)
 }
 
+// writeConflict writes the json-encoded error message to the response
+// with a 409 conflict status code.
+func writeConflict(w http.ResponseWriter, err error) {
+	writeErrorCode(w, err, 409)
+}
+
 // writeJSON writes the json-encoded error message to the response
 // with a 400 bad request status code.
 func writeJSON(w http.ResponseWriter, v interface{}, status int) {
@@ -52,6 +52,22 @@ func (n *notifier) Update(ctx context.Context, server *autoscaler.Server) error
 	return err
 }
 
+func (n *notifier) CompareAndUpdate(ctx context.Context, server *autoscaler.Server, from autoscaler.ServerState) error {
+	err := n.ServerStore.CompareAndUpdate(ctx, server, from)
+	if err != nil {
+		return err
+	}
+	switch {
+	case server.State == autoscaler.StateRunning && n.create:
+		n.notifyCreate(server)
+	case server.State == autoscaler.StateStopped && n.destroy:
+		n.notifyDestroy(server)
+	case server.State == autoscaler.StateError && n.error:
+		n.notifyError(server)
+	}
+	return nil
+}
+
 func (n *notifier) notifyCreate(server *autoscaler.Server) error {
 	opts := &slack.WebHookPostPayload{
 		Text: fmt.Sprintf("Provisioned server instance %s", server.Name),
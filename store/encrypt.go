@@ -0,0 +1,219 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/drone/autoscaler"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// encryptPrefix is prepended to encrypted values, which
+// distinguishes encrypted values from plaintext values that
+// were written before encryption was enabled.
+var encryptPrefix = []byte("aesgcm:")
+
+var (
+	errKeySize        = errors.New("database secret must be 32 bytes")
+	errDecrypt        = errors.New("cannot decrypt value with the database secret")
+	errSecretRequired = errors.New("database secret required to decrypt value")
+)
+
+// Encrypter encrypts the server secret and private keys
+// before they are written to the database, and decrypts them
+// after they are read from the database.
+type Encrypter interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// NewEncrypter returns an Encrypter that encrypts values
+// with AES-GCM using the key. Values encrypted with any of
+// the previous keys can be decrypted, which allows the key
+// to be rotated. If the key is empty, values are not
+// encrypted, but values encrypted with the previous keys
+// can still be decrypted, which allows encryption to be
+// disabled.
+func NewEncrypter(key string, previous ...string) (Encrypter, error) {
+	keys := append([]string{key}, previous...)
+	if key == "" {
+		if len(previous) == 0 {
+			return none{}, nil
+		}
+		keys = previous
+	}
+	enc := new(aesgcm)
+	for _, key := range keys {
+		if len(key) != 32 {
+			return nil, errKeySize
+		}
+		block, err := aes.NewCipher([]byte(key))
+		if err != nil {
+			return nil, err
+		}
+		gcm, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		enc.keys = append(enc.keys, gcm)
+	}
+	if key == "" {
+		return decrypter{enc}, nil
+	}
+	return enc, nil
+}
+
+// EncryptServers encrypts the secret and private keys of
+// every server with the current key, including servers that
+// were written before encryption was enabled, or that were
+// encrypted with a previous key. It returns the number of
+// servers encrypted.
+func EncryptServers(ctx context.Context, db *sqlx.DB, mu sync.Locker, enc Encrypter) (int, error) {
+	store := &serverStore{mu, db, enc}
+	servers, err := store.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	for i, server := range servers {
+		encrypted, err := encryptServer(enc, server)
+		if err != nil {
+			return i, err
+		}
+		stmt, args, err := db.BindNamed(serverEncryptStmt, encrypted)
+		if err != nil {
+			return i, err
+		}
+		_, err = db.ExecContext(noContext, stmt, args...)
+		if err != nil {
+			return i, err
+		}
+	}
+	return len(servers), nil
+}
+
+// none is an Encrypter that does not encrypt values.
+type none struct{}
+
+func (none) Encrypt(plaintext []byte) ([]byte, error) {
+	return plaintext, nil
+}
+
+func (none) Decrypt(ciphertext []byte) ([]byte, error) {
+	if bytes.HasPrefix(ciphertext, encryptPrefix) {
+		return nil, errSecretRequired
+	}
+	return ciphertext, nil
+}
+
+// decrypter is an Encrypter that does not encrypt values,
+// and decrypts values encrypted with the previous keys.
+type decrypter struct {
+	*aesgcm
+}
+
+func (decrypter) Encrypt(plaintext []byte) ([]byte, error) {
+	return plaintext, nil
+}
+
+// aesgcm is an Encrypter that encrypts values with AES-GCM.
+// Values are encrypted with the first key, and decrypted
+// with the first key that authenticates the value.
+type aesgcm struct {
+	keys []cipher.AEAD
+}
+
+func (e *aesgcm) Encrypt(plaintext []byte) ([]byte, error) {
+	if len(plaintext) == 0 {
+		return plaintext, nil
+	}
+	gcm := e.keys[0]
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+
+	// the encrypted value is base64 encoded, since the
+	// columns are text columns in some databases.
+	out := make([]byte, len(encryptPrefix)+base64.StdEncoding.EncodedLen(len(sealed)))
+	copy(out, encryptPrefix)
+	base64.StdEncoding.Encode(out[len(encryptPrefix):], sealed)
+	return out, nil
+}
+
+func (e *aesgcm) Decrypt(ciphertext []byte) ([]byte, error) {
+	// values written before encryption was enabled are
+	// returned as-is.
+	if !bytes.HasPrefix(ciphertext, encryptPrefix) {
+		return ciphertext, nil
+	}
+	data := ciphertext[len(encryptPrefix):]
+	sealed := make([]byte, base64.StdEncoding.DecodedLen(len(data)))
+	n, err := base64.StdEncoding.Decode(sealed, data)
+	if err != nil {
+		return nil, err
+	}
+	sealed = sealed[:n]
+	for _, gcm := range e.keys {
+		size := gcm.NonceSize()
+		if len(sealed) < size {
+			return nil, errDecrypt
+		}
+		plaintext, err := gcm.Open(nil, sealed[:size], sealed[size:], nil)
+		if err == nil {
+			return plaintext, nil
+		}
+	}
+	return nil, errDecrypt
+}
+
+// helper function returns a copy of the server with the
+// secret and private keys encrypted.
+func encryptServer(enc Encrypter, server *autoscaler.Server) (*autoscaler.Server, error) {
+	copy := *server
+	secret, err := enc.Encrypt([]byte(server.Secret))
+	if err != nil {
+		return nil, err
+	}
+	copy.Secret = string(secret)
+	if copy.CAKey, err = enc.Encrypt(server.CAKey); err != nil {
+		return nil, err
+	}
+	if copy.TLSKey, err = enc.Encrypt(server.TLSKey); err != nil {
+		return nil, err
+	}
+	return &copy, nil
+}
+
+// helper function decrypts the server secret and private
+// keys in place.
+func decryptServer(enc Encrypter, server *autoscaler.Server) error {
+	secret, err := enc.Decrypt([]byte(server.Secret))
+	if err != nil {
+		return err
+	}
+	server.Secret = string(secret)
+	if server.CAKey, err = enc.Decrypt(server.CAKey); err != nil {
+		return err
+	}
+	if server.TLSKey, err = enc.Decrypt(server.TLSKey); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/drone/autoscaler"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// NewEventStore returns a new event store.
+func NewEventStore(db *sqlx.DB, mu sync.Locker) autoscaler.EventStore {
+	return &eventStore{mu, db}
+}
+
+type eventStore struct {
+	mu sync.Locker
+	db *sqlx.DB
+}
+
+func (s *eventStore) List(_ context.Context, server string) ([]*autoscaler.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dest := []*autoscaler.Event{}
+	stmt, args, err := s.db.BindNamed(eventListStmt, &autoscaler.Event{Server: server})
+	if err != nil {
+		return nil, err
+	}
+	err = s.db.SelectContext(noContext, &dest, stmt, args...)
+	return dest, err
+}
+
+func (s *eventStore) Purge(_ context.Context, before int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stmt, args, err := s.db.BindNamed(eventPurgeStmt, &autoscaler.Event{Created: before})
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(noContext, stmt, args...)
+	return err
+}
+
+// helper function records the server state transition in
+// the transaction. The actor and planner cycle are sourced
+// from the context.
+func createEvent(ctx context.Context, tx *sqlx.Tx, server *autoscaler.Server, from autoscaler.ServerState) error {
+	event := &autoscaler.Event{
+		Server:  server.Name,
+		From:    from,
+		To:      server.State,
+		Actor:   autoscaler.ActorFrom(ctx),
+		Cycle:   autoscaler.CycleFrom(ctx),
+		Created: time.Now().Unix(),
+	}
+	if server.State == autoscaler.StateError {
+		event.Error = server.Error
+	}
+	stmt, args, err := tx.BindNamed(eventInsertStmt, event)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(noContext, stmt, args...)
+	return err
+}
+
+const eventListStmt = `
+SELECT
+ event_id
+,event_server
+,event_from
+,event_to
+,event_actor
+,event_cycle
+,event_error
+,event_created
+FROM server_events
+WHERE event_server=:event_server
+ORDER BY event_created ASC, event_id ASC
+`
+
+const eventInsertStmt = `
+INSERT INTO server_events (
+ event_server
+,event_from
+,event_to
+,event_actor
+,event_cycle
+,event_error
+,event_created
+) VALUES (
+ :event_server
+,:event_from
+,:event_to
+,:event_actor
+,:event_cycle
+,:event_error
+,:event_created
+)
+`
+
+const eventPurgeStmt = `
+DELETE FROM server_events
+WHERE event_created < :event_created
+`
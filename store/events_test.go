@@ -0,0 +1,123 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/drone/autoscaler"
+)
+
+func TestEvent(t *testing.T) {
+	conn, err := connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer conn.Close()
+
+	mu := locker()
+	servers := NewServerStore(conn, mu, none{})
+	store := NewEventStore(conn, mu).(*eventStore)
+
+	ctx := autoscaler.WithActor(context.TODO(), "planner")
+	ctx = autoscaler.WithCycle(ctx, "KoHzbWvpLtmAaJ4C")
+
+	server := &autoscaler.Server{
+		Name:  "agent-1",
+		State: autoscaler.StatePending,
+	}
+	if err := servers.Create(ctx, server); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// updates that do not change the server state are
+	// not recorded.
+	server.Address = "1.2.3.4"
+	if err := servers.Update(context.TODO(), server); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := servers.Transition(autoscaler.WithActor(context.TODO(), "allocator"), "agent-1", autoscaler.StatePending, autoscaler.StateCreating); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// the error is recorded when the server transitions to
+	// the error state.
+	server.State = autoscaler.StateCreating
+	server.Error = "cannot pull image"
+	if err := servers.Update(context.TODO(), server); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := servers.Transition(autoscaler.WithActor(context.TODO(), "allocator"), "agent-1", autoscaler.StateCreating, autoscaler.StateError); err != nil {
+		t.Error(err)
+		return
+	}
+
+	t.Run("List", testEventList(store))
+	t.Run("Purge", testEventPurge(store))
+}
+
+func testEventList(store *eventStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		events, err := store.List(context.TODO(), "agent-1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(events), 3; got != want {
+			t.Errorf("Want event count %d, got %d", want, got)
+			return
+		}
+		if got, want := events[0].From, autoscaler.ServerState(""); got != want {
+			t.Errorf("Want event From %q, got %q", want, got)
+		}
+		if got, want := events[0].To, autoscaler.StatePending; got != want {
+			t.Errorf("Want event To %s, got %s", want, got)
+		}
+		if got, want := events[0].Actor, "planner"; got != want {
+			t.Errorf("Want event Actor %s, got %s", want, got)
+		}
+		if got, want := events[0].Cycle, "KoHzbWvpLtmAaJ4C"; got != want {
+			t.Errorf("Want event Cycle %s, got %s", want, got)
+		}
+		if got, want := events[1].From, autoscaler.StatePending; got != want {
+			t.Errorf("Want event From %s, got %s", want, got)
+		}
+		if got, want := events[1].To, autoscaler.StateCreating; got != want {
+			t.Errorf("Want event To %s, got %s", want, got)
+		}
+		if got, want := events[2].To, autoscaler.StateError; got != want {
+			t.Errorf("Want event To %s, got %s", want, got)
+		}
+		if got, want := events[2].Error, "cannot pull image"; got != want {
+			t.Errorf("Want event Error %s, got %s", want, got)
+		}
+	}
+}
+
+func testEventPurge(store *eventStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		err := store.Purge(context.TODO(), time.Now().Add(time.Hour).Unix())
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		events, err := store.List(context.TODO(), "agent-1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(events), 0; got != want {
+			t.Errorf("Want event count %d, got %d", want, got)
+		}
+	}
+}
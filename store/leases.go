@@ -0,0 +1,124 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package store
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drone/autoscaler"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// NewLeaseStore returns a new lease store.
+func NewLeaseStore(db *sqlx.DB, mu sync.Locker) autoscaler.LeaseStore {
+	return &leaseStore{mu, db}
+}
+
+type leaseStore struct {
+	mu sync.Locker
+	db *sqlx.DB
+}
+
+func (s *leaseStore) Acquire(ctx context.Context, holder string, ttl time.Duration) (bool, error) {
+	// the lease expiry is calculated using the database
+	// clock, since the replica clocks may be skewed.
+	stmt := strings.Replace(leaseAcquireStmt, "{now}", unixNow(s.db.DriverName()), -1)
+	err := s.exec(ctx, stmt, map[string]interface{}{
+		"lease_holder": holder,
+		"lease_ttl":    int64(ttl / time.Second),
+	})
+	if err != nil {
+		return false, err
+	}
+	// the lease is selected after the update, instead of
+	// relying on the number of rows affected, since mysql
+	// does not count rows that are matched but unchanged.
+	lease, err := s.Find(ctx)
+	if err != nil {
+		return false, err
+	}
+	return lease.Holder == holder, nil
+}
+
+func (s *leaseStore) Release(ctx context.Context, holder string) error {
+	return s.exec(ctx, leaseReleaseStmt, map[string]interface{}{
+		"lease_holder": holder,
+	})
+}
+
+func (s *leaseStore) Pause(ctx context.Context, paused bool) error {
+	return s.exec(ctx, leasePauseStmt, map[string]interface{}{
+		"lease_paused": paused,
+	})
+}
+
+func (s *leaseStore) Find(ctx context.Context) (*autoscaler.Lease, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dest := new(autoscaler.Lease)
+	err := s.db.GetContext(ctx, dest, leaseFindStmt)
+	return dest, err
+}
+
+func (s *leaseStore) exec(ctx context.Context, query string, params map[string]interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stmt, args, err := s.db.BindNamed(query, params)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, stmt, args...)
+	return err
+}
+
+// helper function returns the sql expression that returns
+// the current unix time of the database server.
+func unixNow(driver string) string {
+	switch driver {
+	case "postgres":
+		return "CAST(EXTRACT(EPOCH FROM NOW()) AS BIGINT)"
+	case "mysql":
+		return "UNIX_TIMESTAMP()"
+	default:
+		return "CAST(strftime('%s', 'now') AS INTEGER)"
+	}
+}
+
+const leaseFindStmt = `
+SELECT
+ lease_holder
+,lease_expires
+,lease_paused
+FROM leases
+WHERE lease_name='leader'
+`
+
+const leaseAcquireStmt = `
+UPDATE leases SET
+ lease_holder=:lease_holder
+,lease_expires={now} + :lease_ttl
+WHERE lease_name='leader'
+  AND (lease_holder=:lease_holder OR lease_expires < {now})
+`
+
+const leaseReleaseStmt = `
+UPDATE leases SET
+ lease_holder=''
+,lease_expires=0
+WHERE lease_name='leader'
+  AND lease_holder=:lease_holder
+`
+
+const leasePauseStmt = `
+UPDATE leases SET
+ lease_paused=:lease_paused
+WHERE lease_name='leader'
+`
@@ -0,0 +1,142 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLease(t *testing.T) {
+	conn, err := connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer conn.Close()
+
+	store := NewLeaseStore(conn, locker()).(*leaseStore)
+
+	ok, err := store.Acquire(context.TODO(), "replica-1", time.Minute)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !ok {
+		t.Errorf("Want lease acquired by replica-1")
+	}
+
+	// the lease cannot be acquired by another replica
+	// until the lease expires or is released.
+	ok, err = store.Acquire(context.TODO(), "replica-2", time.Minute)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if ok {
+		t.Errorf("Want lease not acquired by replica-2")
+	}
+
+	// the lease is renewed by the holder.
+	ok, err = store.Acquire(context.TODO(), "replica-1", time.Minute)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !ok {
+		t.Errorf("Want lease renewed by replica-1")
+	}
+
+	lease, err := store.Find(context.TODO())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := lease.Holder, "replica-1"; got != want {
+		t.Errorf("Want lease holder %q, got %q", want, got)
+	}
+
+	// the lease is not released by another replica.
+	if err := store.Release(context.TODO(), "replica-2"); err != nil {
+		t.Error(err)
+		return
+	}
+	if lease, _ := store.Find(context.TODO()); lease.Holder != "replica-1" {
+		t.Errorf("Want lease held by replica-1")
+	}
+
+	if err := store.Release(context.TODO(), "replica-1"); err != nil {
+		t.Error(err)
+		return
+	}
+	ok, err = store.Acquire(context.TODO(), "replica-2", time.Minute)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !ok {
+		t.Errorf("Want lease acquired by replica-2 after release")
+	}
+}
+
+func TestLeaseExpired(t *testing.T) {
+	conn, err := connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer conn.Close()
+
+	store := NewLeaseStore(conn, locker()).(*leaseStore)
+
+	// acquire a lease that is already expired.
+	ok, err := store.Acquire(context.TODO(), "replica-1", -time.Minute)
+	if err != nil || !ok {
+		t.Errorf("Want lease acquired by replica-1")
+		return
+	}
+	ok, err = store.Acquire(context.TODO(), "replica-2", time.Minute)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !ok {
+		t.Errorf("Want expired lease acquired by replica-2")
+	}
+}
+
+func TestLeasePause(t *testing.T) {
+	conn, err := connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer conn.Close()
+
+	store := NewLeaseStore(conn, locker()).(*leaseStore)
+
+	if err := store.Pause(context.TODO(), true); err != nil {
+		t.Error(err)
+		return
+	}
+	lease, err := store.Find(context.TODO())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !lease.Paused {
+		t.Errorf("Want lease paused")
+	}
+
+	// the paused flag is retained when the lease is acquired.
+	if _, err := store.Acquire(context.TODO(), "replica-1", time.Minute); err != nil {
+		t.Error(err)
+		return
+	}
+	if lease, _ := store.Find(context.TODO()); !lease.Paused {
+		t.Errorf("Want lease paused after acquire")
+	}
+}
@@ -0,0 +1,3 @@
+-- name: alter-table-servers-add-column-pool
+
+ALTER TABLE servers ADD COLUMN server_pool VARCHAR(50) NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- name: alter-table-servers-alter-column-secret
+
+ALTER TABLE servers MODIFY COLUMN server_secret VARCHAR(250);
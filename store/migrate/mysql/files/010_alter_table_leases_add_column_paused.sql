@@ -0,0 +1,3 @@
+-- name: alter-table-leases-add-column-paused
+
+ALTER TABLE leases ADD COLUMN lease_paused BOOLEAN NOT NULL DEFAULT FALSE;
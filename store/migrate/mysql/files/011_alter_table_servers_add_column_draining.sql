@@ -0,0 +1,3 @@
+-- name: alter-table-servers-add-column-draining
+
+ALTER TABLE servers ADD COLUMN server_draining INTEGER NOT NULL DEFAULT 0;
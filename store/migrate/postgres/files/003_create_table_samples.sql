@@ -0,0 +1,12 @@
+-- name: create-table-samples
+
+CREATE TABLE samples (
+ sample_pool     VARCHAR(50)
+,sample_pending  INTEGER
+,sample_running  INTEGER
+,sample_created  INTEGER
+);
+
+-- name: create-index-samples-created
+
+CREATE INDEX ix_samples_created ON samples (sample_pool, sample_created);
@@ -0,0 +1,7 @@
+-- name: alter-table-servers-add-column-protected
+
+ALTER TABLE servers ADD COLUMN server_protected BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- name: alter-table-servers-add-column-cordoned
+
+ALTER TABLE servers ADD COLUMN server_cordoned BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,3 @@
+-- name: alter-table-servers-add-column-hash
+
+ALTER TABLE servers ADD COLUMN server_hash VARCHAR(250) NOT NULL DEFAULT '';
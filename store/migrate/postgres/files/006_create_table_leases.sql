@@ -0,0 +1,11 @@
+-- name: create-table-leases
+
+CREATE TABLE leases (
+ lease_name     VARCHAR(50) PRIMARY KEY
+,lease_holder   VARCHAR(250)
+,lease_expires  INTEGER
+);
+
+-- name: insert-lease-leader
+
+INSERT INTO leases (lease_name, lease_holder, lease_expires) VALUES ('leader', '', 0);
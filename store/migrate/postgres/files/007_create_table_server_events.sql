@@ -0,0 +1,20 @@
+-- name: create-table-server-events
+
+CREATE TABLE server_events (
+ event_id       SERIAL PRIMARY KEY
+,event_server   VARCHAR(50)
+,event_from     VARCHAR(50)
+,event_to       VARCHAR(50)
+,event_actor    VARCHAR(250)
+,event_cycle    VARCHAR(50)
+,event_error    TEXT
+,event_created  INTEGER
+);
+
+-- name: create-index-server-events-server
+
+CREATE INDEX ix_server_events_server ON server_events (event_server);
+
+-- name: create-index-server-events-created
+
+CREATE INDEX ix_server_events_created ON server_events (event_created);
@@ -0,0 +1,3 @@
+-- name: alter-table-servers-add-column-labels
+
+ALTER TABLE servers ADD COLUMN server_labels TEXT NOT NULL DEFAULT '{}';
@@ -20,6 +20,62 @@ var migrations = []struct {
 		name: "create-index-server-state",
 		stmt: createIndexServerState,
 	},
+	{
+		name: "alter-table-servers-add-column-pool",
+		stmt: alterTableServersAddColumnPool,
+	},
+	{
+		name: "create-table-samples",
+		stmt: createTableSamples,
+	},
+	{
+		name: "create-index-samples-created",
+		stmt: createIndexSamplesCreated,
+	},
+	{
+		name: "alter-table-servers-add-column-protected",
+		stmt: alterTableServersAddColumnProtected,
+	},
+	{
+		name: "alter-table-servers-add-column-cordoned",
+		stmt: alterTableServersAddColumnCordoned,
+	},
+	{
+		name: "alter-table-servers-add-column-hash",
+		stmt: alterTableServersAddColumnHash,
+	},
+	{
+		name: "create-table-leases",
+		stmt: createTableLeases,
+	},
+	{
+		name: "insert-lease-leader",
+		stmt: insertLeaseLeader,
+	},
+	{
+		name: "create-table-server-events",
+		stmt: createTableServerEvents,
+	},
+	{
+		name: "create-index-server-events-server",
+		stmt: createIndexServerEventsServer,
+	},
+	{
+		name: "create-index-server-events-created",
+		stmt: createIndexServerEventsCreated,
+	},
+	{
+		name: "alter-table-servers-add-column-labels",
+		stmt: alterTableServersAddColumnLabels,
+	},
+	{
+		name: "alter-table-leases-add-column-paused",
+		stmt: alterTableLeasesAddColumnPaused,
+	},
+	{
+		name: "alter-table-servers-add-column-draining",
+		stmt: alterTableServersAddColumnDraining,
+	},
 }
 
 // Migrate performs the database migration. If the migration fails
@@ -131,3 +187,113 @@ CREATE INDEX IF NOT EXISTS ix_servers_id ON servers (server_id);
 var createIndexServerState = `
 CREATE INDEX IF NOT EXISTS ix_servers_state ON servers (server_state);
 `
+
+//
+// 002_alter_table_servers_add_column_pool.sql
+//
+
+var alterTableServersAddColumnPool = `
+ALTER TABLE servers ADD COLUMN server_pool TEXT NOT NULL DEFAULT '';
+`
+
+//
+// 003_create_table_samples.sql
+//
+
+var createTableSamples = `
+CREATE TABLE IF NOT EXISTS samples (
+ sample_pool     TEXT
+,sample_pending  INTEGER
+,sample_running  INTEGER
+,sample_created  INTEGER
+);
+`
+
+var createIndexSamplesCreated = `
+CREATE INDEX IF NOT EXISTS ix_samples_created ON samples (sample_pool, sample_created);
+`
+
+//
+// 004_alter_table_servers_add_column_protected.sql
+//
+
+var alterTableServersAddColumnProtected = `
+ALTER TABLE servers ADD COLUMN server_protected BOOLEAN NOT NULL DEFAULT 0;
+`
+
+var alterTableServersAddColumnCordoned = `
+ALTER TABLE servers ADD COLUMN server_cordoned BOOLEAN NOT NULL DEFAULT 0;
+`
+
+//
+// 005_alter_table_servers_add_column_hash.sql
+//
+
+var alterTableServersAddColumnHash = `
+ALTER TABLE servers ADD COLUMN server_hash TEXT NOT NULL DEFAULT '';
+`
+
+//
+// 006_create_table_leases.sql
+//
+
+var createTableLeases = `
+CREATE TABLE IF NOT EXISTS leases (
+ lease_name     TEXT PRIMARY KEY
+,lease_holder   TEXT
+,lease_expires  INTEGER
+);
+`
+
+var insertLeaseLeader = `
+INSERT INTO leases (lease_name, lease_holder, lease_expires) VALUES ('leader', '', 0);
+`
+
+//
+// 007_create_table_server_events.sql
+//
+
+var createTableServerEvents = `
+CREATE TABLE IF NOT EXISTS server_events (
+ event_id       INTEGER PRIMARY KEY AUTOINCREMENT
+,event_server   TEXT
+,event_from     TEXT
+,event_to       TEXT
+,event_actor    TEXT
+,event_cycle    TEXT
+,event_error    TEXT
+,event_created  INTEGER
+);
+`
+
+var createIndexServerEventsServer = `
+CREATE INDEX IF NOT EXISTS ix_server_events_server ON server_events (event_server);
+`
+
+var createIndexServerEventsCreated = `
+CREATE INDEX IF NOT EXISTS ix_server_events_created ON server_events (event_created);
+`
+
+//
+// 009_alter_table_servers_add_column_labels.sql
+//
+
+var alterTableServersAddColumnLabels = `
+ALTER TABLE servers ADD COLUMN server_labels TEXT NOT NULL DEFAULT '{}';
+`
+
+//
+// 010_alter_table_leases_add_column_paused.sql
+//
+
+var alterTableLeasesAddColumnPaused = `
+ALTER TABLE leases ADD COLUMN lease_paused BOOLEAN NOT NULL DEFAULT 0;
+`
+
+//
+// 011_alter_table_servers_add_column_draining.sql
+//
+
+var alterTableServersAddColumnDraining = `
+ALTER TABLE servers ADD COLUMN server_draining INTEGER NOT NULL DEFAULT 0;
+`
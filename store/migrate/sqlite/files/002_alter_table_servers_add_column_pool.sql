@@ -0,0 +1,3 @@
+-- name: alter-table-servers-add-column-pool
+
+ALTER TABLE servers ADD COLUMN server_pool TEXT NOT NULL DEFAULT '';
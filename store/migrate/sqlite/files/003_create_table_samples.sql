@@ -0,0 +1,12 @@
+-- name: create-table-samples
+
+CREATE TABLE IF NOT EXISTS samples (
+ sample_pool     TEXT
+,sample_pending  INTEGER
+,sample_running  INTEGER
+,sample_created  INTEGER
+);
+
+-- name: create-index-samples-created
+
+CREATE INDEX IF NOT EXISTS ix_samples_created ON samples (sample_pool, sample_created);
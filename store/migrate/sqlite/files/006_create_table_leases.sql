@@ -0,0 +1,11 @@
+-- name: create-table-leases
+
+CREATE TABLE IF NOT EXISTS leases (
+ lease_name     TEXT PRIMARY KEY
+,lease_holder   TEXT
+,lease_expires  INTEGER
+);
+
+-- name: insert-lease-leader
+
+INSERT INTO leases (lease_name, lease_holder, lease_expires) VALUES ('leader', '', 0);
@@ -0,0 +1,20 @@
+-- name: create-table-server-events
+
+CREATE TABLE IF NOT EXISTS server_events (
+ event_id       INTEGER PRIMARY KEY AUTOINCREMENT
+,event_server   TEXT
+,event_from     TEXT
+,event_to       TEXT
+,event_actor    TEXT
+,event_cycle    TEXT
+,event_error    TEXT
+,event_created  INTEGER
+);
+
+-- name: create-index-server-events-server
+
+CREATE INDEX IF NOT EXISTS ix_server_events_server ON server_events (event_server);
+
+-- name: create-index-server-events-created
+
+CREATE INDEX IF NOT EXISTS ix_server_events_created ON server_events (event_created);
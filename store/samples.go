@@ -0,0 +1,98 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package store
+
+import (
+	"context"
+	"sync"
+
+	"github.com/drone/autoscaler"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// NewSampleStore returns a new sample store.
+func NewSampleStore(db *sqlx.DB, mu sync.Locker) autoscaler.SampleStore {
+	return &sampleStore{mu, db}
+}
+
+type sampleStore struct {
+	mu sync.Locker
+	db *sqlx.DB
+}
+
+func (s *sampleStore) List(_ context.Context, pool string, from, to int64) ([]*autoscaler.Sample, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dest := []*autoscaler.Sample{}
+	params := map[string]interface{}{
+		"sample_pool": pool,
+		"from":        from,
+		"to":          to,
+	}
+	stmt, args, err := s.db.BindNamed(sampleListStmt, params)
+	if err != nil {
+		return nil, err
+	}
+	err = s.db.SelectContext(noContext, &dest, stmt, args...)
+	return dest, err
+}
+
+func (s *sampleStore) Create(_ context.Context, sample *autoscaler.Sample) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stmt, args, err := s.db.BindNamed(sampleInsertStmt, sample)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(noContext, stmt, args...)
+	return err
+}
+
+func (s *sampleStore) Purge(_ context.Context, before int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stmt, args, err := s.db.BindNamed(samplePurgeStmt, &autoscaler.Sample{Created: before})
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(noContext, stmt, args...)
+	return err
+}
+
+const sampleListStmt = `
+SELECT
+ sample_pool
+,sample_pending
+,sample_running
+,sample_created
+FROM samples
+WHERE sample_pool=:sample_pool
+  AND sample_created >= :from
+  AND sample_created < :to
+ORDER BY sample_created ASC
+`
+
+const sampleInsertStmt = `
+INSERT INTO samples (
+ sample_pool
+,sample_pending
+,sample_running
+,sample_created
+) VALUES (
+ :sample_pool
+,:sample_pending
+,:sample_running
+,:sample_created
+)
+`
+
+const samplePurgeStmt = `
+DELETE FROM samples
+WHERE sample_created < :sample_created
+`
@@ -0,0 +1,79 @@
+// Copyright 2018 Drone.IO Inc
+// Use of this source code is governed by the Polyform License
+// that can be found in the LICENSE file.
+
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drone/autoscaler"
+)
+
+func TestSample(t *testing.T) {
+	conn, err := connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer conn.Close()
+
+	store := NewSampleStore(conn, locker()).(*sampleStore)
+	t.Run("Create", testSampleCreate(store))
+	t.Run("List", testSampleList(store))
+	t.Run("Purge", testSamplePurge(store))
+}
+
+func testSampleCreate(store *sampleStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		samples := []*autoscaler.Sample{
+			{Pool: "arm64", Pending: 1, Running: 2, Created: 100},
+			{Pool: "arm64", Pending: 3, Running: 4, Created: 200},
+			{Pool: "amd64", Pending: 5, Running: 6, Created: 200},
+			{Pool: "arm64", Pending: 7, Running: 8, Created: 300},
+		}
+		for _, sample := range samples {
+			if err := store.Create(context.TODO(), sample); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+}
+
+func testSampleList(store *sampleStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		samples, err := store.List(context.TODO(), "arm64", 100, 300)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(samples), 2; got != want {
+			t.Errorf("Want sample count %d, got %d", want, got)
+			return
+		}
+		if got, want := samples[1].Pending, 3; got != want {
+			t.Errorf("Want sample Pending %d, got %d", want, got)
+		}
+		if got, want := samples[1].Running, 4; got != want {
+			t.Errorf("Want sample Running %d, got %d", want, got)
+		}
+	}
+}
+
+func testSamplePurge(store *sampleStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		if err := store.Purge(context.TODO(), 200); err != nil {
+			t.Error(err)
+			return
+		}
+		samples, err := store.List(context.TODO(), "arm64", 0, 1000)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(samples), 2; got != want {
+			t.Errorf("Want sample count %d after purge, got %d", want, got)
+		}
+	}
+}
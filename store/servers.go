@@ -7,6 +7,7 @@ package store
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"sync"
 	"time"
 
@@ -16,14 +17,16 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
-// NewServerStore returns a new server store.
-func NewServerStore(db *sqlx.DB, mu sync.Locker) autoscaler.ServerStore {
-	return &serverStore{mu, db}
+// NewServerStore returns a new server store. The server
+// secret and private keys are encrypted with the Encrypter.
+func NewServerStore(db *sqlx.DB, mu sync.Locker, enc Encrypter) autoscaler.ServerStore {
+	return &serverStore{mu, db, enc}
 }
 
 type serverStore struct {
-	mu sync.Locker
-	db *sqlx.DB
+	mu  sync.Locker
+	db  *sqlx.DB
+	enc Encrypter
 }
 
 func (s *serverStore) Find(_ context.Context, name string) (*autoscaler.Server, error) {
@@ -36,7 +39,10 @@ func (s *serverStore) Find(_ context.Context, name string) (*autoscaler.Server,
 		return nil, err
 	}
 	err = s.db.GetContext(noContext, dest, stmt, args...)
-	return dest, err
+	if err != nil {
+		return dest, err
+	}
+	return dest, decryptServer(s.enc, dest)
 }
 
 func (s *serverStore) List(_ context.Context) ([]*autoscaler.Server, error) {
@@ -45,7 +51,10 @@ func (s *serverStore) List(_ context.Context) ([]*autoscaler.Server, error) {
 
 	dest := []*autoscaler.Server{}
 	err := s.db.SelectContext(noContext, &dest, serverListStmt)
-	return dest, err
+	if err != nil {
+		return dest, err
+	}
+	return dest, s.decryptAll(dest)
 }
 
 func (s *serverStore) ListState(_ context.Context, state autoscaler.ServerState) ([]*autoscaler.Server, error) {
@@ -61,13 +70,16 @@ func (s *serverStore) ListState(_ context.Context, state autoscaler.ServerState)
 	if err == sql.ErrNoRows {
 		return dest, nil
 	}
-	return dest, err
+	if err != nil {
+		return dest, err
+	}
+	return dest, s.decryptAll(dest)
 }
 
-func (s *serverStore) Create(_ context.Context, server *autoscaler.Server) error {
+func (s *serverStore) Create(ctx context.Context, server *autoscaler.Server) error {
 	return retry.Do(
 		func() error {
-			if err := s.create(server); isConnReset(err) {
+			if err := s.create(ctx, server); isConnReset(err) {
 				return err
 			} else {
 				return retry.Unrecoverable(err)
@@ -79,24 +91,41 @@ func (s *serverStore) Create(_ context.Context, server *autoscaler.Server) error
 	)
 }
 
-func (s *serverStore) create(server *autoscaler.Server) error {
+func (s *serverStore) create(ctx context.Context, server *autoscaler.Server) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	server.Created = time.Now().Unix()
 	server.Updated = time.Now().Unix()
-	stmt, args, err := s.db.BindNamed(serverInsertStmt, server)
+	encrypted, err := encryptServer(s.enc, server)
 	if err != nil {
 		return err
 	}
-	_, err = s.db.ExecContext(noContext, stmt, args...)
-	return err
+	stmt, args, err := tx.BindNamed(serverInsertStmt, encrypted)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(noContext, stmt, args...)
+	if err != nil {
+		return err
+	}
+	err = createEvent(ctx, tx, server, "")
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (s *serverStore) Update(_ context.Context, server *autoscaler.Server) error {
+func (s *serverStore) Update(ctx context.Context, server *autoscaler.Server) error {
 	return retry.Do(
 		func() error {
-			if err := s.update(server); isConnReset(err) {
+			if err := s.update(ctx, server); isConnReset(err) {
 				return err
 			} else {
 				return retry.Unrecoverable(err)
@@ -108,12 +137,57 @@ func (s *serverStore) Update(_ context.Context, server *autoscaler.Server) error
 	)
 }
 
-func (s *serverStore) update(server *autoscaler.Server) error {
+func (s *serverStore) update(ctx context.Context, server *autoscaler.Server) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// the current server state is selected before the
+	// update to record the state transition.
+	var from autoscaler.ServerState
+	stmt, args, err := tx.BindNamed(serverStateStmt, server)
+	if err != nil {
+		return err
+	}
+	err = tx.GetContext(noContext, &from, stmt, args...)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	found := err == nil
+
+	server.Updated = time.Now().Unix()
+	encrypted, err := encryptServer(s.enc, server)
+	if err != nil {
+		return err
+	}
+	stmt, args, err = tx.BindNamed(serverUpdateStmt, encrypted)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(noContext, stmt, args...)
+	if err != nil {
+		return err
+	}
+	if found && from != server.State {
+		err = createEvent(ctx, tx, server, from)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *serverStore) UpdateFlags(_ context.Context, server *autoscaler.Server) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	server.Updated = time.Now().Unix()
-	stmt, args, err := s.db.BindNamed(serverUpdateStmt, server)
+	stmt, args, err := s.db.BindNamed(serverUpdateFlagsStmt, server)
 	if err != nil {
 		return err
 	}
@@ -121,6 +195,172 @@ func (s *serverStore) update(server *autoscaler.Server) error {
 	return err
 }
 
+func (s *serverStore) Transition(ctx context.Context, name string, from, to autoscaler.ServerState) error {
+	return retry.Do(
+		func() error {
+			if err := s.transition(ctx, name, from, to); isConnReset(err) {
+				return err
+			} else {
+				return retry.Unrecoverable(err)
+			}
+		},
+		retry.Attempts(5),
+		retry.MaxDelay(time.Second*5),
+		retry.LastErrorOnly(true),
+	)
+}
+
+func (s *serverStore) transition(ctx context.Context, name string, from, to autoscaler.ServerState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	params := map[string]interface{}{
+		"server_name":    name,
+		"server_state":   to,
+		"server_from":    from,
+		"server_updated": time.Now().Unix(),
+	}
+	query := serverTransitionStmt
+	if to == autoscaler.StateDraining {
+		query = serverTransitionDrainStmt
+	}
+	stmt, args, err := tx.BindNamed(query, params)
+	if err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(noContext, stmt, args...)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 0 {
+		if from != to {
+			server := &autoscaler.Server{Name: name, State: to}
+			// the error is read in the transaction, so that
+			// the event records the error of the server.
+			if to == autoscaler.StateError {
+				stmt, args, err := tx.BindNamed(serverErrorStmt, server)
+				if err != nil {
+					return err
+				}
+				err = tx.GetContext(noContext, &server.Error, stmt, args...)
+				if err != nil {
+					return err
+				}
+			}
+			err = createEvent(ctx, tx, server, from)
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Commit()
+	}
+
+	return checkConflict(tx, name, from, to)
+}
+
+func (s *serverStore) CompareAndUpdate(ctx context.Context, server *autoscaler.Server, from autoscaler.ServerState) error {
+	return retry.Do(
+		func() error {
+			if err := s.compareAndUpdate(ctx, server, from); isConnReset(err) {
+				return err
+			} else {
+				return retry.Unrecoverable(err)
+			}
+		},
+		retry.Attempts(5),
+		retry.MaxDelay(time.Second*5),
+		retry.LastErrorOnly(true),
+	)
+}
+
+func (s *serverStore) compareAndUpdate(ctx context.Context, server *autoscaler.Server, from autoscaler.ServerState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	server.Updated = time.Now().Unix()
+	encrypted, err := encryptServer(s.enc, server)
+	if err != nil {
+		return err
+	}
+	params := struct {
+		autoscaler.Server
+		From autoscaler.ServerState `db:"server_from"`
+	}{*encrypted, from}
+	stmt, args, err := tx.BindNamed(serverCompareAndUpdateStmt, &params)
+	if err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(noContext, stmt, args...)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return checkConflict(tx, server.Name, from, server.State)
+	}
+	if from != server.State {
+		err = createEvent(ctx, tx, server, from)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// helper function returns ErrStateConflict if a conditional
+// update did not affect the named server. Mysql reports zero
+// rows affected when the values are unchanged, so the server
+// state is verified before a conflict is returned.
+func checkConflict(tx *sqlx.Tx, name string, from, to autoscaler.ServerState) error {
+	var state autoscaler.ServerState
+	stmt, args, err := tx.BindNamed(serverStateStmt, &autoscaler.Server{Name: name})
+	if err != nil {
+		return err
+	}
+	err = tx.GetContext(noContext, &state, stmt, args...)
+	if err == sql.ErrNoRows {
+		return autoscaler.ErrServerNotFound
+	} else if err != nil {
+		return err
+	}
+	if from == to && state == to {
+		return nil
+	}
+	return autoscaler.ErrStateConflict
+}
+
+// helper function decrypts the server secret and private
+// keys of each server in the list. An error is returned if
+// any server cannot be decrypted, since omitting the server
+// would hide it from the planner and the reconciler.
+func (s *serverStore) decryptAll(servers []*autoscaler.Server) error {
+	for _, server := range servers {
+		if err := decryptServer(s.enc, server); err != nil {
+			return fmt.Errorf("cannot decrypt server %s: %w", server.Name, err)
+		}
+	}
+	return nil
+}
+
 func (s *serverStore) Delete(_ context.Context, server *autoscaler.Server) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -150,6 +390,7 @@ SELECT
  server_name
 ,server_id
 ,server_provider
+,server_pool
 ,server_state
 ,server_image
 ,server_region
@@ -157,6 +398,7 @@ SELECT
 ,server_platform
 ,server_address
 ,server_capacity
+,server_hash
 ,server_secret
 ,server_error
 ,server_ca_key
@@ -167,6 +409,10 @@ SELECT
 ,server_updated
 ,server_started
 ,server_stopped
+,server_draining
+,server_protected
+,server_cordoned
+,server_labels
 FROM servers
 WHERE server_name=:server_name
 `
@@ -176,6 +422,7 @@ SELECT
  server_name
 ,server_id
 ,server_provider
+,server_pool
 ,server_state
 ,server_image
 ,server_region
@@ -183,6 +430,7 @@ SELECT
 ,server_platform
 ,server_address
 ,server_capacity
+,server_hash
 ,server_secret
 ,server_error
 ,server_ca_key
@@ -193,6 +441,10 @@ SELECT
 ,server_updated
 ,server_started
 ,server_stopped
+,server_draining
+,server_protected
+,server_cordoned
+,server_labels
 FROM servers
 ORDER BY server_created ASC
 `
@@ -202,6 +454,7 @@ SELECT
  server_name
 ,server_id
 ,server_provider
+,server_pool
 ,server_state
 ,server_image
 ,server_region
@@ -209,6 +462,7 @@ SELECT
 ,server_platform
 ,server_address
 ,server_capacity
+,server_hash
 ,server_secret
 ,server_error
 ,server_ca_key
@@ -219,6 +473,10 @@ SELECT
 ,server_updated
 ,server_started
 ,server_stopped
+,server_draining
+,server_protected
+,server_cordoned
+,server_labels
 FROM servers
 WHERE server_state=:server_state
 ORDER BY server_created ASC
@@ -229,6 +487,7 @@ INSERT INTO servers (
  server_name
 ,server_id
 ,server_provider
+,server_pool
 ,server_state
 ,server_image
 ,server_region
@@ -236,6 +495,7 @@ INSERT INTO servers (
 ,server_platform
 ,server_address
 ,server_capacity
+,server_hash
 ,server_secret
 ,server_error
 ,server_ca_key
@@ -246,10 +506,15 @@ INSERT INTO servers (
 ,server_updated
 ,server_started
 ,server_stopped
+,server_draining
+,server_protected
+,server_cordoned
+,server_labels
 ) VALUES (
  :server_name
 ,:server_id
 ,:server_provider
+,:server_pool
 ,:server_state
 ,:server_image
 ,:server_region
@@ -257,6 +522,7 @@ INSERT INTO servers (
 ,:server_platform
 ,:server_address
 ,:server_capacity
+,:server_hash
 ,:server_secret
 ,:server_error
 ,:server_ca_key
@@ -267,6 +533,10 @@ INSERT INTO servers (
 ,:server_updated
 ,:server_started
 ,:server_stopped
+,:server_draining
+,:server_protected
+,:server_cordoned
+,:server_labels
 )
 `
 
@@ -274,6 +544,38 @@ const serverUpdateStmt = `
 UPDATE servers SET
  server_id=:server_id
 ,server_provider=:server_provider
+,server_pool=:server_pool
+,server_state=:server_state
+,server_image=:server_image
+,server_region=:server_region
+,server_size=:server_size
+,server_platform=:server_platform
+,server_address=:server_address
+,server_capacity=:server_capacity
+,server_hash=:server_hash
+,server_secret=:server_secret
+,server_error=:server_error
+,server_ca_key=:server_ca_key
+,server_ca_cert=:server_ca_cert
+,server_tls_key=:server_tls_key
+,server_tls_cert=:server_tls_cert
+,server_updated=:server_updated
+,server_started=:server_started
+,server_stopped=:server_stopped
+,server_protected=:server_protected
+,server_cordoned=:server_cordoned
+,server_labels=:server_labels
+WHERE server_name=:server_name
+`
+
+// the compare and update statement does not update the
+// protected and cordoned flags, which are updated using
+// the api.
+const serverCompareAndUpdateStmt = `
+UPDATE servers SET
+ server_id=:server_id
+,server_provider=:server_provider
+,server_pool=:server_pool
 ,server_state=:server_state
 ,server_image=:server_image
 ,server_region=:server_region
@@ -281,6 +583,7 @@ UPDATE servers SET
 ,server_platform=:server_platform
 ,server_address=:server_address
 ,server_capacity=:server_capacity
+,server_hash=:server_hash
 ,server_secret=:server_secret
 ,server_error=:server_error
 ,server_ca_key=:server_ca_key
@@ -290,6 +593,55 @@ UPDATE servers SET
 ,server_updated=:server_updated
 ,server_started=:server_started
 ,server_stopped=:server_stopped
+,server_labels=:server_labels
+WHERE server_name=:server_name
+  AND server_state=:server_from
+`
+
+const serverUpdateFlagsStmt = `
+UPDATE servers SET
+ server_protected=:server_protected
+,server_cordoned=:server_cordoned
+,server_updated=:server_updated
+WHERE server_name=:server_name
+`
+
+const serverStateStmt = `
+SELECT server_state
+FROM servers
+WHERE server_name=:server_name
+`
+
+const serverErrorStmt = `
+SELECT server_error
+FROM servers
+WHERE server_name=:server_name
+`
+
+const serverTransitionStmt = `
+UPDATE servers SET
+ server_state=:server_state
+,server_updated=:server_updated
+WHERE server_name=:server_name
+  AND server_state=:server_from
+`
+
+// the drain statement records the time the server started
+// draining, which is not reset when the server is updated.
+const serverTransitionDrainStmt = `
+UPDATE servers SET
+ server_state=:server_state
+,server_updated=:server_updated
+,server_draining=:server_updated
+WHERE server_name=:server_name
+  AND server_state=:server_from
+`
+
+const serverEncryptStmt = `
+UPDATE servers SET
+ server_secret=:server_secret
+,server_ca_key=:server_ca_key
+,server_tls_key=:server_tls_key
 WHERE server_name=:server_name
 `
 
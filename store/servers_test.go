@@ -22,12 +22,16 @@ func TestServer(t *testing.T) {
 	defer conn.Close()
 
 	mu := locker()
-	store := NewServerStore(conn, mu).(*serverStore)
+	store := NewServerStore(conn, mu, none{}).(*serverStore)
 	t.Run("Create", testServerCreate(store))
 	t.Run("Find", testServerFind(store))
 	t.Run("List", testServerList(store))
 	t.Run("ListState", testServerListState(store))
 	t.Run("Update", testServerUpdate(store))
+	t.Run("Transition", testServerTransition(store))
+	t.Run("CompareAndUpdate", testServerCompareAndUpdate(store))
+	t.Run("UpdateFlags", testServerUpdateFlags(store))
+	t.Run("Draining", testServerDraining(store))
 	t.Run("Delete", testServerDelete(store))
 	t.Run("Purge", testServerPurge(store))
 }
@@ -36,12 +40,14 @@ func testServerCreate(store *serverStore) func(t *testing.T) {
 	return func(t *testing.T) {
 		server := &autoscaler.Server{
 			Provider: autoscaler.ProviderGoogle,
+			Pool:     "arm64",
 			State:    autoscaler.StateRunning,
 			Name:     "i-5203422c",
 			Address:  "54.194.252.215",
 			Capacity: 2,
 			Created:  time.Now().Unix(),
 			Updated:  time.Now().Unix(),
+			Labels:   autoscaler.Labels{"zone": "us-central1-a"},
 		}
 		err := store.Create(context.TODO(), server)
 		if err != nil {
@@ -111,6 +117,7 @@ func testServerUpdate(store *serverStore) func(t *testing.T) {
 			Capacity: 2,
 			Created:  time.Now().Unix(),
 			Updated:  time.Now().Unix(),
+			Cordoned: true,
 		}
 		err := store.Update(context.TODO(), server)
 		if err != nil {
@@ -125,6 +132,153 @@ func testServerUpdate(store *serverStore) func(t *testing.T) {
 		if got, want := updated.Capacity, server.Capacity; got != want {
 			t.Errorf("Want updated capacity %d, got %d", want, got)
 		}
+		if got, want := updated.Cordoned, true; got != want {
+			t.Errorf("Want updated cordoned %v, got %v", want, got)
+		}
+	}
+}
+
+func testServerTransition(store *serverStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		err := store.Transition(context.TODO(), "i-5203422c", autoscaler.StateShutdown, autoscaler.StateStopping)
+		if err != autoscaler.ErrStateConflict {
+			t.Errorf("Want state conflict, got %v", err)
+		}
+		err = store.Transition(context.TODO(), "i-5203422c", "", autoscaler.StateShutdown)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		server, err := store.Find(context.TODO(), "i-5203422c")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := server.State, autoscaler.StateShutdown; got != want {
+			t.Errorf("Want server state %s, got %s", want, got)
+		}
+		err = store.Transition(context.TODO(), "i-5203422c", autoscaler.StateShutdown, autoscaler.StateShutdown)
+		if err != nil {
+			t.Error(err)
+		}
+		err = store.Transition(context.TODO(), "i-00000000", autoscaler.StateShutdown, autoscaler.StateStopping)
+		if err != autoscaler.ErrServerNotFound {
+			t.Errorf("Want server not found, got %v", err)
+		}
+	}
+}
+
+func testServerCompareAndUpdate(store *serverStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		server, err := store.Find(context.TODO(), "i-5203422c")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		server.State = autoscaler.StateStopping
+		server.Address = "5.6.7.8"
+		server.Protected = true
+		err = store.CompareAndUpdate(context.TODO(), server, autoscaler.StateRunning)
+		if err != autoscaler.ErrStateConflict {
+			t.Errorf("Want state conflict, got %v", err)
+		}
+		err = store.CompareAndUpdate(context.TODO(), server, autoscaler.StateShutdown)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		updated, err := store.Find(context.TODO(), "i-5203422c")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := updated.State, autoscaler.StateStopping; got != want {
+			t.Errorf("Want server state %s, got %s", want, got)
+		}
+		if got, want := updated.Address, "5.6.7.8"; got != want {
+			t.Errorf("Want server address %s, got %s", want, got)
+		}
+		// the protected and cordoned flags are only updated
+		// using the api.
+		if updated.Protected {
+			t.Errorf("Want protected flag unchanged")
+		}
+	}
+}
+
+func testServerUpdateFlags(store *serverStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		server, err := store.Find(context.TODO(), "i-5203422c")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		server.State = autoscaler.StateError
+		server.Protected = true
+		server.Cordoned = true
+		err = store.UpdateFlags(context.TODO(), server)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		updated, err := store.Find(context.TODO(), "i-5203422c")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !updated.Protected {
+			t.Errorf("Want protected flag updated")
+		}
+		if !updated.Cordoned {
+			t.Errorf("Want cordoned flag updated")
+		}
+		// the server state is only updated by the engine.
+		if got, want := updated.State, autoscaler.StateStopping; got != want {
+			t.Errorf("Want server state %s, got %s", want, got)
+		}
+	}
+}
+
+func testServerDraining(store *serverStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		err := store.Transition(context.TODO(), "i-5203422c", autoscaler.StateStopping, autoscaler.StateDraining)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		server, err := store.Find(context.TODO(), "i-5203422c")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if server.Draining == 0 {
+			t.Errorf("Want drain start time recorded")
+			return
+		}
+
+		// the drain start time is not reset when the server
+		// is updated.
+		draining := server.Draining
+		server.Draining = 0
+		server.Cordoned = false
+		err = store.UpdateFlags(context.TODO(), server)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = store.CompareAndUpdate(context.TODO(), server, autoscaler.StateDraining)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		updated, err := store.Find(context.TODO(), "i-5203422c")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := updated.Draining, draining; got != want {
+			t.Errorf("Want drain start time %d, got %d", want, got)
+		}
 	}
 }
 
@@ -195,5 +349,11 @@ func testServer(server *autoscaler.Server) func(t *testing.T) {
 		if got, want := server.Provider, autoscaler.ProviderGoogle; got != want {
 			t.Errorf("Want server Provider %v, got %v", want, got)
 		}
+		if got, want := server.Pool, "arm64"; got != want {
+			t.Errorf("Want server Pool %q, got %q", want, got)
+		}
+		if got, want := server.Labels["zone"], "us-central1-a"; got != want {
+			t.Errorf("Want server label zone %q, got %q", want, got)
+		}
 	}
 }